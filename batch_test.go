@@ -18,9 +18,18 @@
 package clickhouse
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/ClickHouse/clickhouse-go/v2/internal/structgentest"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExtractNormalizedInsertQueryAndColumns(t *testing.T) {
@@ -218,3 +227,114 @@ func TestExtractNormalizedInsertQueryAndColumns(t *testing.T) {
 		})
 	}
 }
+
+func TestBatchDeduplicationToken(t *testing.T) {
+	options := QueryOptions{settings: Settings{}}
+	token := batchDeduplicationToken(&options, driver.PrepareBatchOptions{})
+	assert.Empty(t, token)
+	assert.NotContains(t, options.settings, "insert_deduplication_token")
+
+	options = QueryOptions{settings: Settings{}}
+	token = batchDeduplicationToken(&options, driver.PrepareBatchOptions{ReplayBuffer: true})
+	assert.NotEmpty(t, token)
+	assert.Equal(t, token, options.settings["insert_deduplication_token"])
+
+	options = QueryOptions{settings: Settings{"insert_deduplication_token": "from_settings"}}
+	token = batchDeduplicationToken(&options, driver.PrepareBatchOptions{ReplayBuffer: true})
+	assert.Equal(t, "from_settings", token)
+
+	options = QueryOptions{settings: Settings{"insert_deduplication_token": "from_settings"}}
+	token = batchDeduplicationToken(&options, driver.PrepareBatchOptions{DeduplicationToken: "from_option"})
+	assert.Equal(t, "from_option", token)
+	assert.Equal(t, "from_option", options.settings["insert_deduplication_token"])
+}
+
+func TestNewEmptyBlock(t *testing.T) {
+	var block proto.Block
+	require.NoError(t, block.AddColumn("col1", "Int64"))
+	require.NoError(t, block.AddColumn("col2", "Nullable(String)"))
+	require.NoError(t, block.Append(int64(1), "a"))
+
+	empty, err := newEmptyBlock(&block)
+	require.NoError(t, err)
+	assert.Equal(t, 0, empty.Rows())
+	assert.Equal(t, block.ColumnsNames(), empty.ColumnsNames())
+	assert.Equal(t, block.Columns[1].Type(), empty.Columns[1].Type())
+	assert.Equal(t, 1, block.Rows())
+}
+
+// structgenBlock returns an empty block with the columns of the table of structgentest.Event
+func structgenBlock(tb testing.TB) *proto.Block {
+	schema, err := os.ReadFile(filepath.Join("internal", "structgentest", "event.schema"))
+	require.NoError(tb, err)
+	block := &proto.Block{}
+	for _, line := range strings.Split(strings.TrimSpace(string(schema)), "\n") {
+		name, typ, _ := strings.Cut(line, "\t")
+		require.NoError(tb, block.AddColumn(name, column.Type(typ)))
+	}
+	return block
+}
+
+// reflectedEvent has the fields of structgentest.Event without its generated methods
+type reflectedEvent structgentest.Event
+
+func TestBatchAppendStructAppender(t *testing.T) {
+	b := &batch{
+		block:       structgenBlock(t),
+		conn:        &connect{structMap: newStructMap(&Options{NameMapper: SnakeCaseNameMapper})},
+		connRelease: func(*connect, error) {},
+	}
+	event := structgentest.Event{Base: structgentest.Base{ID: 1}, UserName: "alice"}
+	require.NoError(t, b.AppendStruct(&event))
+	require.NoError(t, b.AppendStruct((*reflectedEvent)(&event)))
+	require.Equal(t, 2, b.block.Rows())
+	for i := range b.block.Columns {
+		assert.Equal(t, b.block.Columns[i].Row(0, false), b.block.Columns[i].Row(1, false), b.block.ColumnsNames()[i])
+	}
+
+	b.block.Columns = b.block.Columns[1:]
+	require.ErrorContains(t, b.AppendStruct(&event), "expected the 12 columns of *Event, got 11")
+	assert.ErrorIs(t, b.AppendStruct(&event), ErrBatchInvalid)
+}
+
+func BenchmarkAppendStruct(b *testing.B) {
+	referrer := "https://example.com"
+	event := structgentest.Event{
+		Base:     structgentest.Base{ID: 1},
+		UserName: "alice",
+		Home:     structgentest.Address{City: "Amsterdam", Zip: "1011"},
+		Score:    1.5,
+		Active:   true,
+		Day:      time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC),
+		At:       time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		Referrer: &referrer,
+		Tags:     []string{"a", "b"},
+		Attrs:    map[string]string{"k": "v"},
+	}
+	for _, bench := range []struct {
+		name string
+		v    any
+	}{
+		{name: "reflection", v: (*reflectedEvent)(&event)},
+		{name: "generated", v: &event},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			batch := &batch{
+				block:       structgenBlock(b),
+				conn:        &connect{structMap: newStructMap(&Options{NameMapper: SnakeCaseNameMapper})},
+				connRelease: func(*connect, error) {},
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if i%10000 == 0 {
+					for _, c := range batch.block.Columns {
+						c.Reset()
+					}
+				}
+				if err := batch.AppendStruct(bench.v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"sync/atomic"
 	"time"
@@ -99,13 +100,16 @@ type nativeTransport interface {
 	serverVersion() (*ServerVersion, error)
 	query(ctx context.Context, release nativeTransportRelease, query string, args ...any) (*rows, error)
 	queryRow(ctx context.Context, release nativeTransportRelease, query string, args ...any) *row
+	queryRaw(ctx context.Context, release nativeTransportRelease, query string, args ...any) (io.ReadCloser, error)
 	prepareBatch(ctx context.Context, release nativeTransportRelease, acquire nativeTransportAcquire, query string, opts driver.PrepareBatchOptions) (driver.Batch, error)
 	exec(ctx context.Context, query string, args ...any) error
 	asyncInsert(ctx context.Context, query string, wait bool, args ...any) error
+	insertFrom(ctx context.Context, query string, r io.Reader) error
 	ping(context.Context) error
 	isBad() bool
 	connID() int
 	connectedAtTime() time.Time
+	credentialsExpired() bool
 	isReleased() bool
 	setReleased(released bool)
 	debugf(format string, v ...any)
@@ -154,6 +158,17 @@ func (ch *clickhouse) Query(ctx context.Context, query string, args ...any) (row
 	return conn.query(ctx, ch.release, query, args...)
 }
 
+// QueryRaw runs a query ending with a FORMAT clause and returns the result in that format.
+// The connection is returned to the pool when the reader is closed.
+func (ch *clickhouse) QueryRaw(ctx context.Context, query string, args ...any) (io.ReadCloser, error) {
+	conn, err := ch.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn.debugf("[query raw] \"%s\"", query)
+	return conn.queryRaw(ctx, ch.release, query, args...)
+}
+
 func (ch *clickhouse) QueryRow(ctx context.Context, query string, args ...any) driver.Row {
 	conn, err := ch.acquire(ctx)
 	if err != nil {
@@ -177,6 +192,7 @@ func (ch *clickhouse) Exec(ctx context.Context, query string, args ...any) error
 		return err
 	}
 	ch.release(conn, nil)
+	ch.opt.schemaCache.invalidateOnSchemaChange(query)
 	return nil
 }
 
@@ -186,10 +202,17 @@ func (ch *clickhouse) PrepareBatch(ctx context.Context, query string, opts ...dr
 		return nil, err
 	}
 	conn.debugf("[prepare batch] \"%s\"", query)
-	batch, err := conn.prepareBatch(ctx, ch.release, ch.acquire, query, getPrepareBatchOptions(opts...))
+	options := getPrepareBatchOptions(opts...)
+	batch, err := conn.prepareBatch(ctx, ch.release, ch.acquire, query, options)
 	if err != nil {
 		return nil, err
 	}
+	if options.StructValidation != nil {
+		if err := validateBatchStruct(newStructMap(ch.opt), batch, options.StructValidation, options); err != nil {
+			_ = batch.Abort()
+			return nil, err
+		}
+	}
 	return batch, nil
 }
 
@@ -217,6 +240,25 @@ func (ch *clickhouse) AsyncInsert(ctx context.Context, query string, wait bool,
 	return nil
 }
 
+var _ driver.RawConn = (*clickhouse)(nil)
+
+// InsertFrom streams r to the server as the data of an "INSERT INTO ... FORMAT <name>" query.
+// The data is passed through as is, so it must already be encoded in the named format.
+// The native protocol streams the Native format only, block by block; other formats require HTTP.
+func (ch *clickhouse) InsertFrom(ctx context.Context, query string, r io.Reader) error {
+	conn, err := ch.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	conn.debugf("[insert from] \"%s\"", query)
+	if err := conn.insertFrom(ctx, query, r); err != nil {
+		ch.release(conn, err)
+		return err
+	}
+	ch.release(conn, nil)
+	return nil
+}
+
 func (ch *clickhouse) Ping(ctx context.Context) (err error) {
 	conn, err := ch.acquire(ctx)
 	if err != nil {
@@ -402,6 +444,10 @@ func (ch *clickhouse) release(conn nativeTransport, err error) {
 		conn.debugf("[close: lifetime expired]")
 		conn.close()
 		return
+	} else if conn.credentialsExpired() {
+		conn.debugf("[close: credentials expired]")
+		conn.close()
+		return
 	}
 
 	if ch.opt.FreeBufOnConnRelease {
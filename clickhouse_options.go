@@ -30,6 +30,7 @@ import (
 	"time"
 
 	"github.com/ClickHouse/ch-go/compress"
+	"golang.org/x/crypto/ssh"
 )
 
 type CompressionMethod byte
@@ -80,6 +81,9 @@ type Auth struct { // has_control_character
 
 	Username string
 	Password string
+	// SSHSigner authenticates native protocol connections with an SSH key instead of the password,
+	// for users identified with ssh_key. See SSHSignerFromFile.
+	SSHSigner ssh.Signer
 }
 
 type Compression struct {
@@ -103,6 +107,26 @@ const (
 	HTTP
 )
 
+// HTTPFormat is the format of the data of queries and inserts over HTTP.
+type HTTPFormat string
+
+const (
+	HTTPFormatNative    HTTPFormat = "Native"
+	HTTPFormatRowBinary HTTPFormat = "RowBinary"
+	// HTTPFormatRowBinaryWithNamesAndTypes is RowBinary with a header of the column names and types.
+	// Results are always read in this format when RowBinary is selected, as rows cannot be decoded
+	// without their types.
+	HTTPFormatRowBinaryWithNamesAndTypes HTTPFormat = "RowBinaryWithNamesAndTypes"
+)
+
+func (f HTTPFormat) valid() bool {
+	switch f {
+	case HTTPFormatNative, HTTPFormatRowBinary, HTTPFormatRowBinaryWithNamesAndTypes:
+		return true
+	}
+	return false
+}
+
 func (p Protocol) String() string {
 	switch p {
 	case Native:
@@ -157,11 +181,49 @@ type Options struct {
 	// HTTPProxy specifies an HTTP proxy URL to use for requests made by the client.
 	HTTPProxyURL *url.URL
 
+	// TransportFunc wraps or replaces the transport of HTTP connections, e.g. to record or instrument
+	// requests. It is called with the transport configured from the other options.
+	TransportFunc func(*http.Transport) (http.RoundTripper, error)
+
+	// HttpFormat is the format of the data of HTTP queries and inserts, HTTPFormatNative by default.
+	// It can be overridden per query with WithHTTPFormat.
+	HttpFormat HTTPFormat
+
+	// QuotaKey binds the connections to the quota of this key, for quotas keyed by client_key. It is
+	// sent in the handshake of native connections and with each HTTP request. WithQuotaKey overrides
+	// it for a query.
+	QuotaKey string
+
+	// ChunkedPackets asks servers that accept both framings to send and receive the packets of native
+	// connections in chunks. Servers that require a framing get it regardless.
+	ChunkedPackets bool
+
 	// GetJWT should return a JWT for authentication with ClickHouse Cloud.
 	// This is called per connection/request, so you may cache the token in your app if needed.
 	// Use this instead of Auth.Username and Auth.Password if you're using JWT auth.
 	GetJWT GetJWTFunc
 
+	// CredentialsProvider returns rotating credentials, taking precedence over the username, password
+	// and SSHSigner of Auth and over GetJWT. It is called when native connections are dialed and for
+	// each HTTP request; native connections are closed once their credentials expire.
+	CredentialsProvider CredentialsProvider
+
+	// NameMapper maps struct fields without a ch tag to column names in ScanStruct, Select, QueryAs,
+	// SelectAs and AppendStruct, e.g. SnakeCaseNameMapper. By default the field name is used as is.
+	NameMapper NameMapper
+	// TolerantStructMapping ignores result columns without a matching struct field when scanning
+	// and leaves struct fields without a matching column unset. INSERT columns without a matching
+	// field are appended with the default value of their type, e.g. NULL for Nullable columns and
+	// empty arrays for Array columns, not their DEFAULT expression. Prepare the batch with
+	// driver.WithOmitDefaults to leave columns with a DEFAULT out of the INSERT instead.
+	TolerantStructMapping bool
+	// SchemaCacheTTL is how long DescribeTable results, also used by HTTP batches, are cached.
+	// Zero, the default, disables caching so that schema changes made elsewhere are seen at once.
+	// Otherwise the TTL is the only bound on staleness: schema changes are detected only for
+	// statements run with Exec on this connection, see DescribeTable.
+	SchemaCacheTTL time.Duration
+
+	schemaCache *schemaCache
 	scheme      string
 	ReadTimeout time.Duration
 }
@@ -185,9 +247,12 @@ func (o *Options) fromDSN(in string) error {
 	}
 	o.Addr = append(o.Addr, strings.Split(dsn.Host, ",")...)
 	var (
-		secure     bool
-		params     = dsn.Query()
-		skipVerify bool
+		secure        bool
+		params        = dsn.Query()
+		skipVerify    bool
+		sshKeyFile    string
+		sshPassphrase string
+		tlsParams     tlsDSN
 	)
 	o.Auth.Database = strings.TrimPrefix(dsn.Path, "/")
 
@@ -195,6 +260,28 @@ func (o *Options) fromDSN(in string) error {
 		switch v {
 		case "debug":
 			o.Debug, _ = strconv.ParseBool(params.Get(v))
+		case "tls_ca_file":
+			tlsParams.caFile = params.Get(v)
+		case "tls_cert_file":
+			tlsParams.certFile = params.Get(v)
+		case "tls_key_file":
+			tlsParams.keyFile = params.Get(v)
+		case "tls_server_name":
+			tlsParams.serverName = params.Get(v)
+		case "tls_min_version":
+			version, ok := tlsVersions[params.Get(v)]
+			if !ok {
+				return fmt.Errorf("clickhouse [dsn parse]: tls_min_version: unknown version %q", params.Get(v))
+			}
+			tlsParams.minVersion = version
+		case "ssh_key_file":
+			sshKeyFile = params.Get(v)
+		case "ssh_key_passphrase":
+			sshPassphrase = params.Get(v)
+		case "quota_key":
+			o.QuotaKey = params.Get(v)
+		case "chunked_packets":
+			o.ChunkedPackets, _ = strconv.ParseBool(params.Get(v))
 		case "compress":
 			if on, _ := strconv.ParseBool(params.Get(v)); on {
 				if o.Compression == nil {
@@ -327,6 +414,12 @@ func (o *Options) fromDSN(in string) error {
 				return fmt.Errorf("clickhouse [dsn parse]: http_proxy: %s", err)
 			}
 			o.HTTPProxyURL = proxyURL
+		case "http_format":
+			format := HTTPFormat(params.Get(v))
+			if !format.valid() {
+				return fmt.Errorf("clickhouse [dsn parse]: http_format: unknown format %q", format)
+			}
+			o.HttpFormat = format
 		default:
 			switch p := strings.ToLower(params.Get(v)); p {
 			case "true":
@@ -342,7 +435,22 @@ func (o *Options) fromDSN(in string) error {
 			}
 		}
 	}
-	if secure {
+	if sshKeyFile != "" {
+		if o.Auth.SSHSigner, err = SSHSignerFromFile(sshKeyFile, sshPassphrase); err != nil {
+			return fmt.Errorf("clickhouse [dsn parse]: ssh_key_file: %w", err)
+		}
+	}
+	switch {
+	case tlsParams.set():
+		// the TLS parameters imply secure, an explicit secure=false contradicts them
+		if params.Has("secure") && !secure {
+			return fmt.Errorf("clickhouse [dsn parse]: tls_* parameters require secure=true, got secure=false")
+		}
+		secure = true
+		if o.TLS, err = tlsParams.config(skipVerify); err != nil {
+			return fmt.Errorf("clickhouse [dsn parse]: %w", err)
+		}
+	case secure:
 		o.TLS = &tls.Config{
 			InsecureSkipVerify: skipVerify,
 		}
@@ -399,5 +507,6 @@ func (o Options) setDefaults() *Options {
 			o.Addr = []string{"localhost:8123"}
 		}
 	}
+	o.schemaCache = newSchemaCache(o.SchemaCacheTTL)
 	return &o
 }
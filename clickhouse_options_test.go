@@ -18,13 +18,19 @@
 package clickhouse
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
 	"crypto/tls"
+	"encoding/pem"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
 )
 
 // TestParseDSN does not implement all use cases yet
@@ -482,6 +488,51 @@ func TestParseDSN(t *testing.T) {
 			},
 			"",
 		},
+		{
+			"http protocol with RowBinary format",
+			"http://127.0.0.1/?http_format=RowBinary",
+			&Options{
+				Protocol:   HTTP,
+				TLS:        nil,
+				Addr:       []string{"127.0.0.1"},
+				Settings:   Settings{},
+				scheme:     "http",
+				HttpFormat: HTTPFormatRowBinary,
+			},
+			"",
+		},
+		{
+			"http protocol with unknown format",
+			"http://127.0.0.1/?http_format=CSV",
+			nil,
+			`clickhouse [dsn parse]: http_format: unknown format "CSV"`,
+		},
+		{
+			"native protocol with quota key",
+			"clickhouse://127.0.0.1/?quota_key=tenant-1",
+			&Options{
+				Protocol: Native,
+				TLS:      nil,
+				Addr:     []string{"127.0.0.1"},
+				Settings: Settings{},
+				scheme:   "clickhouse",
+				QuotaKey: "tenant-1",
+			},
+			"",
+		},
+		{
+			"native protocol with chunked packets",
+			"clickhouse://127.0.0.1/?chunked_packets=true",
+			&Options{
+				Protocol:       Native,
+				TLS:            nil,
+				Addr:           []string{"127.0.0.1"},
+				Settings:       Settings{},
+				scheme:         "clickhouse",
+				ChunkedPackets: true,
+			},
+			"",
+		},
 		{
 			"clickhouse proxy with database as query string",
 			"tcp://127.0.0.1/?database=bla",
@@ -515,6 +566,35 @@ func TestParseDSN(t *testing.T) {
 	}
 }
 
+func TestParseDSNSSHKeyFile(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	plain, err := ssh.MarshalPrivateKey(key, "")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "id_ed25519"), pem.EncodeToMemory(plain), 0o600))
+	encrypted, err := ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte("s3cret"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "id_encrypted"), pem.EncodeToMemory(encrypted), 0o600))
+
+	for _, params := range []string{
+		"ssh_key_file=" + url.QueryEscape(filepath.Join(dir, "id_ed25519")),
+		"ssh_key_file=" + url.QueryEscape(filepath.Join(dir, "id_encrypted")) + "&ssh_key_passphrase=s3cret",
+	} {
+		opts, err := ParseDSN("clickhouse://user@127.0.0.1/db?" + params)
+		require.NoError(t, err, params)
+		require.NotNil(t, opts.Auth.SSHSigner, params)
+		assert.Equal(t, signer.PublicKey().Marshal(), opts.Auth.SSHSigner.PublicKey().Marshal(), params)
+	}
+
+	_, err = ParseDSN("clickhouse://127.0.0.1/?ssh_key_file=" + url.QueryEscape(filepath.Join(dir, "id_encrypted")))
+	assert.ErrorContains(t, err, "clickhouse [dsn parse]: ssh_key_file:")
+	_, err = ParseDSN("clickhouse://127.0.0.1/?ssh_key_file=" + url.QueryEscape(filepath.Join(dir, "missing")))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
 func parseURL(t *testing.T, v string) *url.URL {
 	u, err := url.Parse(v)
 	require.NoError(t, err)
@@ -21,6 +21,7 @@ import (
 	"database/sql"
 	"io"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
 )
 
@@ -71,6 +72,31 @@ next:
 	return r.row <= r.block.Rows()
 }
 
+var _ driver.BlockRows = (*rows)(nil)
+
+// NextBlock advances to the next non-empty block, skipping any rows of the current block not yet read by Next.
+// The block returned by Block is valid until the next call to Next or NextBlock.
+func (r *rows) NextBlock() bool {
+	if r.block == nil {
+		return false
+	}
+	if r.row == 0 && r.block.Rows() != 0 {
+		r.row = r.block.Rows()
+		return true
+	}
+	r.row = r.block.Rows()
+	if !r.Next() {
+		return false
+	}
+	r.row = r.block.Rows()
+	return true
+}
+
+// Block returns the current block, see NextBlock.
+func (r *rows) Block() *proto.Block {
+	return r.block
+}
+
 func (r *rows) Scan(dest ...any) error {
 	if r.block == nil || (r.row == 0 && r.row >= r.block.Rows()) { // call without next when result is empty
 		return io.EOF
@@ -79,6 +105,12 @@ func (r *rows) Scan(dest ...any) error {
 }
 
 func (r *rows) ScanStruct(dest any) error {
+	if s, ok := dest.(driver.StructScanner); ok {
+		if r.block == nil || (r.row == 0 && r.row >= r.block.Rows()) {
+			return io.EOF
+		}
+		return s.ScanFrom(r.block.Columns, r.row-1)
+	}
 	values, err := r.structMap.Map("ScanStruct", r.columns, dest, true)
 	if err != nil {
 		return err
@@ -155,6 +187,11 @@ func (r *row) ScanStruct(dest any) error {
 	if r.err != nil {
 		return r.err
 	}
+	if s, ok := dest.(driver.StructScanner); ok {
+		return r.scanRow(func() error {
+			return r.rows.ScanStruct(s)
+		})
+	}
 	values, err := r.rows.structMap.Map("ScanStruct", r.rows.columns, dest, true)
 	if err != nil {
 		return err
@@ -166,6 +203,13 @@ func (r *row) Scan(dest ...any) error {
 	if r.err != nil {
 		return r.err
 	}
+	return r.scanRow(func() error {
+		return r.rows.Scan(dest...)
+	})
+}
+
+// scanRow advances to the first row, calls scan and closes the rows
+func (r *row) scanRow(scan func() error) error {
 	if !r.rows.Next() {
 		r.rows.Close()
 		if err := r.rows.Err(); err != nil {
@@ -173,7 +217,7 @@ func (r *row) Scan(dest ...any) error {
 		}
 		return sql.ErrNoRows
 	}
-	if err := r.rows.Scan(dest...); err != nil {
+	if err := scan(); err != nil {
 		return err
 	}
 	return r.rows.Close()
@@ -19,7 +19,9 @@ package clickhouse
 
 import (
 	"reflect"
+	"time"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 )
@@ -29,8 +31,21 @@ type columnType struct {
 	chType   string
 	nullable bool
 	scanType reflect.Type
+	parsed   *chtype.Type // nil if the type could not be parsed
 }
 
+func newColumnType(name string, parsed *chtype.Type, scanType reflect.Type) *columnType {
+	return &columnType{
+		name:     name,
+		chType:   parsed.String(),
+		nullable: parsed.Name == "Nullable",
+		scanType: scanType,
+		parsed:   parsed,
+	}
+}
+
+var _ driver.DetailedColumnType = (*columnType)(nil)
+
 func (c *columnType) Name() string {
 	return c.name
 }
@@ -47,16 +62,96 @@ func (c *columnType) DatabaseTypeName() string {
 	return c.chType
 }
 
+func (c *columnType) DecimalSize() (precision, scale int64, ok bool) {
+	if c.parsed == nil {
+		return 0, 0, false
+	}
+	t := c.parsed.Unwrap()
+	p, ok := t.Precision()
+	if !ok {
+		return 0, 0, false
+	}
+	s, _ := t.Scale()
+	return int64(p), int64(s), true
+}
+
+func (c *columnType) Length() (length int64, ok bool) {
+	if c.parsed == nil {
+		return 0, false
+	}
+	l, ok := c.parsed.Unwrap().Length()
+	return int64(l), ok
+}
+
+func (c *columnType) Timezone() (name string, ok bool) {
+	if c.parsed == nil {
+		return "", false
+	}
+	name = c.parsed.Unwrap().Timezone()
+	return name, len(name) != 0
+}
+
+func (c *columnType) EnumValues() map[string]int {
+	if c.parsed == nil {
+		return nil
+	}
+	values := c.parsed.Unwrap().EnumValues()
+	if values == nil {
+		return nil
+	}
+	enum := make(map[string]int, len(values))
+	for _, v := range values {
+		enum[v.Name] = v.Value
+	}
+	return enum
+}
+
+func (c *columnType) LowCardinality() bool {
+	return c.parsed != nil && c.parsed.Name == "LowCardinality"
+}
+
+func (c *columnType) Children() []driver.ColumnType {
+	if c.parsed == nil {
+		return nil
+	}
+	t := c.parsed.Unwrap()
+	switch t.Name {
+	case "Array", "Map", "Tuple", "Nested", "Variant":
+	default:
+		return nil
+	}
+	var children []driver.ColumnType
+	for _, arg := range t.TypeArgs() {
+		var scanType reflect.Type
+		if col, err := column.Type(arg.Type.String()).Column(arg.Name, time.UTC); err == nil {
+			scanType = col.ScanType()
+		}
+		children = append(children, newColumnType(arg.Name, arg.Type, scanType))
+	}
+	return children
+}
+
 func (r *rows) ColumnTypes() []driver.ColumnType {
 	types := make([]driver.ColumnType, 0, len(r.columns))
 	for i, c := range r.block.Columns {
-		_, nullable := c.(*column.Nullable)
-		types = append(types, &columnType{
-			name:     r.columns[i],
-			chType:   string(c.Type()),
-			nullable: nullable,
-			scanType: c.ScanType(),
-		})
+		types = append(types, columnTypeOf(r.columns[i], c))
 	}
 	return types
 }
+
+// columnTypeOf describes col, falling back to the raw type name if it cannot be parsed
+func columnTypeOf(name string, col column.Interface) *columnType {
+	parsed, err := chtype.Parse(string(col.Type()))
+	if err != nil {
+		_, nullable := col.(*column.Nullable)
+		return &columnType{
+			name:     name,
+			chType:   string(col.Type()),
+			nullable: nullable,
+			scanType: col.ScanType(),
+		}
+	}
+	c := newColumnType(name, parsed, col.ScanType())
+	c.chType = string(col.Type())
+	return c
+}
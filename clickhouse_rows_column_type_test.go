@@ -0,0 +1,107 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowsColumnTypes(t *testing.T) {
+	block := &proto.Block{}
+	for _, c := range [][2]string{
+		{"dec", "Decimal(18, 4)"},
+		{"dt64", "DateTime64(3, 'Europe/Berlin')"},
+		{"fs", "LowCardinality(Nullable(FixedString(8)))"},
+		{"enum", "Enum8('a' = 1, 'b' = 2)"},
+		{"arr", "Array(Nullable(String))"},
+		{"map", "Map(String, UInt64)"},
+		{"tuple", "Tuple(a String, b Int8)"},
+	} {
+		require.NoError(t, block.AddColumn(c[0], column.Type(c[1])))
+	}
+	r := &rows{block: block, columns: block.ColumnsNames()}
+	var types []driver.DetailedColumnType
+	for _, c := range r.ColumnTypes() {
+		detailed, ok := c.(driver.DetailedColumnType)
+		require.True(t, ok)
+		types = append(types, detailed)
+	}
+	require.Len(t, types, 7)
+
+	precision, scale, ok := types[0].DecimalSize()
+	assert.True(t, ok)
+	assert.Equal(t, [2]int64{18, 4}, [2]int64{precision, scale})
+
+	precision, _, ok = types[1].DecimalSize()
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), precision)
+	tz, ok := types[1].Timezone()
+	assert.True(t, ok)
+	assert.Equal(t, "Europe/Berlin", tz)
+
+	length, ok := types[2].Length()
+	assert.True(t, ok)
+	assert.Equal(t, int64(8), length)
+	assert.True(t, types[2].LowCardinality())
+	// Nullable only reports Nullable columns, not LowCardinality(Nullable) ones
+	assert.False(t, types[2].Nullable())
+	_, ok = types[0].Length()
+	assert.False(t, ok)
+
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, types[3].EnumValues())
+	assert.Nil(t, types[0].EnumValues())
+
+	children := types[4].Children()
+	require.Len(t, children, 1)
+	assert.Equal(t, "Nullable(String)", children[0].DatabaseTypeName())
+	assert.True(t, children[0].Nullable())
+	assert.Equal(t, reflect.TypeOf((*string)(nil)), children[0].ScanType())
+	_, ok = children[0].(driver.DetailedColumnType)
+	assert.True(t, ok)
+
+	children = types[5].Children()
+	require.Len(t, children, 2)
+	assert.Equal(t, "UInt64", children[1].DatabaseTypeName())
+
+	children = types[6].Children()
+	require.Len(t, children, 2)
+	assert.Equal(t, "b", children[1].Name())
+	assert.Equal(t, "Int8", children[1].DatabaseTypeName())
+	assert.Nil(t, types[0].Children())
+
+	std := &stdRows{rows: r}
+	length, ok = std.ColumnTypeLength(2)
+	assert.True(t, ok)
+	assert.Equal(t, int64(8), length)
+	length, ok = std.ColumnTypeLength(4)
+	assert.False(t, ok)
+
+	block = &proto.Block{}
+	require.NoError(t, block.AddColumn("s", "String"))
+	length, ok = (&stdRows{rows: &rows{block: block}}).ColumnTypeLength(0)
+	assert.True(t, ok)
+	assert.Equal(t, int64(math.MaxInt64), length)
+}
@@ -1,8 +1,10 @@
 package clickhouse
 
 import (
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"strconv"
 	"testing"
 )
@@ -114,3 +116,59 @@ func TestReadWithEmptyBlock(t *testing.T) {
 		})
 	}
 }
+
+func TestRowsNextBlock(t *testing.T) {
+	newBlock := func(n int) *proto.Block {
+		block := &proto.Block{}
+		block.AddColumn("col1", "Int64")
+		for i := 0; i < n; i++ {
+			block.Append(int64(i))
+		}
+		return block
+	}
+
+	blockChan := make(chan *proto.Block)
+	go func() {
+		for _, n := range []int{0, 2, 0, 3} {
+			blockChan <- newBlock(n)
+		}
+		close(blockChan)
+	}()
+	r := rows{
+		block:  newBlock(1),
+		stream: blockChan,
+	}
+
+	var sizes []int
+	for r.NextBlock() {
+		sizes = append(sizes, r.block.Rows())
+	}
+	assert.Equal(t, []int{1, 2, 3}, sizes)
+}
+
+type rowsTestScanner struct {
+	Col1  int64
+	calls int
+}
+
+func (s *rowsTestScanner) ScanFrom(columns []column.Interface, row int) error {
+	s.calls++
+	s.Col1 = columns[0].(*column.Int64).Value(row)
+	return nil
+}
+
+func TestRowsScanStructScanner(t *testing.T) {
+	block := &proto.Block{}
+	block.AddColumn("col1", "Int64")
+	require.NoError(t, block.Append(int64(42)))
+	r := &rows{
+		block:     block,
+		columns:   block.ColumnsNames(),
+		structMap: &structMap{},
+	}
+	require.True(t, r.Next())
+
+	var dest rowsTestScanner
+	require.NoError(t, r.ScanStruct(&dest))
+	assert.Equal(t, rowsTestScanner{Col1: 42, calls: 1}, dest)
+}
@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"math/rand"
 	"net"
 	"os"
@@ -324,6 +325,9 @@ func (std *stdDriver) ExecContext(ctx context.Context, query string, args []driv
 		std.debugf("ExecContext error: %v\n", err)
 		return nil, err
 	}
+	if std.opt != nil {
+		std.opt.schemaCache.invalidateOnSchemaChange(query)
+	}
 	return driver.RowsAffected(0), nil
 }
 
@@ -464,11 +468,21 @@ func (r *stdRows) ColumnTypePrecisionScale(idx int) (precision, scale int64, ok
 	return 0, 0, false
 }
 
+// ColumnTypeLength returns the length of FixedString columns and math.MaxInt64 for String columns.
+func (r *stdRows) ColumnTypeLength(idx int) (length int64, ok bool) {
+	c := columnTypeOf("", r.rows.block.Columns[idx])
+	if c.parsed != nil && c.parsed.Unwrap().Name == "String" {
+		return math.MaxInt64, true
+	}
+	return c.Length()
+}
+
 var _ driver.Rows = (*stdRows)(nil)
 var _ driver.RowsNextResultSet = (*stdRows)(nil)
 var _ driver.RowsColumnTypeDatabaseTypeName = (*stdRows)(nil)
 var _ driver.RowsColumnTypeNullable = (*stdRows)(nil)
 var _ driver.RowsColumnTypePrecisionScale = (*stdRows)(nil)
+var _ driver.RowsColumnTypeLength = (*stdRows)(nil)
 
 func (r *stdRows) Next(dest []driver.Value) error {
 	if len(r.rows.block.Columns) != len(dest) {
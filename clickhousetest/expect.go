@@ -0,0 +1,189 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhousetest
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+)
+
+// Column describes a result or table column. Only Name and Type are used for results.
+type Column = clickhouse.TableColumn
+
+// Result is a canned query result.
+type Result struct {
+	columns []Column
+	rows    [][]any
+}
+
+// NewResult returns an empty result with columns.
+func NewResult(columns ...Column) *Result {
+	return &Result{columns: columns}
+}
+
+// AddRow appends a row of values, one for each column, in the representation accepted by Batch.Append.
+func (r *Result) AddRow(values ...any) *Result {
+	r.rows = append(r.rows, values)
+	return r
+}
+
+func (r *Result) block(tz *time.Location) (*proto.Block, error) {
+	block := &proto.Block{Timezone: tz}
+	for _, c := range r.columns {
+		if err := block.AddColumn(c.Name, column.Type(c.Type)); err != nil {
+			return nil, err
+		}
+	}
+	for _, row := range r.rows {
+		if err := block.Append(row...); err != nil {
+			return nil, err
+		}
+	}
+	return block, nil
+}
+
+// Call is a query received by the server.
+type Call struct {
+	Query      string
+	QueryID    string
+	Settings   map[string]string
+	Parameters map[string]string
+	// Blocks are the blocks sent by the client for an INSERT
+	Blocks []*proto.Block
+}
+
+// Expectation is a scripted answer to the queries matching a pattern, see Server.Expect.
+type Expectation struct {
+	pattern *regexp.Regexp
+	times   int
+	result  *Result
+	blocks  []*proto.Block
+	err     *proto.Exception
+	// insertErr answers INSERTs once their data is received
+	insertErr *proto.Exception
+	calls     []Call
+}
+
+// WillReturn answers matching queries with result.
+func (e *Expectation) WillReturn(result *Result) *Expectation {
+	e.result = result
+	return e
+}
+
+// WillReturnBlocks answers matching queries with blocks, which must have the same columns.
+func (e *Expectation) WillReturnBlocks(blocks ...*proto.Block) *Expectation {
+	e.blocks = blocks
+	return e
+}
+
+// WillFail answers matching queries with an exception.
+func (e *Expectation) WillFail(code int32, message string) *Expectation {
+	e.err = &proto.Exception{
+		Code:    code,
+		Name:    "DB::Exception",
+		Message: message,
+	}
+	return e
+}
+
+// WillFailInsert answers matching INSERTs with an exception once the client has sent all of
+// their data, as when the server fails to commit it. The blocks are still recorded in Calls.
+func (e *Expectation) WillFailInsert(code int32, message string) *Expectation {
+	e.insertErr = &proto.Exception{
+		Code:    code,
+		Name:    "DB::Exception",
+		Message: message,
+	}
+	return e
+}
+
+// Times sets how many queries the expectation answers, once by default.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// AnyTimes lets the expectation answer any number of queries, including none.
+func (e *Expectation) AnyTimes() *Expectation {
+	e.times = 0
+	return e
+}
+
+// Calls returns the queries answered by the expectation.
+//
+// Calls must not be called concurrently with queries answered by the expectation.
+func (e *Expectation) Calls() []Call {
+	return e.calls
+}
+
+// Inserted returns the blocks inserted by the queries answered by the expectation.
+func (e *Expectation) Inserted() []*proto.Block {
+	var blocks []*proto.Block
+	for _, call := range e.calls {
+		blocks = append(blocks, call.Blocks...)
+	}
+	return blocks
+}
+
+// InsertedRows returns the number of rows inserted by the queries answered by the expectation.
+func (e *Expectation) InsertedRows() int {
+	var rows int
+	for _, block := range e.Inserted() {
+		rows += block.Rows()
+	}
+	return rows
+}
+
+// response returns the blocks answering a query, starting with the header block. No blocks are
+// returned for expectations without a result, as for DDL statements.
+func (e *Expectation) response(tz *time.Location) ([]*proto.Block, error) {
+	switch {
+	case e.result != nil:
+		block, err := e.result.block(tz)
+		if err != nil {
+			return nil, err
+		}
+		return withHeader(block)
+	case len(e.blocks) != 0:
+		header, err := withHeader(e.blocks[0])
+		if err != nil {
+			return nil, err
+		}
+		return append(header[:1], e.blocks...), nil
+	}
+	return nil, nil
+}
+
+// withHeader returns an empty block with the columns of block, followed by block
+func withHeader(block *proto.Block) ([]*proto.Block, error) {
+	header := &proto.Block{Timezone: block.Timezone}
+	for _, c := range block.Columns {
+		if err := header.AddColumn(c.Name(), c.Type()); err != nil {
+			return nil, fmt.Errorf("clickhousetest: header of column %s: %w", c.Name(), err)
+		}
+	}
+	if block.Rows() == 0 {
+		return []*proto.Block{header}, nil
+	}
+	return []*proto.Block{header, block}, nil
+}
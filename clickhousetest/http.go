@@ -0,0 +1,257 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhousetest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ClickHouse/ch-go/compress"
+	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+)
+
+// httpParams are the URL parameters of the HTTP interface that are not settings
+var httpParams = map[string]bool{
+	"query":          true,
+	"query_id":       true,
+	"quota_key":      true,
+	"database":       true,
+	"default_format": true,
+	"compress":       true,
+	"decompress":     true,
+}
+
+func (s *Server) httpHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ping" {
+			_, _ = io.WriteString(w, "Ok.\n")
+			return
+		}
+		if err := s.serveHTTP(w, r); err != nil {
+			writeHTTPException(w, err, r.URL.Query().Get("compress") == "1")
+		}
+	})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) error {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		username, password = r.Header.Get("X-ClickHouse-User"), r.Header.Get("X-ClickHouse-Key")
+	}
+	if err := s.authenticate(username, password); err != nil {
+		return err
+	}
+	call, body, err := httpCall(r)
+	if err != nil {
+		return err
+	}
+
+	var (
+		params     = r.URL.Query()
+		compressed = params.Get("compress") == "1"
+		e          *Expectation
+		blocks     []*proto.Block
+	)
+	if strings.TrimSpace(call.Query) != helloQuery {
+		e = s.match(call.Query)
+	}
+	switch {
+	case e != nil && e.err != nil:
+		s.record(e, call)
+		return e.err
+	case e != nil:
+		if table, columns, ok := insertData(call.Query); ok {
+			reader := chproto.NewReader(body)
+			if params.Get("decompress") == "1" {
+				reader.EnableCompression()
+			}
+			read := func() (*proto.Block, error) {
+				block := &proto.Block{Timezone: s.timezone}
+				if err := block.Decode(reader, 0); err != nil {
+					return nil, err
+				}
+				return block, nil
+			}
+			if format := insertFormatMatch.FindStringSubmatch(call.Query); format != nil && !strings.EqualFold(format[1], "Native") {
+				var header *proto.Block
+				if strings.EqualFold(format[1], "RowBinary") {
+					if header, err = s.insertHeader(table, columns); err != nil {
+						return err
+					}
+				}
+				var source io.Reader = body
+				if params.Get("decompress") == "1" {
+					source = compress.NewReader(body)
+				}
+				read = rowBinaryBlocks(proto.NewRowBinaryReader(source, s.timezone, header))
+			}
+			for {
+				block, err := read()
+				if err != nil {
+					if errors.Is(err, io.EOF) {
+						break
+					}
+					return err
+				}
+				call.Blocks = append(call.Blocks, block)
+			}
+			s.record(e, call)
+			if e.insertErr != nil {
+				return e.insertErr
+			}
+			return nil
+		}
+		if blocks, err = e.response(s.timezone); err != nil {
+			return err
+		}
+		s.record(e, call)
+	default:
+		if blocks, err = s.builtin(call.Query); err != nil {
+			return err
+		}
+		if blocks == nil {
+			s.record(nil, call)
+			return unexpectedQuery(call.Query)
+		}
+	}
+
+	var (
+		buffer     chproto.Buffer
+		compressor = compress.NewWriter(compress.LevelZero, compress.LZ4)
+		format     = "Native"
+	)
+	if params.Get("default_format") == "RowBinaryWithNamesAndTypes" {
+		format = "RowBinaryWithNamesAndTypes"
+	}
+	for i, block := range blocks {
+		start := len(buffer.Buf)
+		switch format {
+		case "RowBinaryWithNamesAndTypes":
+			// the names and types are only written once, before the rows of the first block
+			if err := block.EncodeRowBinary(&buffer, i == 0); err != nil {
+				return err
+			}
+		default:
+			if err := block.Encode(&buffer, 0); err != nil {
+				return err
+			}
+		}
+		if compressed {
+			if err := compressor.Compress(buffer.Buf[start:]); err != nil {
+				return err
+			}
+			buffer.Buf = append(buffer.Buf[:start], compressor.Data...)
+		}
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(buffer.Buf)))
+	w.Header().Set("X-ClickHouse-Format", format)
+	_, err = w.Write(buffer.Buf)
+	return err
+}
+
+// insertFormatMatch matches the format of the data of an INSERT
+var insertFormatMatch = regexp.MustCompile(`(?is)\sFORMAT\s+(\w+)\s*$`)
+
+// rowBinaryBlocks returns a func reading the blocks of reader that have rows, until io.EOF
+func rowBinaryBlocks(reader *proto.RowBinaryReader) func() (*proto.Block, error) {
+	return func() (*proto.Block, error) {
+		for {
+			block, err := reader.ReadBlock(1 << 16)
+			if err != nil || block.Rows() != 0 {
+				return block, err
+			}
+		}
+	}
+}
+
+// httpCall returns the query, settings and parameters of r and the rest of its body
+func httpCall(r *http.Request) (Call, io.Reader, error) {
+	var (
+		params = r.URL.Query()
+		call   = Call{
+			Query:      params.Get("query"),
+			QueryID:    params.Get("query_id"),
+			Settings:   make(map[string]string),
+			Parameters: make(map[string]string),
+		}
+		body io.Reader = r.Body
+	)
+	if body == nil {
+		body = http.NoBody
+	}
+	for key := range params {
+		switch name, isParam := strings.CutPrefix(key, "param_"); {
+		case isParam:
+			call.Parameters[name] = params.Get(key)
+		case httpParams[key], strings.HasSuffix(key, "_format"), strings.HasSuffix(key, "_structure"):
+		default:
+			call.Settings[key] = params.Get(key)
+		}
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		// external tables, the query is a form field
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return call, nil, err
+		}
+		call.Query, body = r.FormValue("query"), http.NoBody
+	}
+	if len(call.Query) == 0 {
+		query, err := io.ReadAll(body)
+		if err != nil {
+			return call, nil, err
+		}
+		call.Query, body = string(query), http.NoBody
+	}
+	return call, body, nil
+}
+
+// writeHTTPException writes err as an exception message, compressed like results if compressed is set
+func writeHTTPException(w http.ResponseWriter, err error, compressed bool) {
+	var exception *proto.Exception
+	if !errors.As(err, &exception) {
+		exception = &proto.Exception{
+			Code:    CodeUnexpectedQuery,
+			Name:    "DB::Exception",
+			Message: err.Error(),
+		}
+	}
+	status := http.StatusInternalServerError
+	switch exception.Code {
+	case CodeAuthenticationFailed:
+		status = http.StatusUnauthorized
+	case CodeUnknownTable:
+		status = http.StatusNotFound
+	}
+	message := []byte(fmt.Sprintf("Code: %d. %s: %s\n", exception.Code, exception.Name, exception.Message))
+	if compressed {
+		compressor := compress.NewWriter(compress.LevelZero, compress.LZ4)
+		if err := compressor.Compress(message); err == nil {
+			message = compressor.Data
+		}
+	}
+	w.Header().Set("X-ClickHouse-Exception-Code", strconv.Itoa(int(exception.Code)))
+	w.WriteHeader(status)
+	_, _ = w.Write(message)
+}
@@ -0,0 +1,121 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhousetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/server"
+)
+
+// nativeHandler answers native protocol clients
+type nativeHandler struct {
+	server *Server
+}
+
+func (h *nativeHandler) Handshake(_ context.Context, hello *server.Hello) error {
+	return h.server.authenticate(hello.Username, hello.Password)
+}
+
+func (h *nativeHandler) Query(_ context.Context, conn *server.Conn, q *server.Query) error {
+	call := nativeCall(&q.Query)
+	e := h.server.match(q.Body)
+	if table, columns, ok := insertData(q.Body); ok && (e == nil || e.err == nil) {
+		// the table is checked first, as by the server
+		header, err := h.server.insertHeader(table, columns)
+		if err != nil {
+			return err
+		}
+		if e != nil {
+			return h.insert(conn, e, call, header)
+		}
+	}
+	var (
+		blocks []*proto.Block
+		err    error
+	)
+	switch {
+	case e != nil && e.err != nil:
+		h.server.record(e, call)
+		return e.err
+	case e != nil:
+		if blocks, err = e.response(h.server.timezone); err != nil {
+			return err
+		}
+		h.server.record(e, call)
+	default:
+		if blocks, err = h.server.builtin(q.Body); err != nil {
+			return err
+		}
+		if blocks == nil {
+			h.server.record(nil, call)
+			return unexpectedQuery(q.Body)
+		}
+	}
+	for _, block := range blocks {
+		if err := conn.WriteData(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nativeCall returns the query, settings and parameters of q
+func nativeCall(q *proto.Query) Call {
+	call := Call{
+		Query:      q.Body,
+		QueryID:    q.ID,
+		Settings:   make(map[string]string),
+		Parameters: make(map[string]string),
+	}
+	for _, s := range q.Settings {
+		call.Settings[s.Key] = fmt.Sprint(s.Value)
+	}
+	for _, p := range q.Parameters {
+		call.Parameters[p.Key] = p.Value
+	}
+	return call
+}
+
+// insert sends the header of the table and records the blocks sent by the client
+func (h *nativeHandler) insert(conn *server.Conn, e *Expectation, call Call, header *proto.Block) error {
+	if err := conn.WriteData(header); err != nil {
+		return err
+	}
+	for {
+		block, err := conn.ReadData()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if block.Rows() != 0 {
+			call.Blocks = append(call.Blocks, block)
+		}
+	}
+	h.server.record(e, call)
+	if e.insertErr != nil {
+		return e.insertErr
+	}
+	return nil
+}
@@ -0,0 +1,573 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhousetest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+)
+
+// Recorder records the queries of clients and the responses of a real server to a file, which a
+// Replayer serves later without the server. Native protocol connections are recorded by DialContext
+// at packet granularity, HTTP requests by the round tripper returned by Transport. Responses are
+// buffered whole, so the recorder is meant for tests rather than large results.
+//
+//	rec := clickhousetest.NewRecorder("testdata/events.json")
+//	conn, err := clickhouse.Open(rec.Apply(&clickhouse.Options{Addr: []string{"localhost:9000"}}))
+//	...
+//	err = conn.Close()
+//	err = rec.Close()
+type Recorder struct {
+	path string
+
+	mu        sync.Mutex
+	recording recording
+	conns     map[*recordConn]struct{}
+	errs      []error
+	wg        sync.WaitGroup
+}
+
+// NewRecorder returns a recorder writing to path when it is closed.
+func NewRecorder(path string) *Recorder {
+	return &Recorder{
+		path:  path,
+		conns: make(map[*recordConn]struct{}),
+	}
+}
+
+// Apply makes opt record its connections: DialContext for the native protocol and TransportFunc
+// for HTTP are wrapped, or set if they are nil. It returns opt.
+func (r *Recorder) Apply(opt *clickhouse.Options) *clickhouse.Options {
+	switch opt.Protocol {
+	case clickhouse.HTTP:
+		next := opt.TransportFunc
+		opt.TransportFunc = func(t *http.Transport) (http.RoundTripper, error) {
+			if next == nil {
+				return r.Transport(t)
+			}
+			rt, err := next(t)
+			if err != nil {
+				return nil, err
+			}
+			return r.Transport(rt)
+		}
+	default:
+		dial := opt.DialContext
+		opt.DialContext = func(ctx context.Context, addr string) (net.Conn, error) {
+			if dial == nil {
+				return r.DialContext(ctx, addr)
+			}
+			conn, err := dial(ctx, addr)
+			if err != nil {
+				return nil, err
+			}
+			return r.Record(conn), nil
+		}
+	}
+	return opt
+}
+
+// DialContext connects to the native protocol server at addr over TCP and records the connection.
+func (r *Recorder) DialContext(ctx context.Context, addr string) (net.Conn, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return r.Record(conn), nil
+}
+
+// Record returns conn, a native protocol connection to a server, recording its traffic.
+func (r *Recorder) Record(conn net.Conn) net.Conn {
+	clientReader, clientWriter := io.Pipe()
+	serverReader, serverWriter := io.Pipe()
+	var (
+		c = &recordConn{
+			Conn:     conn,
+			recorder: r,
+			client:   clientWriter,
+			server:   serverWriter,
+		}
+		s = &nativeRecording{
+			recorder:    r,
+			clientHello: make(chan uint64, 1),
+			serverHello: make(chan struct{}),
+			pending:     make(chan pendingQuery, 16),
+			clientDone:  make(chan struct{}),
+			serverDone:  make(chan struct{}),
+		}
+	)
+	r.mu.Lock()
+	r.conns[c] = struct{}{}
+	r.mu.Unlock()
+	r.wg.Add(2)
+	go func() {
+		defer r.wg.Done()
+		r.fail(s.readClient(bufio.NewReaderSize(clientReader, 128<<10)))
+		close(s.clientDone)
+		// keep the connection going when the stream cannot be parsed
+		_, _ = io.Copy(io.Discard, clientReader)
+	}()
+	go func() {
+		defer r.wg.Done()
+		r.fail(s.readServer(bufio.NewReaderSize(serverReader, 128<<10)))
+		s.closeServerHello.Do(func() { close(s.serverHello) })
+		close(s.serverDone)
+		_, _ = io.Copy(io.Discard, serverReader)
+	}()
+	return c
+}
+
+// Transport returns a round tripper recording the HTTP requests sent through next.
+func (r *Recorder) Transport(next http.RoundTripper) (http.RoundTripper, error) {
+	return &recordTransport{recorder: r, next: next}, nil
+}
+
+// Close closes the recorded connections that are still open and writes the recording. It returns
+// the errors of the connections that could not be recorded.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	for c := range r.conns {
+		_ = c.Close()
+	}
+	r.mu.Unlock()
+	r.wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return errors.Join(append(r.errs, r.recording.write(r.path))...)
+}
+
+func (r *Recorder) add(e *exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recording.Exchanges = append(r.recording.Exchanges, e)
+}
+
+func (r *Recorder) fail(err error) {
+	if err == nil || errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, fmt.Errorf("clickhousetest: recording: %w", err))
+}
+
+// recordConn copies the traffic of a connection to the readers of a nativeRecording
+type recordConn struct {
+	net.Conn
+	recorder *Recorder
+	client   *io.PipeWriter
+	server   *io.PipeWriter
+	once     sync.Once
+}
+
+func (c *recordConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		_, _ = c.server.Write(p[:n])
+	}
+	return n, err
+}
+
+func (c *recordConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if n > 0 {
+		_, _ = c.client.Write(p[:n])
+	}
+	return n, err
+}
+
+func (c *recordConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() {
+		_ = c.client.Close()
+		_ = c.server.Close()
+		c.recorder.mu.Lock()
+		delete(c.recorder.conns, c)
+		c.recorder.mu.Unlock()
+	})
+	return err
+}
+
+// pendingQuery is a query waiting for the response of the server
+type pendingQuery struct {
+	exchange    *exchange
+	compression bool
+	ping        bool
+}
+
+// nativeRecording parses both directions of a native protocol connection and pairs each query
+// of the client with the response of the server
+type nativeRecording struct {
+	recorder *Recorder
+	// set by readServer before serverHello is closed
+	revision uint64
+	timezone *time.Location
+	// set by readClient from the addendum, before the first query is pushed
+	chunkedRecv bool
+
+	clientHello      chan uint64
+	serverHello      chan struct{}
+	closeServerHello sync.Once
+	pending          chan pendingQuery
+	clientDone       chan struct{}
+	serverDone       chan struct{}
+}
+
+func (s *nativeRecording) readClient(source *bufio.Reader) error {
+	var (
+		reader      = chproto.NewReader(source)
+		compression bool
+	)
+	for {
+		code, err := reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch code {
+		case proto.ClientHello:
+			var hello proto.ClientHandshake
+			if err := hello.Decode(reader); err != nil {
+				return err
+			}
+			var credentials [3]string // database, user and password
+			for i := range credentials {
+				if credentials[i], err = reader.Str(); err != nil {
+					return err
+				}
+			}
+			if strings.HasPrefix(credentials[1], proto.SSHKeyAuthMarker) {
+				// the challenge request, and the signed challenge after the server sent it
+				for _, expected := range []byte{proto.ClientSSHChallengeRequest, proto.ClientSSHChallengeResponse} {
+					if code, err = reader.ReadByte(); err != nil {
+						return err
+					}
+					if code != expected {
+						return fmt.Errorf("unexpected client packet %d, expected %d", code, expected)
+					}
+				}
+				if _, err := reader.Str(); err != nil {
+					return err
+				}
+			}
+			s.clientHello <- hello.ProtocolVersion
+			<-s.serverHello
+			if s.timezone == nil {
+				// rejected, or the server stream failed
+				return nil
+			}
+			if s.revision >= proto.DBMS_MIN_PROTOCOL_VERSION_WITH_ADDENDUM {
+				if _, err := reader.Str(); err != nil { // quota key
+					return err
+				}
+			}
+			var chunkedSend bool
+			if s.revision >= proto.DBMS_MIN_PROTOCOL_VERSION_WITH_CHUNKED_PACKETS {
+				for _, chunked := range []*bool{&chunkedSend, &s.chunkedRecv} {
+					framing, err := reader.Str()
+					if err != nil {
+						return err
+					}
+					*chunked = framing == proto.FramingChunked
+				}
+			}
+			if s.revision >= proto.DBMS_MIN_REVISION_WITH_VERSIONED_PARALLEL_REPLICAS_PROTOCOL {
+				if _, err := reader.UVarInt(); err != nil {
+					return err
+				}
+			}
+			if chunkedSend {
+				reader = chproto.NewReader(proto.NewChunkedReader(source))
+			}
+		case proto.ClientPing:
+			if err := s.push(pendingQuery{ping: true}); err != nil {
+				return err
+			}
+		case proto.ClientQuery:
+			var query proto.Query
+			if err := query.Decode(reader, s.revision); err != nil {
+				return err
+			}
+			compression = query.Compression
+			// external tables end with an empty block
+			for {
+				if code, err = reader.ReadByte(); err != nil {
+					return err
+				}
+				if code != proto.ClientData {
+					return fmt.Errorf("unexpected client packet %d, expected external tables", code)
+				}
+				block, err := s.readBlock(reader, compression)
+				if err != nil {
+					return err
+				}
+				if len(block.Columns) == 0 {
+					break
+				}
+			}
+			call := nativeCall(&query)
+			e := &exchange{
+				Protocol:   protocolNative,
+				Query:      call.Query,
+				Settings:   call.Settings,
+				Parameters: call.Parameters,
+				Revision:   s.revision,
+			}
+			s.recorder.add(e)
+			if err := s.push(pendingQuery{exchange: e, compression: compression}); err != nil {
+				return err
+			}
+		case proto.ClientData:
+			// the data of an INSERT is not part of the response
+			if _, err := s.readBlock(reader, compression); err != nil {
+				return err
+			}
+		case proto.ClientCancel:
+		default:
+			return fmt.Errorf("unexpected client packet %d", code)
+		}
+	}
+}
+
+func (s *nativeRecording) push(query pendingQuery) error {
+	select {
+	case s.pending <- query:
+		return nil
+	case <-s.serverDone:
+		return errors.New("server stream ended")
+	}
+}
+
+func (s *nativeRecording) readServer(source *bufio.Reader) error {
+	reader := chproto.NewReader(source)
+	code, err := reader.ReadByte()
+	if err != nil {
+		return err
+	}
+	if code == proto.ServerSSHChallenge {
+		if _, err := reader.Str(); err != nil {
+			return err
+		}
+		if code, err = reader.ReadByte(); err != nil {
+			return err
+		}
+	}
+	if code != proto.ServerHello {
+		// a rejected client
+		return nil
+	}
+	// the fields of the server hello depend on the revision of the client
+	var clientRevision uint64
+	select {
+	case clientRevision = <-s.clientHello:
+	case <-s.clientDone:
+		return errors.New("no client hello")
+	}
+	var hello proto.ServerHandshake
+	if err := hello.Decode(reader, clientRevision); err != nil {
+		return err
+	}
+	s.revision, s.timezone = min(clientRevision, hello.Revision), hello.Timezone
+	s.closeServerHello.Do(func() { close(s.serverHello) })
+
+	s.recorder.mu.Lock()
+	if s.recorder.recording.Server == nil {
+		s.recorder.recording.Server = &recordedServer{
+			Name:        hello.Name,
+			DisplayName: hello.DisplayName,
+			Version:     hello.Version,
+			Revision:    hello.Revision,
+			Timezone:    hello.Timezone.String(),
+		}
+	}
+	s.recorder.mu.Unlock()
+
+	chunked := false
+	for {
+		var query pendingQuery
+		select {
+		case query = <-s.pending:
+		case <-s.clientDone:
+			select {
+			case query = <-s.pending:
+			default:
+				return nil
+			}
+		}
+		if s.chunkedRecv && !chunked {
+			// the server answers nothing before the first query
+			reader, chunked = chproto.NewReader(proto.NewChunkedReader(source)), true
+		}
+		if query.ping {
+			if code, err := reader.ReadByte(); err != nil || code != proto.ServerPong {
+				return errors.Join(err, fmt.Errorf("unexpected server packet %d, expected pong", code))
+			}
+			continue
+		}
+		packets, err := s.readResponse(reader, query.compression)
+		s.recorder.mu.Lock()
+		query.exchange.Packets = packets
+		s.recorder.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readResponse reads the packets of the response to a query
+func (s *nativeRecording) readResponse(reader *chproto.Reader, compression bool) ([]recordedPacket, error) {
+	var packets []recordedPacket
+	for {
+		code, err := reader.ReadByte()
+		if err != nil {
+			return packets, err
+		}
+		var buffer chproto.Buffer
+		switch code {
+		case proto.ServerData, proto.ServerTotals, proto.ServerExtremes, proto.ServerLog, proto.ServerProfileEvents:
+			block, err := s.readBlock(reader, compression && code != proto.ServerLog && code != proto.ServerProfileEvents)
+			if err != nil {
+				return packets, err
+			}
+			if err := block.Encode(&buffer, s.revision); err != nil {
+				return packets, err
+			}
+		case proto.ServerProgress:
+			var progress proto.Progress
+			if err := progress.Decode(reader, s.revision); err != nil {
+				return packets, err
+			}
+			progress.Encode(&buffer, s.revision)
+		case proto.ServerProfileInfo:
+			var info proto.ProfileInfo
+			if err := info.Decode(reader, s.revision); err != nil {
+				return packets, err
+			}
+			info.Encode(&buffer, s.revision)
+		case proto.ServerTableColumns:
+			var columns proto.TableColumns
+			if err := columns.Decode(reader, s.revision); err != nil {
+				return packets, err
+			}
+			columns.Encode(&buffer, s.revision)
+		case proto.ServerTimezoneUpdate:
+			name, err := reader.Str()
+			if err != nil {
+				return packets, err
+			}
+			buffer.PutString(name)
+		case proto.ServerPartUUIDs:
+			var uuids proto.PartUUIDs
+			if err := uuids.Decode(reader); err != nil {
+				return packets, err
+			}
+			uuids.Encode(&buffer)
+		case proto.ServerException:
+			var exception proto.Exception
+			if err := exception.Decode(reader); err != nil {
+				return packets, err
+			}
+			exception.Encode(&buffer)
+		case proto.ServerEndOfStream:
+		default:
+			return packets, fmt.Errorf("unexpected server packet %d", code)
+		}
+		packets = append(packets, recordedPacket{Code: code, Type: packetTypes[code], Data: buffer.Buf})
+		if code == proto.ServerException || code == proto.ServerEndOfStream {
+			return packets, nil
+		}
+	}
+}
+
+func (s *nativeRecording) readBlock(reader *chproto.Reader, compression bool) (*proto.Block, error) {
+	if _, err := reader.Str(); err != nil {
+		return nil, err
+	}
+	if compression {
+		reader.EnableCompression()
+		defer reader.DisableCompression()
+	}
+	block := &proto.Block{Timezone: s.timezone}
+	if err := block.Decode(reader, s.revision); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// recordTransport records the HTTP requests sent through next
+type recordTransport struct {
+	recorder *Recorder
+	next     http.RoundTripper
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	probe := req.Clone(req.Context())
+	probe.Body = io.NopCloser(bytes.NewReader(body))
+	call, _, callErr := httpCall(probe)
+
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	if callErr != nil {
+		t.recorder.fail(callErr)
+		return resp, nil
+	}
+	header := resp.Header.Clone()
+	header.Del("Date")
+	t.recorder.add(&exchange{
+		Protocol:   protocolHTTP,
+		Query:      call.Query,
+		Settings:   call.Settings,
+		Parameters: call.Parameters,
+		Status:     resp.StatusCode,
+		Header:     header,
+		Body:       data,
+	})
+	return resp, nil
+}
@@ -0,0 +1,134 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhousetest
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+)
+
+const (
+	protocolNative = "native"
+	protocolHTTP   = "http"
+)
+
+// recording is the content of a file written by a Recorder
+type recording struct {
+	Server    *recordedServer `json:"server,omitempty"`
+	Exchanges []*exchange     `json:"exchanges"`
+}
+
+// recordedServer is the handshake of the recorded native protocol server
+type recordedServer struct {
+	Name        string        `json:"name"`
+	DisplayName string        `json:"display_name"`
+	Version     proto.Version `json:"version"`
+	Revision    uint64        `json:"revision"`
+	Timezone    string        `json:"timezone"`
+}
+
+// exchange is a query and the response of the server. Native protocol responses are kept as
+// packets and HTTP responses as is.
+type exchange struct {
+	Protocol   string            `json:"protocol"`
+	Query      string            `json:"query"`
+	Settings   map[string]string `json:"settings,omitempty"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	Revision uint64           `json:"revision,omitempty"`
+	Packets  []recordedPacket `json:"packets,omitempty"`
+
+	Status int                 `json:"status,omitempty"`
+	Header map[string][]string `json:"header,omitempty"`
+	Body   []byte              `json:"body,omitempty"`
+
+	replayed bool
+}
+
+// recordedPacket is a server packet, the blocks of data packets are stored uncompressed
+type recordedPacket struct {
+	Code byte   `json:"code"`
+	Type string `json:"type"`
+	Data []byte `json:"data,omitempty"`
+}
+
+var packetTypes = map[byte]string{
+	proto.ServerData:           "Data",
+	proto.ServerException:      "Exception",
+	proto.ServerProgress:       "Progress",
+	proto.ServerEndOfStream:    "EndOfStream",
+	proto.ServerProfileInfo:    "ProfileInfo",
+	proto.ServerTotals:         "Totals",
+	proto.ServerExtremes:       "Extremes",
+	proto.ServerLog:            "Log",
+	proto.ServerTableColumns:   "TableColumns",
+	proto.ServerProfileEvents:  "ProfileEvents",
+	proto.ServerPartUUIDs:      "PartUUIDs",
+	proto.ServerTimezoneUpdate: "TimezoneUpdate",
+}
+
+func readRecording(path string) (*recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r recording
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (r *recording) write(path string) error {
+	if r.Exchanges == nil {
+		r.Exchanges = []*exchange{}
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// find returns the first exchange of call that was not replayed yet, or the last one if all of
+// them were, so that repeated queries can be replayed any number of times
+func (r *recording) find(protocol string, call Call) *exchange {
+	var last *exchange
+	for _, e := range r.Exchanges {
+		if !e.matches(protocol, call) {
+			continue
+		}
+		if !e.replayed {
+			e.replayed = true
+			return e
+		}
+		last = e
+	}
+	return last
+}
+
+func (e *exchange) matches(protocol string, call Call) bool {
+	return e.Protocol == protocol &&
+		strings.TrimSpace(e.Query) == strings.TrimSpace(call.Query) &&
+		maps.Equal(e.Settings, call.Settings) &&
+		maps.Equal(e.Parameters, call.Parameters)
+}
@@ -0,0 +1,244 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhousetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/server"
+)
+
+// Replayer serves the responses recorded by a Recorder without a server. Queries are matched on
+// their text, settings and parameters, repeated queries are answered with the responses recorded
+// in the same order. Queries that were not recorded fail with CodeUnexpectedQuery. Clients must use
+// the compression of the recording.
+//
+//	rep, err := clickhousetest.NewReplayer("testdata/events.json")
+//	defer rep.Close()
+//	conn, err := clickhouse.Open(rep.Apply(&clickhouse.Options{}))
+type Replayer struct {
+	native *server.Server
+
+	mu        sync.Mutex
+	recording *recording
+}
+
+// NewReplayer returns a replayer of the recording at path.
+func NewReplayer(path string) (*Replayer, error) {
+	recording, err := readRecording(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Replayer{
+		recording: recording,
+		native:    &server.Server{Timezone: time.UTC},
+	}
+	r.native.Handler = &replayHandler{replayer: r}
+	if s := recording.Server; s != nil {
+		r.native.Name, r.native.DisplayName = s.Name, s.DisplayName
+		r.native.Version, r.native.Revision = s.Version, s.Revision
+		if r.native.Timezone, err = time.LoadLocation(s.Timezone); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Apply makes opt connect to the replayer: DialContext is set for the native protocol and
+// TransportFunc for HTTP. It returns opt.
+func (r *Replayer) Apply(opt *clickhouse.Options) *clickhouse.Options {
+	switch opt.Protocol {
+	case clickhouse.HTTP:
+		opt.TransportFunc = r.Transport
+	default:
+		opt.DialContext = r.DialContext
+	}
+	return opt
+}
+
+// DialContext returns a native protocol connection served by the replayer, addr is ignored.
+func (r *Replayer) DialContext(_ context.Context, _ string) (net.Conn, error) {
+	client, conn := net.Pipe()
+	go func() {
+		_ = r.native.ServeConn(conn)
+	}()
+	return client, nil
+}
+
+// Transport returns a round tripper answering HTTP requests with the replayer, t is not used.
+func (r *Replayer) Transport(_ *http.Transport) (http.RoundTripper, error) {
+	return replayTransport{replayer: r}, nil
+}
+
+// Close closes the native protocol connections.
+func (r *Replayer) Close() error {
+	return r.native.Close()
+}
+
+func (r *Replayer) find(protocol string, call Call) *exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.recording.find(protocol, call)
+}
+
+// replayHandler answers native protocol queries with the recorded packets
+type replayHandler struct {
+	replayer *Replayer
+}
+
+func (h *replayHandler) Handshake(context.Context, *server.Hello) error {
+	return nil
+}
+
+func (h *replayHandler) Query(_ context.Context, conn *server.Conn, q *server.Query) error {
+	e := h.replayer.find(protocolNative, nativeCall(&q.Query))
+	if e == nil {
+		return unexpectedQuery(q.Body)
+	}
+	for _, packet := range e.Packets {
+		reader := chproto.NewReader(bytes.NewReader(packet.Data))
+		switch packet.Code {
+		case proto.ServerData, proto.ServerTotals, proto.ServerExtremes, proto.ServerLog, proto.ServerProfileEvents:
+			block := &proto.Block{Timezone: h.replayer.native.Timezone}
+			if err := block.Decode(reader, e.Revision); err != nil {
+				return err
+			}
+			if err := conn.WriteBlock(packet.Code, block); err != nil {
+				return err
+			}
+			if packet.Code == proto.ServerData && q.HasData {
+				// the header of an INSERT, the data is read and dropped
+				if err := discardData(conn); err != nil {
+					return err
+				}
+			}
+		case proto.ServerProgress:
+			var progress proto.Progress
+			if err := progress.Decode(reader, e.Revision); err != nil {
+				return err
+			}
+			if err := conn.WriteProgress(&progress); err != nil {
+				return err
+			}
+		case proto.ServerProfileInfo:
+			var info proto.ProfileInfo
+			if err := info.Decode(reader, e.Revision); err != nil {
+				return err
+			}
+			if err := conn.WriteProfileInfo(&info); err != nil {
+				return err
+			}
+		case proto.ServerTableColumns:
+			var columns proto.TableColumns
+			if err := columns.Decode(reader, e.Revision); err != nil {
+				return err
+			}
+			if err := conn.WriteTableColumns(columns.First, columns.Second); err != nil {
+				return err
+			}
+		case proto.ServerTimezoneUpdate:
+			name, err := reader.Str()
+			if err != nil {
+				return err
+			}
+			if err := conn.WriteTimezoneUpdate(name); err != nil {
+				return err
+			}
+		case proto.ServerPartUUIDs:
+			var uuids proto.PartUUIDs
+			if err := uuids.Decode(reader); err != nil {
+				return err
+			}
+			if err := conn.WritePartUUIDs(uuids); err != nil {
+				return err
+			}
+		case proto.ServerException:
+			exception := &proto.Exception{}
+			if err := exception.Decode(reader); err != nil {
+				return err
+			}
+			return exception
+		case proto.ServerEndOfStream:
+			return nil
+		default:
+			return fmt.Errorf("clickhousetest: unexpected recorded packet %d", packet.Code)
+		}
+	}
+	return nil
+}
+
+func discardData(conn *server.Conn) error {
+	for {
+		if _, err := conn.ReadData(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// replayTransport answers HTTP requests with the recorded responses
+type replayTransport struct {
+	replayer *Replayer
+}
+
+func (t replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+	call, body, err := httpCall(req)
+	if err != nil {
+		return nil, err
+	}
+	// the data of an INSERT is read as by a server
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		return nil, err
+	}
+	e := t.replayer.find(protocolHTTP, call)
+	if e == nil {
+		w := httptest.NewRecorder()
+		writeHTTPException(w, unexpectedQuery(call.Query), req.URL.Query().Get("compress") == "1")
+		resp := w.Result()
+		resp.Request = req
+		return resp, nil
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header(e.Header).Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,104 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhousetest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordReplay(t *testing.T) {
+	testProtocols(t, func(t *testing.T, protocol clickhouse.Protocol, compression clickhouse.CompressionMethod) {
+		path := filepath.Join(t.TempDir(), "recording.json")
+		ctx := clickhouse.Context(context.Background(),
+			clickhouse.WithParameters(clickhouse.Parameters{"min": "0"}),
+			clickhouse.WithSettings(clickhouse.Settings{"max_threads": 2}),
+		)
+		run := func(conn driver.Conn) {
+			version, err := conn.ServerVersion()
+			require.NoError(t, err)
+			assert.Equal(t, uint64(24), version.Version.Major)
+
+			var names []string
+			for _, min := range []string{"0", "1"} {
+				rows, err := conn.Query(clickhouse.Context(ctx, clickhouse.WithParameters(clickhouse.Parameters{"min": min})),
+					"SELECT name FROM users WHERE id > {min:UInt64}")
+				require.NoError(t, err)
+				for rows.Next() {
+					var name string
+					require.NoError(t, rows.Scan(&name))
+					names = append(names, name)
+				}
+				require.NoError(t, rows.Close())
+			}
+			assert.Equal(t, []string{"alice", "bob", "bob"}, names)
+
+			batch, err := conn.PrepareBatch(ctx, "INSERT INTO users")
+			require.NoError(t, err)
+			require.NoError(t, batch.Append(uint64(3), "carol"))
+			require.NoError(t, batch.Send())
+
+			assert.Error(t, conn.Exec(ctx, "DROP TABLE missing"))
+		}
+
+		srv := NewServer()
+		srv.Table("users", Column{Name: "id", Type: "UInt64"}, Column{Name: "name", Type: "String"})
+		users := NewResult(Column{Name: "name", Type: "String"})
+		srv.Expect(`^SELECT name FROM users`).WillReturn(users.AddRow("alice").AddRow("bob")).Times(1)
+		srv.Expect(`^SELECT name FROM users`).WillReturn(NewResult(Column{Name: "name", Type: "String"}).AddRow("bob"))
+		srv.Expect(`^INSERT INTO users`)
+		srv.Expect(`^DROP TABLE`).WillFail(CodeUnknownTable, "Table default.missing does not exist")
+
+		recorder := NewRecorder(path)
+		opt := srv.Options(protocol)
+		opt.Compression = &clickhouse.Compression{Method: compression}
+		// compressed connections are recorded with chunked packets
+		opt.ChunkedPackets = compression != clickhouse.CompressionNone
+		conn, err := clickhouse.Open(recorder.Apply(opt))
+		require.NoError(t, err)
+		run(conn)
+		require.NoError(t, conn.Close())
+		require.NoError(t, recorder.Close())
+		srv.Close()
+		require.NoError(t, srv.ExpectationsWereMet())
+
+		replayer, err := NewReplayer(path)
+		require.NoError(t, err)
+		defer replayer.Close()
+		opt = &clickhouse.Options{
+			Protocol:    protocol,
+			Compression: &clickhouse.Compression{Method: compression},
+		}
+		conn, err = clickhouse.Open(replayer.Apply(opt))
+		require.NoError(t, err)
+		defer conn.Close()
+		run(conn)
+
+		err = conn.Exec(ctx, "SELECT 42")
+		require.Error(t, err)
+		if protocol == clickhouse.Native {
+			assert.ErrorContains(t, err, `unexpected query "SELECT 42"`)
+		}
+	})
+}
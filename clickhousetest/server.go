@@ -0,0 +1,350 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package clickhousetest provides an in-process fake ClickHouse server for tests. The server speaks
+// the native protocol and the HTTP interface, answers scripted queries with canned blocks and records
+// the blocks inserted by clients, so that code using the driver can be tested without a real server.
+//
+//	srv := clickhousetest.NewServer()
+//	defer srv.Close()
+//	srv.Expect(`SELECT count\(\) FROM events`).WillReturn(
+//		clickhousetest.NewResult(clickhousetest.Column{Name: "count()", Type: "UInt64"}).AddRow(uint64(42)),
+//	)
+//	conn, err := clickhouse.Open(srv.Options(clickhouse.Native))
+package clickhousetest
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/server"
+)
+
+// Exception codes returned by the server, see ClickHouse src/Common/ErrorCodes.cpp.
+const (
+	CodeUnexpectedQuery      int32 = 0
+	CodeUnknownTable         int32 = 60
+	CodeAuthenticationFailed int32 = 516
+)
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithTimezone sets the server timezone sent in the handshake, UTC by default.
+func WithTimezone(loc *time.Location) Option {
+	return func(s *Server) {
+		s.timezone = loc
+	}
+}
+
+// WithVersion sets the server version sent in the handshake.
+func WithVersion(version proto.Version) Option {
+	return func(s *Server) {
+		s.version = version
+	}
+}
+
+// WithCredentials makes the server reject clients that do not authenticate as username with password.
+func WithCredentials(username, password string) Option {
+	return func(s *Server) {
+		s.username, s.password, s.checkAuth = username, password, true
+	}
+}
+
+// Server is an in-process fake ClickHouse server. Queries are matched against the expectations
+// registered with Expect, queries without a matching expectation fail with CodeUnexpectedQuery.
+type Server struct {
+	timezone  *time.Location
+	version   proto.Version
+	username  string
+	password  string
+	checkAuth bool
+
+	listener net.Listener
+	native   *server.Server
+	http     *httptest.Server
+
+	mu           sync.Mutex
+	tables       map[string][]Column
+	expectations []*Expectation
+	unexpected   []string
+}
+
+// NewServer starts a server listening on the loopback interface, it must be stopped with Close.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		timezone: time.UTC,
+		version:  proto.Version{Major: 24, Minor: 8, Patch: 1},
+		tables:   make(map[string][]Column),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("clickhousetest: failed to listen: %v", err))
+	}
+	s.listener = listener
+	s.native = &server.Server{
+		Handler:     &nativeHandler{server: s},
+		DisplayName: "clickhousetest",
+		Version:     s.version,
+		Timezone:    s.timezone,
+	}
+	s.http = httptest.NewServer(s.httpHandler())
+	go func() {
+		_ = s.native.Serve(listener)
+	}()
+	return s
+}
+
+// Addr returns the address of the native protocol listener.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// HTTPAddr returns the address of the HTTP listener.
+func (s *Server) HTTPAddr() string {
+	return s.http.Listener.Addr().String()
+}
+
+// Options returns client options connecting to the server over protocol.
+func (s *Server) Options(protocol clickhouse.Protocol) *clickhouse.Options {
+	addr := s.Addr()
+	if protocol == clickhouse.HTTP {
+		addr = s.HTTPAddr()
+	}
+	return &clickhouse.Options{
+		Protocol: protocol,
+		Addr:     []string{addr},
+		Auth: clickhouse.Auth{
+			Username: s.username,
+			Password: s.password,
+		},
+	}
+}
+
+// Close stops the server and closes client connections.
+func (s *Server) Close() {
+	_ = s.native.Close()
+	_ = s.listener.Close()
+	s.http.CloseClientConnections()
+	s.http.Close()
+}
+
+// Table registers the columns of table. They are sent as the header of INSERT queries into the
+// table and returned by DESCRIBE TABLE queries.
+func (s *Server) Table(name string, columns ...Column) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tables[tableKey(name)] = columns
+}
+
+// Expect registers an expectation for queries matching the regular expression pattern.
+// Queries are matched against the expectations in registration order, skipping expectations
+// that have been called as often as expected.
+func (s *Server) Expect(pattern string) *Expectation {
+	e := &Expectation{
+		pattern: regexp.MustCompile(pattern),
+		times:   1,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expectations = append(s.expectations, e)
+	return e
+}
+
+// ExpectationsWereMet returns an error listing the expectations that were not called as often as
+// expected and the queries that matched no expectation.
+func (s *Server) ExpectationsWereMet() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for _, e := range s.expectations {
+		if e.times > 0 && len(e.calls) < e.times {
+			errs = append(errs, fmt.Errorf("expected query matching %q %d time(s), got %d", e.pattern, e.times, len(e.calls)))
+		}
+	}
+	for _, query := range s.unexpected {
+		errs = append(errs, fmt.Errorf("unexpected query %q", query))
+	}
+	return errors.Join(errs...)
+}
+
+// match returns the first expectation for query that has not been exhausted
+func (s *Server) match(query string) *Expectation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.expectations {
+		if (e.times <= 0 || len(e.calls) < e.times) && e.pattern.MatchString(query) {
+			return e
+		}
+	}
+	return nil
+}
+
+// record adds call to the calls of e, or to the unexpected queries if e is nil
+func (s *Server) record(e *Expectation, call Call) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e == nil {
+		s.unexpected = append(s.unexpected, call.Query)
+		return
+	}
+	e.calls = append(e.calls, call)
+}
+
+func (s *Server) table(name string) ([]Column, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	columns, found := s.tables[tableKey(name)]
+	return columns, found
+}
+
+func (s *Server) authenticate(username, password string) error {
+	if !s.checkAuth || (username == s.username && password == s.password) {
+		return nil
+	}
+	return &proto.Exception{
+		Code:    CodeAuthenticationFailed,
+		Name:    "DB::Exception",
+		Message: fmt.Sprintf("%s: Authentication failed: password is incorrect, or there is no user with such name.", username),
+	}
+}
+
+var (
+	insertDataMatch = regexp.MustCompile(`(?is)^\s*INSERT\s+INTO\s+([^\s(]+)\s*(?:\(([^()]*(?:\([^()]*\)[^()]*)*)\))?\s*(?:VALUES|FORMAT\s+(?:Native|RowBinary|RowBinaryWithNamesAndTypes))?\s*$`)
+	describeMatch   = regexp.MustCompile(`(?is)^\s*(?:DESCRIBE|DESC)(?:\s+TABLE)?\s+(\S+)\s*$`)
+	helloQuery      = "SELECT displayName(), version(), revision(), timezone()"
+)
+
+// insertData returns the table and columns of INSERT queries that send their data in blocks
+func insertData(query string) (table string, columns []string, ok bool) {
+	matches := insertDataMatch.FindStringSubmatch(query)
+	if matches == nil {
+		return "", nil, false
+	}
+	if len(strings.TrimSpace(matches[2])) != 0 {
+		for _, name := range strings.Split(matches[2], ",") {
+			columns = append(columns, strings.Trim(strings.TrimSpace(name), "`\""))
+		}
+	}
+	return matches[1], columns, true
+}
+
+// insertHeader returns the header block of an INSERT into table with columns
+func (s *Server) insertHeader(table string, columns []string) (*proto.Block, error) {
+	described, found := s.table(table)
+	if !found {
+		return nil, unknownTable(table)
+	}
+	if len(columns) == 0 {
+		return NewResult(described...).block(s.timezone)
+	}
+	selected := make([]Column, 0, len(columns))
+	for _, name := range columns {
+		idx := -1
+		for i, c := range described {
+			if c.Name == name {
+				idx = i
+			}
+		}
+		if idx == -1 {
+			return nil, &proto.Exception{
+				Code:    16, // NO_SUCH_COLUMN_IN_TABLE
+				Name:    "DB::Exception",
+				Message: fmt.Sprintf("No such column %s in table %s", name, table),
+			}
+		}
+		selected = append(selected, described[idx])
+	}
+	return NewResult(selected...).block(s.timezone)
+}
+
+// builtin answers the queries the driver sends on its own, pings and table descriptions, with
+// their response blocks. It returns no blocks and no error for other queries.
+func (s *Server) builtin(query string) ([]*proto.Block, error) {
+	var result *Result
+	switch query = strings.TrimSpace(query); {
+	case query == "SELECT 1":
+		result = NewResult(Column{Name: "1", Type: "UInt8"}).AddRow(uint8(1))
+	case describeMatch.MatchString(query):
+		table := describeMatch.FindStringSubmatch(query)[1]
+		columns, found := s.table(table)
+		if !found {
+			return nil, unknownTable(table)
+		}
+		result = NewResult(
+			Column{Name: "name", Type: "String"},
+			Column{Name: "type", Type: "String"},
+			Column{Name: "default_type", Type: "String"},
+			Column{Name: "default_expression", Type: "String"},
+			Column{Name: "comment", Type: "String"},
+			Column{Name: "codec_expression", Type: "String"},
+			Column{Name: "ttl_expression", Type: "String"},
+		)
+		for _, c := range columns {
+			result.AddRow(c.Name, c.Type, c.DefaultKind, c.DefaultExpression, c.Comment, c.CodecExpression, c.TTLExpression)
+		}
+	case query == helloQuery:
+		result = s.hello()
+	default:
+		return nil, nil
+	}
+	block, err := result.block(s.timezone)
+	if err != nil {
+		return nil, err
+	}
+	return withHeader(block)
+}
+
+func (s *Server) hello() *Result {
+	return NewResult(
+		Column{Name: "displayName()", Type: "String"},
+		Column{Name: "version()", Type: "String"},
+		Column{Name: "revision()", Type: "UInt32"},
+		Column{Name: "timezone()", Type: "String"},
+	).AddRow("clickhousetest", s.version.String(), uint32(proto.DBMS_TCP_PROTOCOL_VERSION), s.timezone.String())
+}
+
+func unexpectedQuery(query string) *proto.Exception {
+	return &proto.Exception{
+		Code:    CodeUnexpectedQuery,
+		Name:    "DB::Exception",
+		Message: fmt.Sprintf("clickhousetest: unexpected query %q", query),
+	}
+}
+
+func unknownTable(table string) *proto.Exception {
+	return &proto.Exception{
+		Code:    CodeUnknownTable,
+		Name:    "DB::Exception",
+		Message: fmt.Sprintf("Table %s does not exist", table),
+	}
+}
+
+func tableKey(name string) string {
+	return strings.NewReplacer("`", "", `"`, "").Replace(name)
+}
@@ -0,0 +1,396 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhousetest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testProtocols(t *testing.T, test func(t *testing.T, protocol clickhouse.Protocol, compression clickhouse.CompressionMethod)) {
+	for name, protocol := range map[string]clickhouse.Protocol{"Native": clickhouse.Native, "HTTP": clickhouse.HTTP} {
+		for _, compression := range []clickhouse.CompressionMethod{clickhouse.CompressionNone, clickhouse.CompressionLZ4} {
+			t.Run(name+"/"+compression.String(), func(t *testing.T) {
+				test(t, protocol, compression)
+			})
+		}
+	}
+}
+
+func open(t *testing.T, srv *Server, protocol clickhouse.Protocol, compression clickhouse.CompressionMethod) driver.Conn {
+	opt := srv.Options(protocol)
+	opt.Compression = &clickhouse.Compression{Method: compression}
+	conn, err := clickhouse.Open(opt)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func TestServerQuery(t *testing.T) {
+	testProtocols(t, func(t *testing.T, protocol clickhouse.Protocol, compression clickhouse.CompressionMethod) {
+		srv := NewServer()
+		defer srv.Close()
+		e := srv.Expect(`^SELECT id, name, ts FROM users WHERE id > \{min:UInt64\}$`).WillReturn(
+			NewResult(
+				Column{Name: "id", Type: "UInt64"},
+				Column{Name: "name", Type: "String"},
+				Column{Name: "ts", Type: "DateTime"},
+			).
+				AddRow(uint64(1), "alice", time.Unix(1700000000, 0)).
+				AddRow(uint64(2), "bob", time.Unix(1700000001, 0)),
+		)
+		conn := open(t, srv, protocol, compression)
+		ctx := clickhouse.Context(context.Background(),
+			clickhouse.WithParameters(clickhouse.Parameters{"min": "0"}),
+			clickhouse.WithSettings(clickhouse.Settings{"max_threads": 2}),
+		)
+
+		var users []struct {
+			ID   uint64    `ch:"id"`
+			Name string    `ch:"name"`
+			TS   time.Time `ch:"ts"`
+		}
+		require.NoError(t, conn.Select(ctx, &users, "SELECT id, name, ts FROM users WHERE id > {min:UInt64}"))
+		require.Len(t, users, 2)
+		assert.Equal(t, "bob", users[1].Name)
+		assert.Equal(t, int64(1700000001), users[1].TS.Unix())
+
+		require.Len(t, e.Calls(), 1)
+		assert.Equal(t, "0", e.Calls()[0].Parameters["min"])
+		assert.Equal(t, "2", e.Calls()[0].Settings["max_threads"])
+		assert.NoError(t, srv.ExpectationsWereMet())
+	})
+}
+
+func TestServerExec(t *testing.T) {
+	testProtocols(t, func(t *testing.T, protocol clickhouse.Protocol, compression clickhouse.CompressionMethod) {
+		srv := NewServer()
+		defer srv.Close()
+		srv.Expect(`^CREATE TABLE`).Times(2)
+		srv.Expect(`^DROP TABLE`).WillFail(60, "Table default.missing does not exist")
+		conn := open(t, srv, protocol, compression)
+		ctx := context.Background()
+
+		require.NoError(t, conn.Exec(ctx, "CREATE TABLE a (id UInt64) ENGINE = Memory"))
+		require.NoError(t, conn.Exec(ctx, "CREATE TABLE b (id UInt64) ENGINE = Memory"))
+		err := conn.Exec(ctx, "DROP TABLE missing")
+		require.Error(t, err)
+		// Exec over HTTP does not request compressed responses, but reads errors as compressed
+		if protocol == clickhouse.Native || compression == clickhouse.CompressionNone {
+			assert.Contains(t, err.Error(), "Table default.missing does not exist")
+		}
+		if protocol == clickhouse.Native {
+			var exception *proto.Exception
+			require.ErrorAs(t, err, &exception)
+			assert.Equal(t, int32(60), exception.Code)
+		}
+		assert.NoError(t, srv.ExpectationsWereMet())
+
+		require.Error(t, conn.Exec(ctx, "TRUNCATE TABLE a"))
+		require.NoError(t, conn.Ping(ctx))
+		assert.ErrorContains(t, srv.ExpectationsWereMet(), `unexpected query "TRUNCATE TABLE a"`)
+	})
+}
+
+func TestServerInsert(t *testing.T) {
+	testProtocols(t, func(t *testing.T, protocol clickhouse.Protocol, compression clickhouse.CompressionMethod) {
+		srv := NewServer()
+		defer srv.Close()
+		srv.Table("events",
+			Column{Name: "id", Type: "UInt64"},
+			Column{Name: "name", Type: "String"},
+			Column{Name: "tags", Type: "Array(String)"},
+		)
+		e := srv.Expect(`^INSERT INTO events`).Times(2)
+		conn := open(t, srv, protocol, compression)
+		ctx := context.Background()
+
+		batch, err := conn.PrepareBatch(ctx, "INSERT INTO events")
+		require.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			require.NoError(t, batch.Append(uint64(i), "event", []string{"a", "b"}))
+		}
+		require.NoError(t, batch.Send())
+
+		batch, err = conn.PrepareBatch(ctx, "INSERT INTO events (name, id)")
+		require.NoError(t, err)
+		require.NoError(t, batch.Append("other", uint64(10)))
+		require.NoError(t, batch.Send())
+
+		assert.Equal(t, 11, e.InsertedRows())
+		inserted := e.Inserted()
+		require.Len(t, inserted, 2)
+		assert.Equal(t, []string{"id", "name", "tags"}, inserted[0].ColumnsNames())
+		assert.Equal(t, []string{"name", "id"}, inserted[1].ColumnsNames())
+		assert.Equal(t, uint64(10), inserted[1].Columns[1].Row(0, false))
+
+		columns, err := conn.(driver.SchemaConn).DescribeTable(ctx, "", "events")
+		require.NoError(t, err)
+		assert.Len(t, columns, 3)
+
+		_, err = conn.PrepareBatch(ctx, "INSERT INTO missing")
+		assert.Error(t, err)
+		assert.NoError(t, srv.ExpectationsWereMet())
+	})
+}
+
+// insertedIDs returns the id column of the blocks inserted by the calls of e
+func insertedIDs(e *Expectation) []uint64 {
+	var ids []uint64
+	for _, block := range e.Inserted() {
+		for i := 0; i < block.Rows(); i++ {
+			ids = append(ids, block.Columns[0].Row(i, false).(uint64))
+		}
+	}
+	return ids
+}
+
+func TestServerInsertReplayBuffer(t *testing.T) {
+	testProtocols(t, func(t *testing.T, protocol clickhouse.Protocol, compression clickhouse.CompressionMethod) {
+		srv := NewServer()
+		defer srv.Close()
+		srv.Table("events", Column{Name: "id", Type: "UInt64"})
+		failed := srv.Expect(`^INSERT INTO events`).WillFailInsert(252, "Too many parts")
+		committed := srv.Expect(`^INSERT INTO events`).AnyTimes()
+		conn := open(t, srv, protocol, compression)
+		ctx := context.Background()
+
+		batch, err := conn.PrepareBatch(ctx, "INSERT INTO events", driver.WithReplayBuffer())
+		require.NoError(t, err)
+		require.NoError(t, batch.Append(uint64(0)))
+		require.NoError(t, batch.Append(uint64(1)))
+		require.NoError(t, batch.Flush())
+		require.NoError(t, batch.Append(uint64(2)))
+		require.ErrorContains(t, batch.Send(), "Too many parts")
+		require.NoError(t, batch.Send())
+		require.ErrorIs(t, batch.Send(), clickhouse.ErrBatchAlreadySent)
+
+		assert.Equal(t, []uint64{0, 1, 2}, insertedIDs(committed))
+		require.Len(t, failed.Calls(), 1)
+		require.Len(t, committed.Calls(), 1)
+		token := failed.Calls()[0].Settings["insert_deduplication_token"]
+		assert.NotEmpty(t, token)
+		assert.Equal(t, token, committed.Calls()[0].Settings["insert_deduplication_token"])
+		assert.NoError(t, srv.ExpectationsWereMet())
+	})
+}
+
+func TestServerInsertReplayBufferCloseOnFlush(t *testing.T) {
+	testProtocols(t, func(t *testing.T, protocol clickhouse.Protocol, compression clickhouse.CompressionMethod) {
+		srv := NewServer()
+		defer srv.Close()
+		srv.Table("events", Column{Name: "id", Type: "UInt64"})
+		first := srv.Expect(`^INSERT INTO events`)
+		failed := srv.Expect(`^INSERT INTO events`).WillFailInsert(252, "Too many parts")
+		committed := srv.Expect(`^INSERT INTO events`).AnyTimes()
+		conn := open(t, srv, protocol, compression)
+		ctx := context.Background()
+
+		batch, err := conn.PrepareBatch(ctx, "INSERT INTO events", driver.WithReplayBuffer(), driver.WithCloseOnFlush())
+		require.NoError(t, err)
+		require.NoError(t, batch.Append(uint64(0)))
+		require.NoError(t, batch.Flush())
+		require.NoError(t, batch.Append(uint64(1)))
+		if protocol == clickhouse.Native {
+			// each Flush commits an INSERT, the second one fails
+			require.ErrorContains(t, batch.Flush(), "Too many parts")
+			require.NoError(t, batch.Append(uint64(2)))
+			require.NoError(t, batch.Send())
+
+			assert.Equal(t, []uint64{0}, insertedIDs(first))
+			assert.Equal(t, []uint64{1}, insertedIDs(failed))
+			assert.Equal(t, []uint64{1, 2}, insertedIDs(committed))
+		} else {
+			// Flush is a no-op over HTTP, Send inserts all rows
+			require.NoError(t, batch.Flush())
+			require.NoError(t, batch.Append(uint64(2)))
+			require.NoError(t, batch.Send())
+
+			assert.Equal(t, []uint64{0, 1, 2}, insertedIDs(first))
+		}
+	})
+}
+
+// appenderEvent maps the columns of events without a DEFAULT, like a struct generated by lib/structgen
+type appenderEvent struct {
+	ID   uint64 `ch:"id"`
+	Name string `ch:"name"`
+}
+
+func (e *appenderEvent) AppendTo(columns []column.Interface) error {
+	if len(columns) != 2 {
+		return fmt.Errorf("expected 2 columns, got %d", len(columns))
+	}
+	if err := columns[0].AppendRow(e.ID); err != nil {
+		return err
+	}
+	return columns[1].AppendRow(e.Name)
+}
+
+func TestServerInsertStructAppenderOmitDefaults(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.Table("events",
+		Column{Name: "id", Type: "UInt64"},
+		Column{Name: "name", Type: "String"},
+		Column{Name: "ts", Type: "DateTime", DefaultKind: "DEFAULT", DefaultExpression: "now()"},
+	)
+	insert := srv.Expect(`^INSERT INTO events`).AnyTimes()
+	conn := open(t, srv, clickhouse.HTTP, clickhouse.CompressionNone)
+
+	ctx := context.Background()
+	batch, err := conn.PrepareBatch(ctx, "INSERT INTO events", driver.WithOmitDefaults())
+	require.NoError(t, err)
+	require.NoError(t, batch.AppendStruct(&appenderEvent{ID: 1, Name: "a"}))
+	require.NoError(t, batch.Send())
+
+	inserted := insert.Inserted()
+	require.NotEmpty(t, inserted)
+	assert.Equal(t, []string{"id", "name"}, inserted[len(inserted)-1].ColumnsNames())
+	assert.Equal(t, 1, insert.InsertedRows())
+}
+
+func TestServerAuthentication(t *testing.T) {
+	testProtocols(t, func(t *testing.T, protocol clickhouse.Protocol, compression clickhouse.CompressionMethod) {
+		srv := NewServer(WithCredentials("default", "secret"))
+		defer srv.Close()
+		require.NoError(t, open(t, srv, protocol, compression).Ping(context.Background()))
+
+		opt := srv.Options(protocol)
+		opt.Auth.Password = "wrong"
+		conn, err := clickhouse.Open(opt)
+		if err == nil {
+			err = conn.Ping(context.Background())
+			_ = conn.Close()
+		}
+		assert.ErrorContains(t, err, "Authentication failed")
+	})
+}
+
+func TestServerHandshake(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	srv := NewServer(WithTimezone(loc), WithVersion(proto.Version{Major: 25, Minor: 3, Patch: 2}))
+	defer srv.Close()
+	for _, protocol := range []clickhouse.Protocol{clickhouse.Native, clickhouse.HTTP} {
+		version, err := open(t, srv, protocol, clickhouse.CompressionNone).ServerVersion()
+		require.NoError(t, err)
+		assert.Equal(t, "25.3.2", version.Version.String())
+		assert.Equal(t, "Europe/Berlin", version.Timezone.String())
+	}
+}
+
+func TestServerHTTPFormats(t *testing.T) {
+	formats := []clickhouse.HTTPFormat{
+		clickhouse.HTTPFormatNative,
+		clickhouse.HTTPFormatRowBinary,
+		clickhouse.HTTPFormatRowBinaryWithNamesAndTypes,
+	}
+	for _, compression := range []clickhouse.CompressionMethod{clickhouse.CompressionNone, clickhouse.CompressionLZ4} {
+		for _, format := range formats {
+			t.Run(compression.String()+"/"+string(format), func(t *testing.T) {
+				srv := NewServer()
+				defer srv.Close()
+				srv.Table("events",
+					Column{Name: "id", Type: "UInt64"},
+					Column{Name: "name", Type: "Nullable(String)"},
+					Column{Name: "tags", Type: "Array(LowCardinality(String))"},
+				)
+				insert := srv.Expect(`^INSERT INTO events`).Times(2)
+				query := srv.Expect(`^SELECT`).Times(2).WillReturn(
+					NewResult(
+						Column{Name: "id", Type: "UInt64"},
+						Column{Name: "name", Type: "Nullable(String)"},
+						Column{Name: "ts", Type: "DateTime"},
+					).
+						AddRow(uint64(1), "alice", time.Unix(1700000000, 0)).
+						AddRow(uint64(2), nil, time.Unix(1700000001, 0)),
+				)
+				opt := srv.Options(clickhouse.HTTP)
+				opt.Compression = &clickhouse.Compression{Method: compression}
+				opt.HttpFormat = format
+				conn, err := clickhouse.Open(opt)
+				require.NoError(t, err)
+				defer conn.Close()
+
+				type event struct {
+					ID   uint64    `ch:"id"`
+					Name *string   `ch:"name"`
+					TS   time.Time `ch:"ts"`
+				}
+				for _, ctx := range []context.Context{
+					context.Background(),
+					clickhouse.Context(context.Background(), clickhouse.WithHTTPFormat(formats[(slices.Index(formats, format)+1)%len(formats)])),
+				} {
+					var events []event
+					require.NoError(t, conn.Select(ctx, &events, "SELECT id, name, ts FROM events"))
+					require.Len(t, events, 2)
+					assert.Equal(t, "alice", *events[0].Name)
+					assert.Nil(t, events[1].Name)
+					assert.Equal(t, int64(1700000001), events[1].TS.Unix())
+
+					batch, err := conn.PrepareBatch(ctx, "INSERT INTO events (id, name, tags)")
+					require.NoError(t, err)
+					for i := 0; i < 100; i++ {
+						require.NoError(t, batch.Append(uint64(i), nil, []string{"a", "b"}))
+					}
+					require.NoError(t, batch.Send())
+				}
+
+				require.Len(t, query.Calls(), 2)
+				assert.Equal(t, 200, insert.InsertedRows())
+				inserted := insert.Inserted()
+				require.Len(t, inserted, 2)
+				assert.Equal(t, []string{"id", "name", "tags"}, inserted[1].ColumnsNames())
+				assert.Equal(t, uint64(99), inserted[1].Columns[0].Row(99, false))
+				assert.Equal(t, []string{"a", "b"}, inserted[1].Columns[2].Row(99, false))
+				assert.NoError(t, srv.ExpectationsWereMet())
+			})
+		}
+	}
+
+	srv := NewServer()
+	defer srv.Close()
+	opt := srv.Options(clickhouse.HTTP)
+	opt.HttpFormat = "CSV"
+	conn, err := clickhouse.Open(opt)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.ErrorContains(t, conn.Ping(context.Background()), `unknown HTTP format "CSV"`)
+
+	conn, err = clickhouse.Open(srv.Options(clickhouse.HTTP))
+	require.NoError(t, err)
+	defer conn.Close()
+	ctx := clickhouse.Context(context.Background(), clickhouse.WithHTTPFormat("CSV"))
+	_, err = conn.Query(ctx, "SELECT 1")
+	assert.ErrorContains(t, err, `unknown HTTP format "CSV"`)
+	_, err = conn.PrepareBatch(ctx, "INSERT INTO events")
+	assert.ErrorContains(t, err, `unknown HTTP format "CSV"`)
+}
@@ -0,0 +1,209 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type columnSchema struct {
+	name   string
+	chType string
+}
+
+type tableSchema struct {
+	name    string
+	columns []columnSchema
+}
+
+var (
+	createTableRe  = regexp.MustCompile("(?is)\\bCREATE\\s+(?:OR\\s+REPLACE\\s+)?(?:TEMPORARY\\s+)?TABLE\\s+(?:IF\\s+NOT\\s+EXISTS\\s+)?([`\"\\w.]+)(?:\\s+ON\\s+CLUSTER\\s+\\S+)?\\s*\\(")
+	lineCommentRe  = regexp.MustCompile(`--[^\n]*`)
+	skipElementRe  = regexp.MustCompile(`(?i)^(INDEX|PROJECTION|CONSTRAINT|PRIMARY\s+KEY)\b`)
+	skipColumnKind = map[string]bool{"MATERIALIZED": true, "ALIAS": true, "EPHEMERAL": true}
+	// column declaration keywords that end the type expression
+	columnKeywords = map[string]bool{
+		"DEFAULT": true, "MATERIALIZED": true, "ALIAS": true, "EPHEMERAL": true,
+		"CODEC": true, "COMMENT": true, "TTL": true, "NULL": true, "NOT": true,
+		"PRIMARY": true, "SETTINGS": true, "STATISTICS": true,
+	}
+)
+
+// parseDDL extracts the columns of every CREATE TABLE statement in ddl
+func parseDDL(ddl string) ([]tableSchema, error) {
+	ddl = lineCommentRe.ReplaceAllString(ddl, "")
+	var schemas []tableSchema
+	for _, m := range createTableRe.FindAllStringSubmatchIndex(ddl, -1) {
+		name := unquoteIdent(ddl[m[2]:m[3]])
+		body, err := enclosed(ddl[m[1]:])
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", name, err)
+		}
+		schema := tableSchema{name: name}
+		for _, element := range splitTopLevel(body) {
+			if element = strings.TrimSpace(element); len(element) == 0 || skipElementRe.MatchString(element) {
+				continue
+			}
+			c, skip, err := parseColumn(element)
+			if err != nil {
+				return nil, fmt.Errorf("table %s: %w", name, err)
+			}
+			if !skip {
+				schema.columns = append(schema.columns, c)
+			}
+		}
+		schemas = append(schemas, schema)
+	}
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("no CREATE TABLE statements found")
+	}
+	return schemas, nil
+}
+
+// parseColumn parses a column declaration, skip is true for columns not stored by INSERT
+func parseColumn(element string) (c columnSchema, skip bool, err error) {
+	var rest string
+	switch element[0] {
+	case '`', '"':
+		end := strings.IndexByte(element[1:], element[0])
+		if end == -1 {
+			return c, false, fmt.Errorf("unterminated identifier in %q", element)
+		}
+		c.name, rest = element[1:end+1], element[end+2:]
+	default:
+		end := strings.IndexFunc(element, unicode.IsSpace)
+		if end == -1 {
+			return c, false, fmt.Errorf("column %s has no type", element)
+		}
+		c.name, rest = element[:end], element[end:]
+	}
+
+	var (
+		depth  int
+		quoted bool
+		end    = len(rest)
+	)
+scan:
+	for i := 0; i < len(rest); i++ {
+		switch ch := rest[i]; {
+		case quoted:
+			if ch == '\\' {
+				i++
+			} else if ch == '\'' {
+				quoted = false
+			}
+		case ch == '\'':
+			quoted = true
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case depth == 0 && isIdentStart(ch) && (i == 0 || !isIdentChar(rest[i-1])):
+			j := i
+			for j < len(rest) && isIdentChar(rest[j]) {
+				j++
+			}
+			if keyword := strings.ToUpper(rest[i:j]); columnKeywords[keyword] {
+				end = i
+				break scan
+			}
+			i = j - 1
+		}
+	}
+	c.chType = strings.TrimSpace(rest[:end])
+	modifiers := strings.Fields(strings.ToUpper(rest[end:]))
+	if len(modifiers) != 0 && skipColumnKind[modifiers[0]] {
+		return c, true, nil
+	}
+	if len(c.chType) == 0 {
+		return c, false, fmt.Errorf("column %s has no explicit type", c.name)
+	}
+	if len(modifiers) != 0 && modifiers[0] == "NULL" {
+		c.chType = "Nullable(" + c.chType + ")"
+	}
+	return c, false, nil
+}
+
+// enclosed returns the text up to the parenthesis closing an already opened one
+func enclosed(s string) (string, error) {
+	depth, quoted := 1, byte(0)
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case quoted != 0:
+			if ch == '\\' {
+				i++
+			} else if ch == quoted {
+				quoted = 0
+			}
+		case ch == '\'' || ch == '`' || ch == '"':
+			quoted = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			if depth--; depth == 0 {
+				return s[:i], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unbalanced parentheses")
+}
+
+// splitTopLevel splits s at commas outside of parentheses and quotes
+func splitTopLevel(s string) []string {
+	var (
+		parts  []string
+		start  int
+		depth  int
+		quoted byte
+	)
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case quoted != 0:
+			if ch == '\\' {
+				i++
+			} else if ch == quoted {
+				quoted = 0
+			}
+		case ch == '\'' || ch == '`' || ch == '"':
+			quoted = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case ch == ',' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func unquoteIdent(name string) string {
+	return strings.NewReplacer("`", "", `"`, "").Replace(name)
+}
+
+func isIdentStart(ch byte) bool {
+	return ch == '_' || 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z'
+}
+
+func isIdentChar(ch byte) bool {
+	return isIdentStart(ch) || '0' <= ch && ch <= '9'
+}
@@ -0,0 +1,165 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"go/format"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+)
+
+//go:embed tables.tpl
+var tablesSrc string
+
+var tablesTpl = template.Must(template.New("tables").Parse(tablesSrc))
+
+type structField struct {
+	Name   string
+	GoType string
+	Tag    string
+	ChType string
+}
+
+type structDecl struct {
+	Name   string
+	Table  string
+	Fields []structField
+}
+
+// initialisms are kept upper case in Go names
+var initialisms = map[string]bool{
+	"ID": true, "UUID": true, "URL": true, "URI": true, "IP": true, "HTTP": true,
+	"JSON": true, "API": true, "TTL": true, "UTC": true, "SQL": true,
+}
+
+func generate(pkg string, schemas []tableSchema, helpers bool) ([]byte, error) {
+	var (
+		imports = make(map[string]bool)
+		structs = make([]structDecl, 0, len(schemas))
+	)
+	for _, schema := range schemas {
+		_, table := splitTableName(schema.name)
+		decl := structDecl{
+			Name:  goName(table),
+			Table: schema.name,
+		}
+		names := make(map[string]int)
+		for _, c := range schema.columns {
+			col, err := column.Type(c.chType).Column(c.name, time.UTC)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", schema.name, c.name, err)
+			}
+			name := goName(c.name)
+			if names[name]++; names[name] > 1 {
+				name += strconv.Itoa(names[name])
+			}
+			decl.Fields = append(decl.Fields, structField{
+				Name:   name,
+				GoType: goType(col.ScanType(), imports),
+				Tag:    "`ch:" + strconv.Quote(c.name) + "`",
+				ChType: c.chType,
+			})
+		}
+		structs = append(structs, decl)
+	}
+	if helpers {
+		imports["context"] = true
+		imports["github.com/ClickHouse/clickhouse-go/v2/lib/driver"] = true
+	}
+	var std, paths []string
+	for path := range imports {
+		if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+			paths = append(paths, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(paths)
+
+	var out bytes.Buffer
+	if err := tablesTpl.Execute(&out, map[string]any{
+		"Package":    pkg,
+		"StdImports": std,
+		"Imports":    paths,
+		"Structs":    structs,
+		"Helpers":    helpers,
+	}); err != nil {
+		return nil, err
+	}
+	return format.Source(out.Bytes())
+}
+
+// goType renders t as Go source and collects the import paths it needs
+func goType(t reflect.Type, imports map[string]bool) string {
+	if len(t.Name()) != 0 {
+		if len(t.PkgPath()) != 0 {
+			imports[t.PkgPath()] = true
+		}
+		return t.String()
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + goType(t.Elem(), imports)
+	case reflect.Slice:
+		return "[]" + goType(t.Elem(), imports)
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), goType(t.Elem(), imports))
+	case reflect.Map:
+		return fmt.Sprintf("map[%s]%s", goType(t.Key(), imports), goType(t.Elem(), imports))
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any"
+		}
+	}
+	return t.String()
+}
+
+// goName converts a ClickHouse identifier such as user_id to an exported Go name such as UserID
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if upper := strings.ToUpper(part); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	switch s := b.String(); {
+	case len(s) == 0:
+		return "Column"
+	case unicode.IsDigit([]rune(s)[0]):
+		return "C" + s
+	default:
+		return s
+	}
+}
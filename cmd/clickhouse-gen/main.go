@@ -0,0 +1,172 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command clickhouse-gen generates Go structs with ch tags from ClickHouse table schemas,
+// read either from a live server or from a file of CREATE TABLE statements.
+//
+//	clickhouse-gen -dsn clickhouse://localhost:9000/default -tables events,users -package model -output model/tables.go
+//	clickhouse-gen -ddl schema.sql -package model -helpers
+//
+// Every column gets the Go type the driver scans it into most efficiently, Nullable columns
+// become pointers and LowCardinality is unwrapped. MATERIALIZED, ALIAS and EPHEMERAL columns
+// are left out as they are neither returned by SELECT * nor written by INSERT.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+func main() {
+	var (
+		dsn     = flag.String("dsn", "", "DSN of the server to read table schemas from")
+		ddl     = flag.String("ddl", "", "file with CREATE TABLE statements to read table schemas from")
+		tables  = flag.String("tables", "", "comma-separated list of tables, [db.]table; default all tables of the DDL file")
+		pkg     = flag.String("package", "main", "package name of the generated file")
+		output  = flag.String("output", "", "output file; default stdout")
+		helpers = flag.Bool("helpers", false, "emit typed Select and Insert helpers for every table")
+	)
+	flag.Parse()
+
+	var names []string
+	if len(*tables) != 0 {
+		names = strings.Split(*tables, ",")
+	}
+	var (
+		schemas []tableSchema
+		err     error
+	)
+	switch {
+	case len(*dsn) != 0 && len(*ddl) != 0:
+		err = errors.New("-dsn and -ddl are mutually exclusive")
+	case len(*dsn) != 0:
+		schemas, err = fromServer(*dsn, names)
+	case len(*ddl) != 0:
+		schemas, err = fromDDLFile(*ddl, names)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := generate(*pkg, schemas, *helpers)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(*output) == 0 {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = os.WriteFile(*output, src, 0o644)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func fromServer(dsn string, tables []string) ([]tableSchema, error) {
+	if len(tables) == 0 {
+		return nil, errors.New("-tables is required with -dsn")
+	}
+	opt, err := clickhouse.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := clickhouse.Open(opt)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	schemas := make([]tableSchema, 0, len(tables))
+	for _, name := range tables {
+		database, table := splitTableName(name)
+		rows, err := conn.Query(ctx, `
+			SELECT name, type FROM system.columns
+			WHERE database = if(empty($1), currentDatabase(), $1) AND table = $2
+				AND default_kind NOT IN ('MATERIALIZED', 'ALIAS', 'EPHEMERAL')
+			ORDER BY position`, database, table)
+		if err != nil {
+			return nil, err
+		}
+		schema := tableSchema{name: name}
+		for rows.Next() {
+			var c columnSchema
+			if err := rows.Scan(&c.name, &c.chType); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			schema.columns = append(schema.columns, c)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		if len(schema.columns) == 0 {
+			return nil, fmt.Errorf("table %s not found", name)
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas, nil
+}
+
+func fromDDLFile(name string, tables []string) ([]tableSchema, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	schemas, err := parseDDL(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return schemas, nil
+	}
+	selected := make([]tableSchema, 0, len(tables))
+	for _, table := range tables {
+		found := false
+		for _, schema := range schemas {
+			if schema.name == table {
+				selected, found = append(selected, schema), true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("table %s not found in %s", table, name)
+		}
+	}
+	return selected, nil
+}
+
+func splitTableName(name string) (database, table string) {
+	if database, table, found := strings.Cut(name, "."); found {
+		return database, table
+	}
+	return "", name
+}
@@ -0,0 +1,112 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDDL(t *testing.T) {
+	schemas, err := parseDDL(`
+		-- comment (
+		CREATE TABLE IF NOT EXISTS db.events ON CLUSTER default (
+			` + "`event id`" + ` UUID,
+			ts DateTime64(3, 'UTC') DEFAULT now64() CODEC(Delta, ZSTD),
+			e Enum8('a, (b' = 1, 'c' = 2) COMMENT 'enum',
+			name String NULL,
+			strict String NOT NULL,
+			day Date MATERIALIZED toDate(ts),
+			INDEX idx ts TYPE minmax GRANULARITY 1,
+			PRIMARY KEY ts
+		) ENGINE = MergeTree ORDER BY ts;
+		CREATE TABLE users (id UInt64) ENGINE = Memory;
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, []tableSchema{
+		{
+			name: "db.events",
+			columns: []columnSchema{
+				{name: "event id", chType: "UUID"},
+				{name: "ts", chType: "DateTime64(3, 'UTC')"},
+				{name: "e", chType: "Enum8('a, (b' = 1, 'c' = 2)"},
+				{name: "name", chType: "Nullable(String)"},
+				{name: "strict", chType: "String"},
+			},
+		},
+		{
+			name:    "users",
+			columns: []columnSchema{{name: "id", chType: "UInt64"}},
+		},
+	}, schemas)
+
+	_, err = parseDDL("SELECT 1")
+	assert.Error(t, err)
+	_, err = parseDDL("CREATE TABLE t (a DEFAULT 1) ENGINE = Memory")
+	assert.Error(t, err)
+}
+
+func TestGoName(t *testing.T) {
+	for in, expected := range map[string]string{
+		"user_id":    "UserID",
+		"event id":   "EventID",
+		"ts":         "Ts",
+		"1st":        "C1st",
+		"http_url":   "HTTPURL",
+		"camelCase":  "CamelCase",
+		"__":         "Column",
+		"Attrs.keys": "AttrsKeys",
+	} {
+		assert.Equal(t, expected, goName(in), in)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := generate("model", []tableSchema{{
+		name: "db.user_events",
+		columns: []columnSchema{
+			{name: "id", chType: "UInt64"},
+			{name: "country", chType: "LowCardinality(Nullable(String))"},
+			{name: "ts", chType: "DateTime64(3)"},
+			{name: "tags", chType: "Array(LowCardinality(String))"},
+		},
+	}}, true)
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"package model",
+		"\"time\"",
+		"\"github.com/ClickHouse/clickhouse-go/v2/lib/driver\"",
+		"type UserEvents struct {",
+		"ID      uint64    `ch:\"id\"`      // UInt64",
+		"Country *string   `ch:\"country\"` // LowCardinality(Nullable(String))",
+		"Ts      time.Time `ch:\"ts\"`",
+		"Tags    []string  `ch:\"tags\"`",
+		"func SelectUserEvents(ctx context.Context, conn driver.Conn, query string, args ...any) ([]UserEvents, error) {",
+		"conn.PrepareBatch(ctx, \"INSERT INTO db.user_events\")",
+	} {
+		assert.Contains(t, string(src), expected)
+	}
+
+	_, err = generate("model", []tableSchema{{
+		name:    "t",
+		columns: []columnSchema{{name: "a", chType: "NoSuchType"}},
+	}}, false)
+	assert.Error(t, err)
+}
@@ -18,6 +18,7 @@
 package clickhouse
 
 import (
+	"bufio"
 	"context"
 	"crypto/tls"
 	"errors"
@@ -44,13 +45,27 @@ func dial(ctx context.Context, addr string, num int, opt *Options) (*connect, er
 		debugf = func(format string, v ...any) {}
 	)
 
+	credentials, err := providedCredentials(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := opt.TLS
+	if credentials != nil {
+		if credentials.Certificate != nil && opt.DialContext != nil {
+			return nil, errors.New("clickhouse: a client certificate of the credentials provider cannot be used with DialContext")
+		}
+		if tlsConfig, err = withClientCertificate(opt.TLS, credentials.Certificate); err != nil {
+			return nil, err
+		}
+	}
+
 	switch {
 	case opt.DialContext != nil:
 		conn, err = opt.DialContext(ctx, addr)
 	default:
 		switch {
-		case opt.TLS != nil:
-			conn, err = tls.DialWithDialer(&net.Dialer{Timeout: opt.DialTimeout}, "tcp", addr, opt.TLS)
+		case tlsConfig != nil:
+			conn, err = tls.DialWithDialer(&net.Dialer{Timeout: opt.DialTimeout}, "tcp", addr, tlsConfig)
 		default:
 			conn, err = net.DialTimeout("tcp", addr, opt.DialTimeout)
 		}
@@ -91,6 +106,7 @@ func dial(ctx context.Context, addr string, num int, opt *Options) (*connect, er
 		compressor = compress.NewWriter(compress.LevelZero, compress.None)
 	}
 
+	source := bufio.NewReaderSize(conn, 128<<10)
 	var (
 		connect = &connect{
 			id:                   num,
@@ -98,9 +114,10 @@ func dial(ctx context.Context, addr string, num int, opt *Options) (*connect, er
 			conn:                 conn,
 			debugfFunc:           debugf,
 			buffer:               new(chproto.Buffer),
-			reader:               chproto.NewReader(conn),
+			source:               source,
+			reader:               chproto.NewReader(source),
 			revision:             ClientTCPProtocolVersion,
-			structMap:            &structMap{},
+			structMap:            newStructMap(opt),
 			compression:          compression,
 			connectedAt:          time.Now(),
 			compressor:           compressor,
@@ -111,7 +128,15 @@ func dial(ctx context.Context, addr string, num int, opt *Options) (*connect, er
 	)
 
 	auth := opt.Auth
-	if useJWTAuth(opt) {
+	switch {
+	case credentials != nil:
+		auth.Username, auth.Password = credentials.Username, credentials.Password
+		if credentials.JWT != "" {
+			auth.Username, auth.Password = jwtAuthMarker, credentials.JWT
+		}
+		auth.SSHSigner = nil
+		connect.credentialsExpiry = credentials.Expiry
+	case useJWTAuth(opt):
 		jwt, err := opt.GetJWT(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get JWT: %w", err)
@@ -119,6 +144,7 @@ func dial(ctx context.Context, addr string, num int, opt *Options) (*connect, er
 
 		auth.Username = jwtAuthMarker
 		auth.Password = jwt
+		auth.SSHSigner = nil
 	}
 
 	if err := connect.handshake(auth); err != nil {
@@ -148,12 +174,17 @@ type connect struct {
 	server               ServerVersion
 	closed               bool
 	buffer               *chproto.Buffer
+	source               *bufio.Reader // the connection, read by reader until packets are chunked
 	reader               *chproto.Reader
+	chunkedSend          bool
+	packetEnds           []int // the ends of the packets in buffer, when chunkedSend
+	queryTimezone        *time.Location
 	released             bool
 	revision             uint64
 	structMap            *structMap
 	compression          CompressionMethod
 	connectedAt          time.Time
+	credentialsExpiry    time.Time
 	compressor           *compress.Writer
 	readTimeout          time.Duration
 	blockBufferSize      uint8
@@ -174,6 +205,10 @@ func (c *connect) connectedAtTime() time.Time {
 	return c.connectedAt
 }
 
+func (c *connect) credentialsExpired() bool {
+	return !c.credentialsExpiry.IsZero() && !time.Now().Before(c.credentialsExpiry)
+}
+
 func (c *connect) serverVersion() (*ServerVersion, error) {
 	return &c.server, nil
 }
@@ -215,6 +250,10 @@ func (c *connect) isBad() bool {
 		return true
 	}
 
+	if c.credentialsExpired() {
+		return true
+	}
+
 	if err := c.connCheck(); err != nil {
 		return true
 	}
@@ -334,6 +373,7 @@ func (c *connect) sendData(block *proto.Block, name string) error {
 	if err := c.compressBuffer(compressionOffset); err != nil {
 		return err
 	}
+	c.endPacket()
 
 	if err := c.flush(); err != nil {
 		switch {
@@ -381,8 +421,11 @@ func (c *connect) readData(ctx context.Context, packet byte, compressible bool)
 
 	userLocation := queryOptionsUserLocation(ctx)
 	location := c.server.Timezone
-	if userLocation != nil {
+	switch {
+	case userLocation != nil:
 		location = userLocation
+	case c.queryTimezone != nil:
+		location = c.queryTimezone
 	}
 
 	block := proto.Block{Timezone: location}
@@ -401,18 +444,31 @@ func (c *connect) freeBuffer() {
 	c.compressor.Data = nil
 }
 
+// endPacket marks the end of a packet written to the buffer, for chunked connections.
+func (c *connect) endPacket() {
+	if c.chunkedSend {
+		c.packetEnds = append(c.packetEnds, len(c.buffer.Buf))
+	}
+}
+
 func (c *connect) flush() error {
 	if len(c.buffer.Buf) == 0 {
 		// Nothing to flush.
 		return nil
 	}
 
-	n, err := c.conn.Write(c.buffer.Buf)
+	data := c.buffer.Buf
+	if c.chunkedSend {
+		data = proto.AppendChunks(nil, data, c.packetEnds)
+		c.packetEnds = c.packetEnds[:0]
+	}
+
+	n, err := c.conn.Write(data)
 	if err != nil {
 		return fmt.Errorf("write: %w", err)
 	}
 
-	if n != len(c.buffer.Buf) {
+	if n != len(data) {
 		return errors.New("wrote less than expected")
 	}
 
@@ -27,6 +27,8 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
@@ -36,12 +38,13 @@ var insertMatch = regexp.MustCompile(`(?i)(INSERT\s+INTO\s+[^( ]+(?:\s*\([^()]*(
 var columnMatch = regexp.MustCompile(`INSERT INTO .+\s\((?P<Columns>.+)\)$`)
 
 func (c *connect) prepareBatch(ctx context.Context, release nativeTransportRelease, acquire nativeTransportAcquire, query string, opts driver.PrepareBatchOptions) (driver.Batch, error) {
-	query, _, queryColumns, verr := extractNormalizedInsertQueryAndColumns(query)
+	query, tableName, queryColumns, verr := extractNormalizedInsertQueryAndColumns(query)
 	if verr != nil {
 		return nil, verr
 	}
 
 	options := queryOptions(ctx)
+	deduplicationToken := batchDeduplicationToken(&options, opts)
 	if deadline, ok := ctx.Deadline(); ok {
 		c.conn.SetDeadline(deadline)
 		defer c.conn.SetDeadline(time.Time{})
@@ -50,10 +53,12 @@ func (c *connect) prepareBatch(ctx context.Context, release nativeTransportRelea
 		release(c, err)
 		return nil, err
 	}
-	var (
-		onProcess  = options.onProcess()
-		block, err = c.firstBlock(ctx, onProcess)
-	)
+	var tableColumns []TableColumn
+	onProcess := options.onProcess()
+	onProcess.tableColumns = func(columns []TableColumn) {
+		tableColumns = columns
+	}
+	block, err := c.firstBlock(ctx, onProcess)
 	if err != nil {
 		release(c, err)
 		return nil, err
@@ -76,15 +81,20 @@ func (c *connect) prepareBatch(ctx context.Context, release nativeTransportRelea
 	}
 
 	b := &batch{
-		ctx:          ctx,
-		query:        query,
-		conn:         c,
-		block:        block,
-		released:     false,
-		connRelease:  connRelease,
-		connAcquire:  connAcquire,
-		onProcess:    onProcess,
-		closeOnFlush: opts.CloseOnFlush,
+		ctx:                ctx,
+		query:              query,
+		tableName:          tableName,
+		tableColumns:       tableColumns,
+		omitDefaults:       opts.OmitDefaults,
+		conn:               c,
+		block:              block,
+		released:           false,
+		connRelease:        connRelease,
+		connAcquire:        connAcquire,
+		onProcess:          onProcess,
+		closeOnFlush:       opts.CloseOnFlush,
+		replayBuffer:       opts.ReplayBuffer,
+		deduplicationToken: deduplicationToken,
 	}
 
 	if opts.ReleaseConnection {
@@ -94,18 +104,44 @@ func (c *connect) prepareBatch(ctx context.Context, release nativeTransportRelea
 	return b, nil
 }
 
+// batchDeduplicationToken sets insert_deduplication_token for batches with a replay buffer,
+// so that replayed blocks are deduplicated by the server. Returns the token in use.
+func batchDeduplicationToken(options *QueryOptions, opts driver.PrepareBatchOptions) string {
+	token := opts.DeduplicationToken
+	if token == "" {
+		if v, ok := options.settings["insert_deduplication_token"]; ok {
+			token = fmt.Sprint(v)
+		}
+	}
+	if token == "" && opts.ReplayBuffer {
+		token = uuid.NewString()
+	}
+	if token != "" {
+		options.settings["insert_deduplication_token"] = token
+	}
+	return token
+}
+
 type batch struct {
-	err          error
-	ctx          context.Context
-	query        string
-	conn         *connect
-	sent         bool // sent signalize that batch is send to ClickHouse.
-	released     bool // released signalize that conn was returned to pool and can't be used.
-	closeOnFlush bool // closeOnFlush signalize that batch should close query and release conn when use Flush
-	block        *proto.Block
-	connRelease  func(*connect, error)
-	connAcquire  func(context.Context) (*connect, error)
-	onProcess    *onProcess
+	err                error
+	ctx                context.Context
+	query              string
+	tableName          string
+	tableColumns       []TableColumn
+	omitDefaults       bool // omitDefaults signalize that the first appended row may leave out columns with defaults
+	conn               *connect
+	sent               bool // sent signalize that batch is send to ClickHouse.
+	released           bool // released signalize that conn was returned to pool and can't be used.
+	closeOnFlush       bool // closeOnFlush signalize that batch should close query and release conn when use Flush
+	replayBuffer       bool // replayBuffer signalize that flushed blocks are kept until Send succeeds
+	delivered          bool // delivered signalize that Send succeeded and the replay buffer was dropped
+	deduplicationToken string
+	block              *proto.Block
+	replay             []*proto.Block
+	connRelease        func(*connect, error)
+	connAcquire        func(context.Context) (*connect, error)
+	onProcess          *onProcess
+	checker            blockChecker
 }
 
 func (b *batch) release(err error) {
@@ -140,6 +176,16 @@ func (b *batch) Append(v ...any) error {
 		}
 	}
 
+	if b.canOmitDefaults() {
+		columns := b.block.ColumnsNames()
+		if omitted := omittedDefaultColumns(columns, b.tableColumns, defaultColumnsProvider(columns, b.tableColumns, len(v))); len(omitted) != 0 {
+			if err := b.omitColumns(omitted); err != nil {
+				return err
+			}
+			v = omitColumns(columns, v, omitted)
+		}
+	}
+
 	if err := b.block.Append(v...); err != nil {
 		b.err = fmt.Errorf("%w: %w", ErrBatchInvalid, err)
 		b.release(err)
@@ -148,6 +194,46 @@ func (b *batch) Append(v ...any) error {
 	return nil
 }
 
+// canOmitDefaults reports whether the columns of the batch may still be narrowed
+func (b *batch) canOmitDefaults() bool {
+	return b.omitDefaults && b.tableColumns != nil && !b.sent && !b.released &&
+		b.block.Rows() == 0 && len(b.replay) == 0
+}
+
+// omitColumns restarts the INSERT with the omitted columns left out, the server fills them with their defaults
+func (b *batch) omitColumns(omitted []string) error {
+	b.omitDefaults = false
+	if err := b.closeQuery(); err != nil {
+		b.release(err)
+		return err
+	}
+	var columns []string
+	for _, name := range b.block.ColumnsNames() {
+		if !slices.Contains(omitted, name) {
+			columns = append(columns, name)
+		}
+	}
+	b.query = insertQueryWithColumns(b.tableName, columns)
+	options := queryOptions(b.ctx)
+	if b.deduplicationToken != "" {
+		options.settings["insert_deduplication_token"] = b.deduplicationToken
+	}
+	if err := b.conn.sendQuery(b.query, &options); err != nil {
+		b.release(err)
+		return err
+	}
+	block, err := b.conn.firstBlock(b.ctx, b.onProcess)
+	if err == nil {
+		err = block.SortColumns(columns)
+	}
+	if err != nil {
+		b.release(err)
+		return err
+	}
+	b.block = block
+	return nil
+}
+
 // appendRowsBlocks is an experimental feature that allows rows blocks be appended directly to the batch.
 // This API is not stable and may be changed in the future.
 // See: tests/batch_block_test.go
@@ -182,13 +268,48 @@ func (b *batch) AppendStruct(v any) error {
 	if b.err != nil {
 		return b.err
 	}
-	values, err := b.conn.structMap.Map("AppendStruct", b.block.ColumnsNames(), v, false)
+	if b.canOmitDefaults() {
+		if index, ok := b.conn.structMap.indexOf(v); ok {
+			if omitted := omittedDefaultColumns(b.block.ColumnsNames(), b.tableColumns, func(_ int, name string) bool {
+				_, found := index[name]
+				return found
+			}); len(omitted) != 0 {
+				if err := b.omitColumns(omitted); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if a, ok := v.(driver.StructAppender); ok {
+		if b.sent {
+			return ErrBatchAlreadySent
+		}
+		if err := a.AppendTo(b.block.Columns); err != nil {
+			b.err = fmt.Errorf("%w: %w", ErrBatchInvalid, err)
+			b.release(err)
+			return err
+		}
+		return nil
+	}
+	values, err := b.conn.structMap.appendValues(b.block, v)
 	if err != nil {
 		return err
 	}
 	return b.Append(values...)
 }
 
+func (b *batch) checkAppend(v ...any) error {
+	return b.checker.check(b.block, func(block *proto.Block) error {
+		return block.Append(v...)
+	})
+}
+
+func (b *batch) checkAppendStruct(v any) error {
+	return b.checker.check(b.block, func(block *proto.Block) error {
+		return appendStructToBlock(block, b.conn.structMap, v)
+	})
+}
+
 func (b *batch) IsSent() bool {
 	return b.sent
 }
@@ -233,6 +354,9 @@ func (b *batch) Send() (err error) {
 	if b.err != nil {
 		return b.err
 	}
+	if b.delivered {
+		return ErrBatchAlreadySent
+	}
 	if b.sent || b.released {
 		if err = b.resetConnection(); err != nil {
 			return err
@@ -252,6 +376,10 @@ func (b *batch) Send() (err error) {
 	if err = b.closeQuery(); err != nil {
 		return err
 	}
+	if b.replayBuffer {
+		b.delivered = true
+		b.replay = nil
+	}
 	return nil
 }
 
@@ -266,6 +394,9 @@ func (b *batch) resetConnection() (err error) {
 	}()
 
 	options := queryOptions(b.ctx)
+	if b.deduplicationToken != "" {
+		options.settings["insert_deduplication_token"] = b.deduplicationToken
+	}
 	if deadline, ok := b.ctx.Deadline(); ok {
 		b.conn.conn.SetDeadline(deadline)
 		defer b.conn.conn.SetDeadline(time.Time{})
@@ -281,6 +412,14 @@ func (b *batch) resetConnection() (err error) {
 		return err
 	}
 
+	for i, block := range b.replay {
+		b.conn.debugf("[batch replay] block=%d rows=%d", i, block.Rows())
+		if err = b.conn.sendData(block, ""); err != nil {
+			b.release(err)
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -298,20 +437,56 @@ func (b *batch) Flush() error {
 	}
 	if b.block.Rows() != 0 {
 		if err := b.conn.sendData(b.block, ""); err != nil {
-			// broken pipe/conn reset aren't generally recoverable on retry
-			if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+			// broken pipe/conn reset aren't generally recoverable on retry, and with the replay
+			// buffer the retry replays the INSERT on a new connection anyway
+			if b.replayBuffer || errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
 				b.release(err)
 			}
 			return err
 		}
 		if b.closeOnFlush {
-			b.release(b.closeQuery())
+			err := b.closeQuery()
+			b.release(err)
+			if b.replayBuffer {
+				if err != nil {
+					// the next Flush or Send replays the blocks of the failed INSERT
+					block, newErr := newEmptyBlock(b.block)
+					if newErr != nil {
+						return errors.Join(err, newErr)
+					}
+					b.replay, b.block = append(b.replay, b.block), block
+					return err
+				}
+				// the INSERT committed all blocks, there is nothing left to replay
+				b.replay = nil
+				b.block.Reset()
+				return nil
+			}
+		}
+		if b.replayBuffer {
+			block, err := newEmptyBlock(b.block)
+			if err != nil {
+				return err
+			}
+			b.replay, b.block = append(b.replay, b.block), block
+			return nil
 		}
 	}
 	b.block.Reset()
 	return nil
 }
 
+// newEmptyBlock returns a block with the same columns as the given block but no rows.
+func newEmptyBlock(b *proto.Block) (*proto.Block, error) {
+	block := &proto.Block{Timezone: b.Timezone}
+	for _, c := range b.Columns {
+		if err := block.AddColumn(c.Name(), c.Type()); err != nil {
+			return nil, err
+		}
+	}
+	return block, nil
+}
+
 func (b *batch) Rows() int {
 	return b.block.Rows()
 }
@@ -320,6 +495,12 @@ func (b *batch) Columns() []column.Interface {
 	return slices.Clone(b.block.Columns)
 }
 
+var _ driver.SchemaBatch = (*batch)(nil)
+
+func (b *batch) TableColumns() []TableColumn {
+	return slices.Clone(b.tableColumns)
+}
+
 func (b *batch) closeQuery() error {
 	if err := b.conn.sendData(&proto.Block{}, ""); err != nil {
 		return err
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"time"
 
+	chproto "github.com/ClickHouse/ch-go/proto"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
 )
 
@@ -42,7 +43,15 @@ func (c *connect) handshake(auth Auth) error {
 			ClientVersion:   proto.Version{ClientVersionMajor, ClientVersionMinor, ClientVersionPatch}, //nolint:govet
 		}
 		handshake.Encode(c.buffer)
-		{
+		switch {
+		case auth.SSHSigner != nil:
+			c.buffer.PutString(auth.Database)
+			c.buffer.PutString(proto.SSHKeyAuthMarker + auth.Username)
+			c.buffer.PutString("")
+			if err := c.sshAuth(auth); err != nil {
+				return err
+			}
+		default:
 			c.buffer.PutString(auth.Database)
 			c.buffer.PutString(auth.Username)
 			c.buffer.PutString(auth.Password)
@@ -60,7 +69,7 @@ func (c *connect) handshake(auth Auth) error {
 		case proto.ServerException:
 			return c.exception()
 		case proto.ServerHello:
-			if err := c.server.Decode(c.reader); err != nil {
+			if err := c.server.Decode(c.reader, ClientTCPProtocolVersion); err != nil {
 				return err
 			}
 		case proto.ServerEndOfStream:
@@ -82,10 +91,70 @@ func (c *connect) handshake(auth Auth) error {
 	return nil
 }
 
+// sshAuth answers the SSH challenge of the server, which precedes its hello.
+func (c *connect) sshAuth(auth Auth) error {
+	c.debugf("[handshake] -> ssh challenge request")
+	c.buffer.PutByte(proto.ClientSSHChallengeRequest)
+	if err := c.flush(); err != nil {
+		return err
+	}
+	packet, err := c.reader.ReadByte()
+	if err != nil {
+		return err
+	}
+	switch packet {
+	case proto.ServerException:
+		return c.exception()
+	case proto.ServerSSHChallenge:
+	default:
+		return fmt.Errorf("[handshake] unexpected packet [%d] from server, expected ssh challenge", packet)
+	}
+	challenge, err := c.reader.Str()
+	if err != nil {
+		return err
+	}
+	message := proto.SSHAuthMessage(ClientTCPProtocolVersion, auth.Database, auth.Username, challenge)
+	signature, err := signSSHChallenge(auth.SSHSigner, message)
+	if err != nil {
+		return &OpError{Op: "handshake", Err: fmt.Errorf("sign ssh challenge: %w", err)}
+	}
+	c.debugf("[handshake] -> ssh challenge response")
+	c.buffer.PutByte(proto.ClientSSHChallengeResponse)
+	c.buffer.PutString(string(signature))
+	return nil
+}
+
 func (c *connect) sendAddendum() error {
 	if c.revision >= proto.DBMS_MIN_PROTOCOL_VERSION_WITH_QUOTA_KEY {
-		c.buffer.PutString("") // todo quota key support
+		c.buffer.PutString(c.opt.QuotaKey)
 	}
-
-	return c.flush()
+	var chunkedSend, chunkedRecv bool
+	if c.revision >= proto.DBMS_MIN_PROTOCOL_VERSION_WITH_CHUNKED_PACKETS {
+		framing := proto.FramingNotChunkedOptional
+		if c.opt.ChunkedPackets {
+			framing = proto.FramingChunkedOptional
+		}
+		var err error
+		if chunkedSend, err = proto.NegotiateChunked(c.server.ChunkedRecv, framing); err != nil {
+			return &OpError{Op: "handshake", Err: err}
+		}
+		if chunkedRecv, err = proto.NegotiateChunked(c.server.ChunkedSend, framing); err != nil {
+			return &OpError{Op: "handshake", Err: err}
+		}
+		c.buffer.PutString(proto.FramingOf(chunkedSend))
+		c.buffer.PutString(proto.FramingOf(chunkedRecv))
+	}
+	if c.revision >= proto.DBMS_MIN_REVISION_WITH_VERSIONED_PARALLEL_REPLICAS_PROTOCOL {
+		c.buffer.PutUVarInt(proto.DBMS_PARALLEL_REPLICAS_PROTOCOL_VERSION)
+	}
+	if err := c.flush(); err != nil {
+		return err
+	}
+	// the packets after the addendum are framed as negotiated
+	c.chunkedSend = chunkedSend
+	if chunkedRecv {
+		c.reader = chproto.NewReader(proto.NewChunkedReader(c.source))
+	}
+	c.debugf("[handshake] chunked send=%t recv=%t", chunkedSend, chunkedRecv)
+	return nil
 }
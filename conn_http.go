@@ -119,8 +119,22 @@ func (rw *HTTPReaderWriter) reset(pw *io.PipeWriter) io.WriteCloser {
 
 // applyOptionsToRequest applies the client Options (such as auth, headers, client info) to the given http.Request
 func applyOptionsToRequest(ctx context.Context, req *http.Request, opt *Options) error {
+	username, password := opt.Auth.Username, opt.Auth.Password
 	jwt := queryOptionsJWT(ctx)
 	useJWT := jwt != "" || useJWTAuth(opt)
+	if jwt == "" {
+		credentials, err := providedCredentials(ctx, opt)
+		if err != nil {
+			return err
+		}
+		if credentials != nil {
+			if credentials.Certificate != nil && opt.TLS == nil {
+				return errors.New("clickhouse: a client certificate requires TLS")
+			}
+			username, password, jwt = credentials.Username, credentials.Password, credentials.JWT
+			useJWT = jwt != ""
+		}
+	}
 
 	if opt.TLS != nil && useJWT {
 		if jwt == "" {
@@ -132,20 +146,20 @@ func applyOptionsToRequest(ctx context.Context, req *http.Request, opt *Options)
 		}
 
 		req.Header.Set("Authorization", "Bearer "+jwt)
-	} else if opt.TLS != nil && len(opt.Auth.Username) > 0 {
-		req.Header.Set("X-ClickHouse-User", opt.Auth.Username)
-		if len(opt.Auth.Password) > 0 {
-			req.Header.Set("X-ClickHouse-Key", opt.Auth.Password)
+	} else if opt.TLS != nil && len(username) > 0 {
+		req.Header.Set("X-ClickHouse-User", username)
+		if len(password) > 0 {
+			req.Header.Set("X-ClickHouse-Key", password)
 			req.Header.Set("X-ClickHouse-SSL-Certificate-Auth", "off")
 		} else {
 			req.Header.Set("X-ClickHouse-SSL-Certificate-Auth", "on")
 		}
-	} else if opt.TLS == nil && len(opt.Auth.Username) > 0 {
-		if len(opt.Auth.Password) > 0 {
-			req.URL.User = url.UserPassword(opt.Auth.Username, opt.Auth.Password)
+	} else if opt.TLS == nil && len(username) > 0 {
+		if len(password) > 0 {
+			req.URL.User = url.UserPassword(username, password)
 
 		} else {
-			req.URL.User = url.User(opt.Auth.Username)
+			req.URL.User = url.User(username)
 		}
 	}
 
@@ -184,6 +198,12 @@ func dialHttp(ctx context.Context, addr string, num int, opt *Options) (*httpCon
 			return nil, errors.New("invalid interface type for http")
 		}
 	}
+	if opt.HttpFormat != "" && !opt.HttpFormat.valid() {
+		return nil, fmt.Errorf("unknown HTTP format %q", opt.HttpFormat)
+	}
+	if opt.Auth.SSHSigner != nil {
+		return nil, errors.New("SSH key authentication is only supported by the native protocol")
+	}
 	u := &url.URL{
 		Scheme: opt.scheme,
 		Host:   addr,
@@ -214,6 +234,10 @@ func dialHttp(ctx context.Context, addr string, num int, opt *Options) (*httpCon
 		query.Set(k, fmt.Sprint(v))
 	}
 
+	if opt.QuotaKey != "" {
+		query.Set(quotaKeyParamName, opt.QuotaKey)
+	}
+
 	query.Set("default_format", "Native")
 	// TODO: we support newer revisions but for some reason this completely breaks Native format
 	//query.Set("client_protocol_version", strconv.Itoa(ClientTCPProtocolVersion))
@@ -235,6 +259,12 @@ func dialHttp(ctx context.Context, addr string, num int, opt *Options) (*httpCon
 		ResponseHeaderTimeout: opt.ReadTimeout,
 		TLSClientConfig:       opt.TLS,
 	}
+	var certExpiry *certificateExpiry
+	if opt.TLS != nil && opt.CredentialsProvider != nil {
+		// the client certificate of the provider is requested for each new TLS connection
+		certExpiry = new(certificateExpiry)
+		t.TLSClientConfig = providedCertificateTLSConfig(opt, certExpiry)
+	}
 
 	if opt.DialContext != nil {
 		t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
@@ -242,6 +272,13 @@ func dialHttp(ctx context.Context, addr string, num int, opt *Options) (*httpCon
 		}
 	}
 
+	var transport http.RoundTripper = t
+	if opt.TransportFunc != nil {
+		if transport, err = opt.TransportFunc(t); err != nil {
+			return nil, err
+		}
+	}
+
 	conn := httpConnect{
 		id:          num,
 		connectedAt: time.Now(),
@@ -249,7 +286,7 @@ func dialHttp(ctx context.Context, addr string, num int, opt *Options) (*httpCon
 		debugfFunc:  debugf,
 		opt:         opt,
 		client: &http.Client{
-			Transport: t,
+			Transport: transport,
 		},
 		url: u,
 		// TODO: learn more about why revision is broken
@@ -259,6 +296,7 @@ func dialHttp(ctx context.Context, addr string, num int, opt *Options) (*httpCon
 		blockCompressor: compress.NewWriter(compress.Level(opt.Compression.Level), compress.Method(opt.Compression.Method)),
 		compressionPool: compressionPool,
 		blockBufferSize: opt.BlockBufferSize,
+		certExpiry:      certExpiry,
 	}
 
 	handshake, err := conn.queryHello(ctx, func(nativeTransport, error) {})
@@ -285,6 +323,8 @@ type httpConnect struct {
 	compressionPool Pool[HTTPReaderWriter]
 	blockBufferSize uint8
 	handshake       proto.ServerHandshake
+	// certExpiry is when the first provided client certificate of the TLS connections expires, nil without
+	certExpiry *certificateExpiry
 }
 
 func (h *httpConnect) serverVersion() (*ServerVersion, error) {
@@ -299,6 +339,12 @@ func (h *httpConnect) connectedAtTime() time.Time {
 	return h.connectedAt
 }
 
+// credentialsExpired reports whether a provided client certificate of the TLS connections has expired.
+// The pool then closes the connection, which closes its TLS connections with CloseIdleConnections.
+func (h *httpConnect) credentialsExpired() bool {
+	return h.certExpiry != nil && h.certExpiry.expired()
+}
+
 func (h *httpConnect) isReleased() bool {
 	return h.released
 }
@@ -404,11 +450,18 @@ func createCompressionPool(compression *Compression) (Pool[HTTPReaderWriter], er
 	return pool, nil
 }
 
-func (h *httpConnect) writeData(block *proto.Block) error {
+func (h *httpConnect) writeData(block *proto.Block, format HTTPFormat) error {
 	// Saving offset of compressible data
 	start := len(h.buffer.Buf)
-	if err := block.Encode(h.buffer, h.revision); err != nil {
-		return fmt.Errorf("block encode: %w", err)
+	switch format {
+	case HTTPFormatRowBinary, HTTPFormatRowBinaryWithNamesAndTypes:
+		if err := block.EncodeRowBinary(h.buffer, format == HTTPFormatRowBinaryWithNamesAndTypes); err != nil {
+			return fmt.Errorf("block encode: %w", err)
+		}
+	default:
+		if err := block.Encode(h.buffer, h.revision); err != nil {
+			return fmt.Errorf("block encode: %w", err)
+		}
 	}
 	if h.compression == CompressionLZ4 || h.compression == CompressionZSTD {
 		// Performing compression. Supported and requires
@@ -438,6 +491,51 @@ func (h *httpConnect) readData(reader *chproto.Reader, timezone *time.Location)
 	return &block, nil
 }
 
+// rowBinaryBlockRows is the number of rows of the blocks read from RowBinary results
+const rowBinaryBlockRows = 65536
+
+// blockReader returns a func reading the blocks of a result in format, which is decoded by readData for Native
+func (h *httpConnect) blockReader(reader io.Reader, format HTTPFormat, timezone *time.Location) func() (*proto.Block, error) {
+	if format == HTTPFormatNative {
+		chReader := chproto.NewReader(reader)
+		return func() (*proto.Block, error) {
+			return h.readData(chReader, timezone)
+		}
+	}
+
+	location := h.handshake.Timezone
+	if timezone != nil {
+		location = timezone
+	}
+	if h.compression == CompressionLZ4 || h.compression == CompressionZSTD {
+		// RowBinary rows span the compressed frames, which are decompressed as a single stream
+		reader = compress.NewReader(reader)
+	}
+	rowReader := proto.NewRowBinaryReader(reader, location, nil)
+	return func() (*proto.Block, error) {
+		block, err := rowReader.ReadBlock(rowBinaryBlockRows)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("block decode: %w", err)
+		}
+		return block, err
+	}
+}
+
+// format returns the format of the data of a query, Options.HttpFormat unless overridden by the query
+func (h *httpConnect) format(options *QueryOptions) (HTTPFormat, error) {
+	switch {
+	case options != nil && options.httpFormat != "":
+		if !options.httpFormat.valid() {
+			return "", fmt.Errorf("unknown HTTP format %q", options.httpFormat)
+		}
+		return options.httpFormat, nil
+	case h.opt.HttpFormat != "":
+		return h.opt.HttpFormat, nil
+	default:
+		return HTTPFormatNative, nil
+	}
+}
+
 func (h *httpConnect) sendStreamQuery(ctx context.Context, r io.Reader, options *QueryOptions, headers map[string]string) (*http.Response, error) {
 	req, err := h.createRequest(ctx, h.url.String(), r, options, headers)
 	if err != nil {
@@ -487,6 +585,11 @@ func (h *httpConnect) readRawResponse(response *http.Response) (body []byte, err
 }
 
 func (h *httpConnect) createRequest(ctx context.Context, requestUrl string, reader io.Reader, options *QueryOptions, headers map[string]string) (*http.Request, error) {
+	// the headers and a TLS handshake of the request use the same provided credentials
+	ctx, err := withProvidedCredentials(ctx, h.opt)
+	if err != nil {
+		return nil, err
+	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestUrl, reader)
 	if err != nil {
 		return nil, err
@@ -523,6 +626,14 @@ func (h *httpConnect) createRequest(ctx context.Context, requestUrl string, read
 		for key, value := range options.parameters {
 			query.Set(fmt.Sprintf("param_%s", key), value)
 		}
+		format, err := h.format(options)
+		if err != nil {
+			return nil, err
+		}
+		if format != HTTPFormatNative {
+			// results without their types cannot be decoded, so RowBinary selects them with names and types
+			query.Set("default_format", string(HTTPFormatRowBinaryWithNamesAndTypes))
+		}
 		req.URL.RawQuery = query.Encode()
 	}
 	return req, nil
@@ -26,36 +26,31 @@ import (
 	"io"
 	"os"
 	"slices"
+	"strings"
 )
 
-func fetchColumnNamesAndTypesForInsert(h *httpConnect, release nativeTransportRelease, ctx context.Context, tableName string, requestedColumnNames []string) ([]ColumnNameAndType, error) {
-	describeTableQuery := fmt.Sprintf("DESCRIBE TABLE %s", tableName)
-	r, err := h.query(ctx, release, describeTableQuery)
+func fetchColumnNamesAndTypesForInsert(h *httpConnect, release nativeTransportRelease, ctx context.Context, tableName string, requestedColumnNames []string) ([]ColumnNameAndType, []TableColumn, error) {
+	describe, err := h.opt.schemaCache.describe(tableName, func() ([]TableColumn, error) {
+		r, err := h.query(ctx, release, fmt.Sprintf("DESCRIBE TABLE %s", tableName))
+		if err != nil {
+			return nil, err
+		}
+		return scanTableColumns(r)
+	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer r.Close()
 
 	columnsToTypes := make(map[string]string)
 	var allColumns []string
-	for r.Next() {
-		var (
-			colName     string
-			colType     string
-			defaultType string
-			ignore      string
-		)
-
-		if err = r.Scan(&colName, &colType, &defaultType, &ignore, &ignore, &ignore, &ignore); err != nil {
-			return nil, err
-		}
+	for _, c := range describe {
 		// these column types cannot be specified in INSERT queries
-		if defaultType == "MATERIALIZED" || defaultType == "ALIAS" {
+		if c.DefaultKind == "MATERIALIZED" || c.DefaultKind == "ALIAS" {
 			continue
 		}
 
-		columnsToTypes[colName] = colType
-		allColumns = append(allColumns, colName)
+		columnsToTypes[c.Name] = c.Type
+		allColumns = append(allColumns, c.Name)
 	}
 
 	// The order of the columns must match the INSERT list, or the DESC table if no insert list was provided
@@ -66,7 +61,7 @@ func fetchColumnNamesAndTypesForInsert(h *httpConnect, release nativeTransportRe
 		for _, colName := range requestedColumnNames {
 			colType, ok := columnsToTypes[colName]
 			if !ok {
-				return nil, fmt.Errorf("column %s is not present in the table %s", colName, tableName)
+				return nil, nil, fmt.Errorf("column %s is not present in the table %s", colName, tableName)
 			}
 
 			insertColumns = append(insertColumns, ColumnNameAndType{
@@ -85,66 +80,89 @@ func fetchColumnNamesAndTypesForInsert(h *httpConnect, release nativeTransportRe
 		}
 	}
 
-	return insertColumns, nil
+	return insertColumns, describe, nil
 }
 
-func newBlock(h *httpConnect, release nativeTransportRelease, ctx context.Context, query string) (string, *proto.Block, error) {
+func newBlock(h *httpConnect, release nativeTransportRelease, ctx context.Context, query string) (string, string, *proto.Block, []TableColumn, error) {
 	normalizedQuery, tableName, requestedColumnNames, err := extractNormalizedInsertQueryAndColumns(query)
 	if err != nil {
-		return "", nil, err
+		return "", "", nil, nil, err
 	}
 
-	opt := queryOptions(ctx)
-	columns := opt.columnNamesAndTypes
+	var (
+		opt          = queryOptions(ctx)
+		columns      = opt.columnNamesAndTypes
+		tableColumns []TableColumn
+	)
 
 	// If the user didn't supply known column names/types, do expensive DESC TABLE logic
 	if opt.columnNamesAndTypes == nil {
-		fetchedColumns, err := fetchColumnNamesAndTypesForInsert(h, release, ctx, tableName, requestedColumnNames)
+		fetchedColumns, describe, err := fetchColumnNamesAndTypesForInsert(h, release, ctx, tableName, requestedColumnNames)
 		if err != nil {
-			return "", nil, fmt.Errorf("failed to determine columns for HTTP insert: %w", err)
+			return "", "", nil, nil, fmt.Errorf("failed to determine columns for HTTP insert: %w", err)
 		}
-		columns = fetchedColumns
+		columns, tableColumns = fetchedColumns, describe
 	}
 
 	var block proto.Block
 	for _, col := range columns {
 		if err := block.AddColumn(col.Name, column.Type(col.Type)); err != nil {
-			return "", nil, err
+			return "", "", nil, nil, err
 		}
 	}
 
-	return normalizedQuery, &block, nil
+	return normalizedQuery, tableName, &block, tableColumns, nil
 }
 
 func (h *httpConnect) prepareBatch(ctx context.Context, release nativeTransportRelease, acquire nativeTransportAcquire, query string, opts driver.PrepareBatchOptions) (driver.Batch, error) {
+	options := queryOptions(ctx)
+	if _, err := h.format(&options); err != nil {
+		release(h, err)
+		return nil, err
+	}
 	// release is not used within newBlock since the connection is held for the batch.
-	query, block, err := newBlock(h, func(nativeTransport, error) {}, ctx, query)
+	query, tableName, block, tableColumns, err := newBlock(h, func(nativeTransport, error) {}, ctx, query)
 	if err != nil {
 		err = fmt.Errorf("failed to init block for HTTP batch: %w", err)
 		release(h, err)
 		return nil, err
 	}
 
+	deduplicationToken := batchDeduplicationToken(&options, opts)
+
 	return &httpBatch{
-		ctx:         ctx,
-		conn:        h,
-		connRelease: release,
-		structMap:   &structMap{},
-		block:       block,
-		query:       query,
+		ctx:                ctx,
+		conn:               h,
+		connRelease:        release,
+		connAcquire:        acquire,
+		structMap:          newStructMap(h.opt),
+		block:              block,
+		query:              query,
+		tableName:          tableName,
+		tableColumns:       tableColumns,
+		omitDefaults:       opts.OmitDefaults,
+		replayBuffer:       opts.ReplayBuffer,
+		deduplicationToken: deduplicationToken,
 	}, nil
 }
 
 type httpBatch struct {
-	query       string
-	err         error
-	ctx         context.Context
-	conn        *httpConnect
-	released    bool
-	connRelease nativeTransportRelease
-	structMap   *structMap
-	sent        bool
-	block       *proto.Block
+	query              string
+	tableName          string
+	tableColumns       []TableColumn
+	omitDefaults       bool // omitDefaults signalize that the first appended row may leave out columns with defaults
+	err                error
+	ctx                context.Context
+	conn               *httpConnect
+	released           bool
+	connRelease        nativeTransportRelease
+	connAcquire        nativeTransportAcquire
+	structMap          *structMap
+	sent               bool
+	replayBuffer       bool // replayBuffer signalize that a failed Send keeps the batch open for retrying
+	deduplicationToken string
+	block              *proto.Block
+	checker            blockChecker
 }
 
 func (b *httpBatch) release(err error) {
@@ -190,6 +208,16 @@ func (b *httpBatch) Append(v ...any) error {
 		return b.err
 	}
 
+	if b.canOmitDefaults() {
+		columns := b.block.ColumnsNames()
+		if omitted := omittedDefaultColumns(columns, b.tableColumns, defaultColumnsProvider(columns, b.tableColumns, len(v))); len(omitted) != 0 {
+			if err := b.omitColumns(omitted); err != nil {
+				return err
+			}
+			v = omitColumns(columns, v, omitted)
+		}
+	}
+
 	if err := b.block.Append(v...); err != nil {
 		b.err = fmt.Errorf("%w: %w", ErrBatchInvalid, err)
 		b.release(err)
@@ -203,13 +231,73 @@ func (b *httpBatch) AppendStruct(v any) error {
 	if b.err != nil {
 		return b.err
 	}
-	values, err := b.structMap.Map("AppendStruct", b.block.ColumnsNames(), v, false)
+	if b.canOmitDefaults() {
+		if index, ok := b.structMap.indexOf(v); ok {
+			if omitted := omittedDefaultColumns(b.block.ColumnsNames(), b.tableColumns, func(_ int, name string) bool {
+				_, found := index[name]
+				return found
+			}); len(omitted) != 0 {
+				if err := b.omitColumns(omitted); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if a, ok := v.(driver.StructAppender); ok {
+		if b.sent {
+			return ErrBatchAlreadySent
+		}
+		if err := a.AppendTo(b.block.Columns); err != nil {
+			b.err = fmt.Errorf("%w: %w", ErrBatchInvalid, err)
+			b.release(err)
+			return err
+		}
+		return nil
+	}
+	values, err := b.structMap.appendValues(b.block, v)
 	if err != nil {
 		return err
 	}
 	return b.Append(values...)
 }
 
+func (b *httpBatch) checkAppend(v ...any) error {
+	return b.checker.check(b.block, func(block *proto.Block) error {
+		return block.Append(v...)
+	})
+}
+
+func (b *httpBatch) checkAppendStruct(v any) error {
+	return b.checker.check(b.block, func(block *proto.Block) error {
+		return appendStructToBlock(block, b.structMap, v)
+	})
+}
+
+// canOmitDefaults reports whether the columns of the batch may still be narrowed
+func (b *httpBatch) canOmitDefaults() bool {
+	return b.omitDefaults && b.tableColumns != nil && !b.sent && b.block.Rows() == 0
+}
+
+// omitColumns drops the omitted columns from the block and the INSERT, the server fills them with their defaults
+func (b *httpBatch) omitColumns(omitted []string) error {
+	b.omitDefaults = false
+	var (
+		block   proto.Block
+		columns []string
+	)
+	for _, c := range b.block.Columns {
+		if slices.Contains(omitted, c.Name()) {
+			continue
+		}
+		if err := block.AddColumn(c.Name(), c.Type()); err != nil {
+			return err
+		}
+		columns = append(columns, c.Name())
+	}
+	b.block, b.query = &block, insertQueryWithColumns(b.tableName, columns)
+	return nil
+}
+
 func (b *httpBatch) Column(idx int) driver.BatchColumn {
 	if len(b.block.Columns) <= idx {
 		return &batchColumn{
@@ -232,8 +320,23 @@ func (b *httpBatch) IsSent() bool {
 	return b.sent
 }
 
+func (b *httpBatch) resetConnection() error {
+	conn, err := b.connAcquire(b.ctx)
+	if err != nil {
+		return err
+	}
+	b.conn = conn.(*httpConnect)
+	b.released = false
+	return nil
+}
+
 func (b *httpBatch) Send() (err error) {
 	defer func() {
+		if err != nil && b.replayBuffer && b.err == nil {
+			// keep the batch open, the next Send acquires a new connection
+			b.release(err)
+			return
+		}
 		b.sent = true
 		b.release(err)
 	}()
@@ -246,8 +349,16 @@ func (b *httpBatch) Send() (err error) {
 	if b.block.Rows() == 0 {
 		return nil
 	}
+	if b.released {
+		if err = b.resetConnection(); err != nil {
+			return err
+		}
+	}
 
 	options := queryOptions(b.ctx)
+	if b.deduplicationToken != "" {
+		options.settings["insert_deduplication_token"] = b.deduplicationToken
+	}
 	headers := make(map[string]string)
 	switch b.conn.compression {
 	case CompressionGZIP, CompressionDeflate, CompressionBrotli:
@@ -257,6 +368,10 @@ func (b *httpBatch) Send() (err error) {
 		options.settings["compress"] = "1"
 	}
 
+	format, err := b.conn.format(&options)
+	if err != nil {
+		return err
+	}
 	compressionWriter := b.conn.compressionPool.Get()
 	defer b.conn.compressionPool.Put(compressionWriter)
 	pipeReader, pipeWriter := io.Pipe()
@@ -267,7 +382,7 @@ func (b *httpBatch) Send() (err error) {
 		defer pipeWriter.CloseWithError(err)
 		defer connWriter.Close()
 		b.conn.buffer.Reset()
-		if err = b.conn.writeData(b.block); err != nil {
+		if err = b.conn.writeData(b.block, format); err != nil {
 			return
 		}
 		if _, err = connWriter.Write(b.conn.buffer.Buf); err != nil {
@@ -275,7 +390,7 @@ func (b *httpBatch) Send() (err error) {
 		}
 	}()
 
-	options.settings["query"] = b.query
+	options.settings["query"] = withInsertFormat(b.query, format)
 	headers["Content-Type"] = "application/octet-stream"
 
 	b.conn.debugf("[batch send start] columns=%d rows=%d", len(b.block.Columns), b.block.Rows())
@@ -291,6 +406,14 @@ func (b *httpBatch) Send() (err error) {
 	return nil
 }
 
+// withInsertFormat returns the INSERT query of a batch with its data in format rather than Native
+func withInsertFormat(query string, format HTTPFormat) string {
+	if format == HTTPFormatNative {
+		return query
+	}
+	return strings.TrimSuffix(query, " FORMAT Native") + " FORMAT " + string(format)
+}
+
 func (b *httpBatch) Rows() int {
 	return b.block.Rows()
 }
@@ -299,4 +422,8 @@ func (b *httpBatch) Columns() []column.Interface {
 	return slices.Clone(b.block.Columns)
 }
 
-var _ driver.Batch = (*httpBatch)(nil)
+func (b *httpBatch) TableColumns() []TableColumn {
+	return slices.Clone(b.tableColumns)
+}
+
+var _ driver.SchemaBatch = (*httpBatch)(nil)
@@ -0,0 +1,102 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// insertFromChunkSize is the size of the chunks compressed with LZ4/ZSTD framing
+const insertFromChunkSize = 1 << 20
+
+func (h *httpConnect) insertFrom(ctx context.Context, query string, r io.Reader) error {
+	if _, err := insertQueryFormat(query); err != nil {
+		return err
+	}
+
+	options := queryOptions(ctx)
+	headers := make(map[string]string)
+	switch h.compression {
+	case CompressionGZIP, CompressionDeflate, CompressionBrotli:
+		headers["Content-Encoding"] = h.compression.String()
+	case CompressionZSTD, CompressionLZ4:
+		options.settings["decompress"] = "1"
+		options.settings["compress"] = "1"
+	}
+
+	compressionWriter := h.compressionPool.Get()
+	defer h.compressionPool.Put(compressionWriter)
+	pipeReader, pipeWriter := io.Pipe()
+	connWriter := compressionWriter.reset(pipeWriter)
+
+	go func() {
+		var err error
+		defer func() {
+			if cErr := connWriter.Close(); err == nil {
+				err = cErr
+			}
+			pipeWriter.CloseWithError(err)
+		}()
+		switch h.compression {
+		case CompressionZSTD, CompressionLZ4:
+			err = h.writeCompressedChunks(connWriter, r)
+		default:
+			_, err = io.Copy(connWriter, r)
+		}
+	}()
+
+	options.settings["query"] = query
+	headers["Content-Type"] = "application/octet-stream"
+
+	h.debugf("[insert from] start")
+	res, err := h.sendStreamQuery(ctx, pipeReader, &options, headers)
+	if err != nil {
+		// unblock the writer goroutine if the request failed before the body was consumed
+		pipeReader.CloseWithError(err)
+		return fmt.Errorf("insert from sendStreamQuery: %w", err)
+	}
+	discardAndClose(res.Body)
+	h.debugf("[insert from] complete")
+
+	return nil
+}
+
+// writeCompressedChunks writes r to w as ClickHouse compressed blocks, as expected by the decompress=1 setting.
+func (h *httpConnect) writeCompressedChunks(w io.Writer, r io.Reader) error {
+	buf := make([]byte, insertFromChunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if cErr := h.blockCompressor.Compress(buf[:n]); cErr != nil {
+				return fmt.Errorf("compress: %w", cErr)
+			}
+			if _, wErr := w.Write(h.blockCompressor.Data); wErr != nil {
+				return wErr
+			}
+		}
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			return nil
+		default:
+			return err
+		}
+	}
+}
@@ -23,7 +23,6 @@ import (
 	"fmt"
 	"io"
 
-	chproto "github.com/ClickHouse/ch-go/proto"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
 )
 
@@ -31,7 +30,12 @@ import (
 func (h *httpConnect) query(ctx context.Context, release nativeTransportRelease, query string, args ...any) (*rows, error) {
 	h.debugf("[http query] \"%s\"", query)
 	options := queryOptions(ctx)
-	query, err := bindQueryOrAppendParameters(true, &options, query, h.handshake.Timezone, args...)
+	format, err := h.format(&options)
+	if err != nil {
+		release(h, err)
+		return nil, err
+	}
+	query, err = bindQueryOrAppendParameters(true, &options, query, h.handshake.Timezone, args...)
 	if err != nil {
 		err = fmt.Errorf("bindQueryOrAppendParameters: %w", err)
 		release(h, err)
@@ -60,7 +64,7 @@ func (h *httpConnect) query(ctx context.Context, release nativeTransportRelease,
 		return &rows{
 			block:     block,
 			columns:   block.ColumnsNames(),
-			structMap: &structMap{},
+			structMap: newStructMap(h.opt),
 		}, nil
 	}
 
@@ -78,8 +82,8 @@ func (h *httpConnect) query(ctx context.Context, release nativeTransportRelease,
 		release(h, err)
 		return nil, err
 	}
-	chReader := chproto.NewReader(reader)
-	block, err := h.readData(chReader, options.userLocation)
+	read := h.blockReader(reader, format, options.userLocation)
+	block, err := read()
 	if err != nil && !errors.Is(err, io.EOF) {
 		err = fmt.Errorf("readData: %w", err)
 		discardAndClose(res.Body)
@@ -99,7 +103,7 @@ func (h *httpConnect) query(ctx context.Context, release nativeTransportRelease,
 	)
 	go func() {
 		for {
-			block, err := h.readData(chReader, options.userLocation)
+			block, err := read()
 			if err != nil {
 				// ch-go wraps EOF errors
 				if !errors.Is(err, io.EOF) {
@@ -130,7 +134,7 @@ func (h *httpConnect) query(ctx context.Context, release nativeTransportRelease,
 		stream:    stream,
 		errors:    errCh,
 		columns:   block.ColumnsNames(),
-		structMap: &structMap{},
+		structMap: newStructMap(h.opt),
 	}, nil
 }
 
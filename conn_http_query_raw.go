@@ -0,0 +1,88 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+func (h *httpConnect) queryRaw(ctx context.Context, release nativeTransportRelease, query string, args ...any) (io.ReadCloser, error) {
+	h.debugf("[http query raw] \"%s\"", query)
+	if _, _, err := splitQueryFormat(query); err != nil {
+		release(h, err)
+		return nil, err
+	}
+
+	options := queryOptions(ctx)
+	query, err := bindQueryOrAppendParameters(true, &options, query, h.handshake.Timezone, args...)
+	if err != nil {
+		err = fmt.Errorf("bindQueryOrAppendParameters: %w", err)
+		release(h, err)
+		return nil, err
+	}
+	// LZ4 and ZSTD are ClickHouse block compression and would leak into the raw output, so only HTTP encodings are requested
+	headers := make(map[string]string)
+	switch h.compression {
+	case CompressionGZIP, CompressionDeflate, CompressionBrotli:
+		headers["Accept-Encoding"] = h.compression.String()
+	}
+
+	res, err := h.sendQuery(ctx, query, &options, headers)
+	if err != nil {
+		err = fmt.Errorf("sendQuery: %w", err)
+		release(h, err)
+		return nil, err
+	}
+
+	rw := h.compressionPool.Get()
+	reader, err := rw.NewReader(res)
+	if err != nil {
+		err = fmt.Errorf("NewReader: %w", err)
+		discardAndClose(res.Body)
+		h.compressionPool.Put(rw)
+		release(h, err)
+		return nil, err
+	}
+
+	return &httpRawReader{
+		Reader: reader,
+		close: func() error {
+			// the body is closed without draining, the connection is not reused in that case
+			err := res.Body.Close()
+			h.compressionPool.Put(rw)
+			release(h, nil)
+			return err
+		},
+	}, nil
+}
+
+type httpRawReader struct {
+	io.Reader
+	close  func() error
+	closed bool
+}
+
+func (r *httpRawReader) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	return r.close()
+}
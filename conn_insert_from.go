@@ -0,0 +1,91 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+)
+
+var insertFormatMatch = regexp.MustCompile(`(?is)^\s*INSERT\s+INTO\s+.+\sFORMAT\s+([A-Za-z0-9_]+)\s*$`)
+
+// insertQueryFormat returns the input format of an "INSERT INTO ... FORMAT <name>" query.
+func insertQueryFormat(query string) (string, error) {
+	matches := insertFormatMatch.FindStringSubmatch(query)
+	if len(matches) != 2 {
+		return "", fmt.Errorf("invalid INSERT query, expected INSERT INTO ... FORMAT <name>: %s", query)
+	}
+	return matches[1], nil
+}
+
+func (c *connect) insertFrom(ctx context.Context, query string, r io.Reader) error {
+	format, err := insertQueryFormat(query)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(format, "Native") {
+		// the native protocol only transfers Native blocks, other formats would have to be parsed into
+		// blocks by the client or buffered and sent inline with the query
+		return &OpError{
+			Op:  "InsertFrom",
+			Err: fmt.Errorf("format %s is not supported over the native protocol, use the HTTP protocol or the Native format", format),
+		}
+	}
+
+	options := queryOptions(ctx)
+	onProcess := options.onProcess()
+	if deadline, ok := ctx.Deadline(); ok {
+		c.conn.SetDeadline(deadline)
+		defer c.conn.SetDeadline(time.Time{})
+	}
+
+	if err := c.sendQuery(query, &options); err != nil {
+		return err
+	}
+	if _, err := c.firstBlock(ctx, onProcess); err != nil {
+		return err
+	}
+
+	reader := chproto.NewReader(r)
+	for {
+		// Native format streams carry no block info, decode them as revision 0
+		block := proto.Block{Timezone: c.server.Timezone}
+		if err := block.Decode(reader, 0); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("decode native input: %w", err)
+		}
+		if err := c.sendData(&block, ""); err != nil {
+			return err
+		}
+	}
+
+	if err := c.sendData(&proto.Block{}, ""); err != nil {
+		return err
+	}
+	return c.process(ctx, onProcess)
+}
@@ -0,0 +1,55 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertQueryFormat(t *testing.T) {
+	testCases := []struct {
+		query  string
+		format string
+		err    bool
+	}{
+		{query: "INSERT INTO t FORMAT CSVWithNames", format: "CSVWithNames"},
+		{query: "insert into db.t (a, b) format JSONEachRow\n", format: "JSONEachRow"},
+		{query: "INSERT INTO t\n\tFORMAT Native", format: "Native"},
+		{query: "INSERT INTO t VALUES", err: true},
+		{query: "SELECT 1 FORMAT CSV", err: true},
+	}
+
+	for _, tc := range testCases {
+		format, err := insertQueryFormat(tc.query)
+		if tc.err {
+			assert.Error(t, err, tc.query)
+			continue
+		}
+		assert.NoError(t, err, tc.query)
+		assert.Equal(t, tc.format, format)
+	}
+}
+
+func TestInsertFromUnsupportedFormat(t *testing.T) {
+	err := (&connect{}).insertFrom(context.Background(), "INSERT INTO t FORMAT CSVWithNames", strings.NewReader("a\n1\n"))
+	assert.EqualError(t, err, "clickhouse [InsertFrom]: format CSVWithNames is not supported over the native protocol, use the HTTP protocol or the Native format")
+}
@@ -19,21 +19,11 @@ package clickhouse
 
 import (
 	"context"
-	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
 )
 
-type Log struct {
-	Time      time.Time
-	TimeMicro uint32
-	Hostname  string
-	QueryID   string
-	ThreadID  uint64
-	Priority  int8
-	Source    string
-	Text      string
-}
+type Log = proto.Log
 
 func (c *connect) logs(ctx context.Context) ([]Log, error) {
 	block, err := c.readData(ctx, proto.ServerLog, false)
@@ -38,6 +38,7 @@ func (c *connect) ping(ctx context.Context) (err error) {
 	}
 	c.debugf("[ping] -> ping")
 	c.buffer.PutByte(proto.ClientPing)
+	c.endPacket()
 	if err := c.flush(); err != nil {
 		return err
 	}
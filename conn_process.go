@@ -24,6 +24,7 @@ import (
 	"io"
 
 	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/timezone"
 )
 
 type onProcess struct {
@@ -32,6 +33,7 @@ type onProcess struct {
 	progress      func(*Progress)
 	profileInfo   func(*ProfileInfo)
 	profileEvents func([]ProfileEvent)
+	tableColumns  func([]TableColumn)
 }
 
 func (c *connect) firstBlock(ctx context.Context, on *onProcess) (*proto.Block, error) {
@@ -193,7 +195,16 @@ func (c *connect) handle(ctx context.Context, packet byte, on *onProcess) error
 		if err := info.Decode(c.reader, c.revision); err != nil {
 			return err
 		}
-		c.debugf("[table columns]")
+		c.debugf("[table columns] table=%s", info.First)
+		if on.tableColumns != nil {
+			columns, err := parseTableColumns(info.Second)
+			if err != nil {
+				// the description only enables optional features, the insert itself can go on
+				c.debugf("[table columns] %v", err)
+				break
+			}
+			on.tableColumns(columns)
+		}
 	case proto.ServerProfileEvents:
 		events, err := c.profileEvents(ctx)
 		if err != nil {
@@ -213,6 +224,39 @@ func (c *connect) handle(ctx context.Context, packet byte, on *onProcess) error
 		}
 		c.debugf("[progress] %s", progress)
 		on.progress(progress)
+	case proto.ServerTimezoneUpdate:
+		name, err := c.reader.Str()
+		if err != nil {
+			return err
+		}
+		// an empty timezone is the timezone of the server
+		c.queryTimezone = nil
+		if name != "" {
+			if c.queryTimezone, err = timezone.Load(name); err != nil {
+				return err
+			}
+		}
+		c.debugf("[timezone update] %q", name)
+	case proto.ServerPartUUIDs:
+		var uuids proto.PartUUIDs
+		if err := uuids.Decode(c.reader); err != nil {
+			return err
+		}
+		c.debugf("[part uuids] %d parts", len(uuids))
+	case proto.ServerReadTaskRequest:
+		// the client has no read tasks to hand out, e.g. for s3Cluster
+		c.debugf("[read task request] -> no task")
+		c.buffer.PutByte(proto.ClientReadTaskResponse)
+		(&proto.ReadTaskResponse{}).Encode(c.buffer)
+		c.endPacket()
+		if err := c.flush(); err != nil {
+			return err
+		}
+	case proto.ServerMergeTreeAllRangesAnnouncement, proto.ServerMergeTreeReadTaskRequest:
+		return &OpError{
+			Op:  "process",
+			Err: fmt.Errorf("parallel replicas packet %d: the client does not coordinate parallel replicas", packet),
+		}
 	default:
 		return &OpError{
 			Op:  "process",
@@ -225,6 +269,7 @@ func (c *connect) handle(ctx context.Context, packet byte, on *onProcess) error
 func (c *connect) cancel() error {
 	c.debugf("[cancel]")
 	c.buffer.PutUVarInt(proto.ClientCancel)
+	c.endPacket()
 	wErr := c.flush()
 	// don't reuse a cancelled query as we don't drain the connection
 	if cErr := c.close(); cErr != nil {
@@ -0,0 +1,124 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	chproto "github.com/ClickHouse/ch-go/proto"
+)
+
+var queryFormatMatch = regexp.MustCompile(`(?is)^(.*\S)\s+FORMAT\s+([A-Za-z0-9_]+)\s*;?\s*$`)
+
+// splitQueryFormat splits a "... FORMAT <name>" query into the query without the FORMAT clause and the format name.
+func splitQueryFormat(query string) (string, string, error) {
+	matches := queryFormatMatch.FindStringSubmatch(query)
+	if len(matches) != 3 {
+		return "", "", fmt.Errorf("query must end with a FORMAT clause: %s", query)
+	}
+	return matches[1], matches[2], nil
+}
+
+// formatRowFormats are the formats whose output is the concatenation of the formatRow output of each row,
+// as they have no prefix, suffix, header or row delimiter. Keys are lower case.
+var formatRowFormats = map[string]bool{
+	"tabseparated":              true,
+	"tsv":                       true,
+	"tabseparatedraw":           true,
+	"tsvraw":                    true,
+	"raw":                       true,
+	"csv":                       true,
+	"tskv":                      true,
+	"jsoneachrow":               true,
+	"jsonlines":                 true,
+	"ndjson":                    true,
+	"jsonstringseachrow":        true,
+	"jsoncompacteachrow":        true,
+	"jsoncompactstringseachrow": true,
+	"rowbinary":                 true,
+}
+
+// queryRaw emulates raw format output over the native protocol, where the server always returns Native blocks.
+// Native output is re-encoded from the received blocks. Row formats without a prefix, suffix or header are
+// produced by the server with formatRow. Other formats, such as CSVWithNames or Parquet, are rejected before
+// the query is sent as they cannot be emulated; they are available with the HTTP protocol.
+func (c *connect) queryRaw(ctx context.Context, release nativeTransportRelease, query string, args ...any) (io.ReadCloser, error) {
+	body, format, err := splitQueryFormat(query)
+	if err != nil {
+		release(c, err)
+		return nil, err
+	}
+
+	native := strings.EqualFold(format, "Native")
+	if !native && !formatRowFormats[strings.ToLower(format)] {
+		release(c, nil)
+		return nil, &OpError{
+			Op:  "QueryRaw",
+			Err: fmt.Errorf("format %s is not supported over the native protocol, use the HTTP protocol", format),
+		}
+	}
+	if !native {
+		body = fmt.Sprintf("SELECT formatRow('%s', *) FROM (%s)", format, body)
+	}
+
+	// the query is cancelled if the reader is closed before the whole result was read
+	ctx, cancel := context.WithCancel(ctx)
+	r, err := c.query(ctx, release, body, args...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		var (
+			err    error
+			buffer chproto.Buffer
+		)
+		for r.NextBlock() {
+			buffer.Reset()
+			switch {
+			case native:
+				// Native format output carries no block info, encode as revision 0
+				err = r.block.Encode(&buffer, 0)
+			default:
+				for i := 0; i < r.block.Rows(); i++ {
+					buffer.Buf = append(buffer.Buf, r.block.Columns[0].Row(i, false).(string)...)
+				}
+			}
+			if err != nil {
+				break
+			}
+			if _, err = pipeWriter.Write(buffer.Buf); err != nil {
+				cancel()
+				break
+			}
+		}
+		if cErr := r.Close(); err == nil {
+			err = cErr
+		}
+		cancel()
+		pipeWriter.CloseWithError(err)
+	}()
+
+	return pipeReader, nil
+}
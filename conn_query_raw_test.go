@@ -0,0 +1,63 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitQueryFormat(t *testing.T) {
+	testCases := []struct {
+		query  string
+		body   string
+		format string
+		err    bool
+	}{
+		{query: "SELECT 1 FORMAT CSV", body: "SELECT 1", format: "CSV"},
+		{query: "SELECT * FROM t WHERE a = 'FORMAT'\nFORMAT  JSONEachRow ;\n", body: "SELECT * FROM t WHERE a = 'FORMAT'", format: "JSONEachRow"},
+		{query: "select number from numbers(10) format Parquet", body: "select number from numbers(10)", format: "Parquet"},
+		{query: "SELECT 1", err: true},
+		{query: "FORMAT CSV", err: true},
+	}
+
+	for _, tc := range testCases {
+		body, format, err := splitQueryFormat(tc.query)
+		if tc.err {
+			assert.Error(t, err, tc.query)
+			continue
+		}
+		assert.NoError(t, err, tc.query)
+		assert.Equal(t, tc.body, body)
+		assert.Equal(t, tc.format, format)
+	}
+}
+
+func TestQueryRawUnsupportedFormat(t *testing.T) {
+	var released []error
+	release := func(_ nativeTransport, err error) {
+		released = append(released, err)
+	}
+	for _, format := range []string{"CSVWithNames", "TSVWithNamesAndTypes", "Parquet", "Arrow", "ORC", "JSON", "Values"} {
+		_, err := (&connect{}).queryRaw(context.Background(), release, "SELECT 1 FORMAT "+format)
+		assert.EqualError(t, err, "clickhouse [QueryRaw]: format "+format+" is not supported over the native protocol, use the HTTP protocol")
+	}
+	assert.Equal(t, make([]error, 7), released)
+}
@@ -25,6 +25,7 @@ import (
 // https://github.com/ClickHouse/ClickHouse/blob/master/src/Client/Connection.cpp
 func (c *connect) sendQuery(body string, o *QueryOptions) error {
 	c.debugf("[send query] compression=%q %s", c.compression, body)
+	c.queryTimezone = nil
 	c.buffer.PutByte(proto.ClientQuery)
 	q := proto.Query{
 		ClientTCPProtocolVersion: ClientTCPProtocolVersion,
@@ -42,6 +43,7 @@ func (c *connect) sendQuery(body string, o *QueryOptions) error {
 	if err := q.Encode(c.buffer, c.revision); err != nil {
 		return err
 	}
+	c.endPacket()
 	for _, table := range o.external {
 		if err := c.sendData(table.Block(), table.Name()); err != nil {
 			return err
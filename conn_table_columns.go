@@ -0,0 +1,186 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// parseTableColumns parses the column descriptions of a ServerTableColumns packet:
+//
+//	columns format version: 1
+//	2 columns:
+//	`id` UInt64
+//	`ts` DateTime	DEFAULT	now()	COMMENT 'time'	CODEC(Delta(4), ZSTD(1))	TTL ts + toIntervalDay(1)
+func parseTableColumns(desc string) ([]TableColumn, error) {
+	lines := strings.Split(strings.TrimRight(desc, "\n"), "\n")
+	if len(lines) < 2 || !strings.HasPrefix(lines[0], "columns format version: 1") {
+		return nil, fmt.Errorf("unsupported table columns description: %q", desc)
+	}
+	var n int
+	if _, err := fmt.Sscanf(lines[1], "%d columns:", &n); err != nil {
+		return nil, fmt.Errorf("invalid table columns description: %w", err)
+	}
+	if lines = lines[2:]; len(lines) != n {
+		return nil, fmt.Errorf("invalid table columns description: expected %d columns, got %d", n, len(lines))
+	}
+	columns := make([]TableColumn, 0, n)
+	for _, line := range lines {
+		if len(line) == 0 || line[0] != '`' {
+			return nil, fmt.Errorf("invalid column description: %q", line)
+		}
+		var (
+			name strings.Builder
+			i    = 1
+		)
+		for ; i < len(line) && line[i] != '`'; i++ {
+			if line[i] == '\\' && i+1 < len(line) {
+				i++
+			}
+			name.WriteByte(line[i])
+		}
+		if i+1 >= len(line) || line[i+1] != ' ' {
+			return nil, fmt.Errorf("invalid column description: %q", line)
+		}
+		var (
+			parts = strings.Split(line[i+2:], "\t")
+			c     = TableColumn{
+				Name: name.String(),
+				Type: unescapeTableColumns(parts[0]),
+			}
+		)
+		for j := 1; j < len(parts); j++ {
+			switch part := unescapeTableColumns(parts[j]); {
+			case part == "DEFAULT" || part == "MATERIALIZED" || part == "ALIAS" || part == "EPHEMERAL":
+				c.DefaultKind = part
+				if j+1 < len(parts) {
+					j++
+					c.DefaultExpression = unescapeTableColumns(parts[j])
+				}
+			case strings.HasPrefix(part, "COMMENT "):
+				comment := strings.TrimPrefix(part, "COMMENT ")
+				c.Comment = unescapeTableColumns(strings.TrimSuffix(strings.TrimPrefix(comment, "'"), "'"))
+			case strings.HasPrefix(part, "CODEC("):
+				c.CodecExpression = strings.TrimSuffix(strings.TrimPrefix(part, "CODEC("), ")")
+			case strings.HasPrefix(part, "TTL "):
+				c.TTLExpression = strings.TrimPrefix(part, "TTL ")
+			}
+		}
+		columns = append(columns, c)
+	}
+	return columns, nil
+}
+
+// unescapeTableColumns reverses the backslash escaping of column descriptions
+func unescapeTableColumns(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '0':
+			b.WriteByte(0)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// omittedDefaultColumns returns the columns without a value, as reported by provided, if all of
+// them have a DEFAULT or EPHEMERAL expression in tableColumns. Otherwise it returns nil.
+func omittedDefaultColumns(columns []string, tableColumns []TableColumn, provided func(i int, name string) bool) []string {
+	var omitted []string
+	for i, name := range columns {
+		if provided(i, name) {
+			continue
+		}
+		idx := slices.IndexFunc(tableColumns, func(c TableColumn) bool {
+			return c.Name == name
+		})
+		if idx == -1 || !hasDefault(tableColumns[idx]) {
+			return nil
+		}
+		omitted = append(omitted, name)
+	}
+	return omitted
+}
+
+func hasDefault(c TableColumn) bool {
+	return c.DefaultKind == "DEFAULT" || c.DefaultKind == "EPHEMERAL"
+}
+
+// defaultColumnsProvider reports the columns provided by an Append call with n values:
+// when n matches the number of columns without a default, the columns with one are omitted
+func defaultColumnsProvider(columns []string, tableColumns []TableColumn, n int) func(int, string) bool {
+	defaults := make(map[string]bool)
+	for _, c := range tableColumns {
+		if hasDefault(c) && slices.Contains(columns, c.Name) {
+			defaults[c.Name] = true
+		}
+	}
+	if n != len(columns)-len(defaults) {
+		return func(int, string) bool {
+			return true
+		}
+	}
+	return func(_ int, name string) bool {
+		return !defaults[name]
+	}
+}
+
+// insertQueryWithColumns builds an INSERT query into table with an explicit column list
+func insertQueryWithColumns(table string, columns []string) string {
+	quoted := make([]string, 0, len(columns))
+	for _, name := range columns {
+		quoted = append(quoted, quoteIdentifier(name))
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) FORMAT Native", table, strings.Join(quoted, ", "))
+}
+
+// omitColumns returns values without the values of the omitted columns
+func omitColumns(columns []string, values []any, omitted []string) []any {
+	if len(values) != len(columns) {
+		return values
+	}
+	kept := make([]any, 0, len(values)-len(omitted))
+	for i, name := range columns {
+		if !slices.Contains(omitted, name) {
+			kept = append(kept, values[i])
+		}
+	}
+	return kept
+}
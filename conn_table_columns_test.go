@@ -0,0 +1,75 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTableColumns(t *testing.T) {
+	desc := "columns format version: 1\n" +
+		"4 columns:\n" +
+		"`id` UInt64\n" +
+		"`ts` DateTime\tDEFAULT\tnow()\tCOMMENT 'creation \\'time\\''\tCODEC(Delta(4), ZSTD(1))\n" +
+		"`a\\`b` String\tMATERIALIZED\tlower(\\'X\\')\n" +
+		"`raw` String\tEPHEMERAL\t\\'\\'\tTTL ts + toIntervalDay(1)\n"
+	columns, err := parseTableColumns(desc)
+	require.NoError(t, err)
+	assert.Equal(t, []TableColumn{
+		{Name: "id", Type: "UInt64"},
+		{Name: "ts", Type: "DateTime", DefaultKind: "DEFAULT", DefaultExpression: "now()", Comment: "creation 'time'", CodecExpression: "Delta(4), ZSTD(1)"},
+		{Name: "a`b", Type: "String", DefaultKind: "MATERIALIZED", DefaultExpression: "lower('X')"},
+		{Name: "raw", Type: "String", DefaultKind: "EPHEMERAL", DefaultExpression: "''", TTLExpression: "ts + toIntervalDay(1)"},
+	}, columns)
+
+	for _, desc := range []string{
+		"",
+		"columns format version: 2\n0 columns:\n",
+		"columns format version: 1\n2 columns:\n`id` UInt64\n",
+		"columns format version: 1\n1 columns:\nid UInt64\n",
+	} {
+		_, err := parseTableColumns(desc)
+		assert.Error(t, err, desc)
+	}
+}
+
+func TestOmittedDefaultColumns(t *testing.T) {
+	var (
+		columns      = []string{"id", "ts", "note", "raw"}
+		tableColumns = []TableColumn{
+			{Name: "id", Type: "UInt64"},
+			{Name: "ts", Type: "DateTime", DefaultKind: "DEFAULT", DefaultExpression: "now()"},
+			{Name: "note", Type: "String"},
+			{Name: "raw", Type: "String", DefaultKind: "EPHEMERAL"},
+		}
+	)
+	assert.Equal(t, []string{"ts", "raw"}, omittedDefaultColumns(columns, tableColumns, defaultColumnsProvider(columns, tableColumns, 2)))
+	assert.Nil(t, omittedDefaultColumns(columns, tableColumns, defaultColumnsProvider(columns, tableColumns, 4)))
+	assert.Nil(t, omittedDefaultColumns(columns, tableColumns, defaultColumnsProvider(columns, tableColumns, 3)))
+
+	provided := func(_ int, name string) bool { return name != "note" }
+	assert.Nil(t, omittedDefaultColumns(columns, tableColumns, provided), "note has no default")
+	provided = func(_ int, name string) bool { return name == "id" || name == "note" }
+	assert.Equal(t, []string{"ts", "raw"}, omittedDefaultColumns(columns, tableColumns, provided))
+
+	assert.Equal(t, []any{1, "x"}, omitColumns(columns, []any{1, "t", "x", "r"}, []string{"ts", "raw"}))
+	assert.Equal(t, "INSERT INTO db.t (`id`, `note`) FORMAT Native", insertQueryWithColumns("db.t", []string{"id", "note"}))
+}
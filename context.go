@@ -73,6 +73,7 @@ type (
 		blockBufferSize     uint8
 		userLocation        *time.Location
 		columnNamesAndTypes []ColumnNameAndType
+		httpFormat          HTTPFormat
 	}
 )
 
@@ -187,6 +188,15 @@ func WithUserLocation(location *time.Location) QueryOption {
 	}
 }
 
+// WithHTTPFormat overrides Options.HttpFormat, the format of the data of the query over HTTP.
+// It is ignored by the native protocol. An unknown format fails the HTTP queries and batches it is used with.
+func WithHTTPFormat(format HTTPFormat) QueryOption {
+	return func(o *QueryOptions) error {
+		o.httpFormat = format
+		return nil
+	}
+}
+
 func ignoreExternalTables() QueryOption {
 	return func(o *QueryOptions) error {
 		o.external = nil
@@ -309,6 +319,7 @@ func (q *QueryOptions) clone() QueryOptions {
 		blockBufferSize:     q.blockBufferSize,
 		userLocation:        q.userLocation,
 		columnNamesAndTypes: nil,
+		httpFormat:          q.httpFormat,
 	}
 
 	if q.settings != nil {
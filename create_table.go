@@ -0,0 +1,220 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+)
+
+// CreateTableOptions configures the statement built by CreateTableSQL.
+type CreateTableOptions struct {
+	Table       string // required, may be qualified with a database, e.g. db.events
+	IfNotExists bool
+	OnCluster   string
+	Engine      string   // default MergeTree
+	OrderBy     []string // columns or expressions, default tuple() for MergeTree engines
+	PartitionBy string
+	PrimaryKey  []string
+	TTL         string
+	// Codecs maps column names to codecs, e.g. {"ts": "Delta, ZSTD(1)"}.
+	Codecs map[string]string
+	// NameMapper maps fields without a ch tag to column names, as Options.NameMapper does.
+	NameMapper NameMapper
+}
+
+// createTableScalarTypes are the ClickHouse types CreateTableSQL maps Go types to. When several
+// types scan into the same Go type the first one is used.
+var createTableScalarTypes = []string{
+	"Int8", "Int16", "Int32", "Int64", "Int128",
+	"UInt8", "UInt16", "UInt32", "UInt64",
+	"Float32", "Float64",
+	"String", "Bool", "UUID", "DateTime", "IPv6",
+}
+
+var (
+	createTableTypesOnce sync.Once
+	createTableTypes     map[reflect.Type]string
+	createTableKinds     map[reflect.Kind]string
+)
+
+func initCreateTableTypes() {
+	createTableTypes = map[reflect.Type]string{
+		reflect.TypeFor[int]():    "Int64",
+		reflect.TypeFor[uint]():   "UInt64",
+		reflect.TypeFor[[]byte](): "String",
+	}
+	createTableKinds = map[reflect.Kind]string{
+		reflect.Int:  "Int64",
+		reflect.Uint: "UInt64",
+	}
+	for _, chType := range createTableScalarTypes {
+		col, err := column.Type(chType).Column("", time.UTC)
+		if err != nil {
+			panic(err)
+		}
+		t := col.ScanType()
+		if _, found := createTableTypes[t]; !found {
+			createTableTypes[t] = chType
+		}
+		if _, found := createTableKinds[t.Kind()]; !found && len(t.PkgPath()) == 0 {
+			createTableKinds[t.Kind()] = chType
+		}
+	}
+}
+
+// CreateTableSQL builds a CREATE TABLE statement with a column for every field of struct T.
+// Fields are mapped to columns as in AppendStruct, and Go types to the ClickHouse types the
+// driver scans into them, pointers become Nullable. Use the type option of the ch tag for
+// any other type, it must be the last option: `ch:"ts,type=DateTime64(3, 'UTC')"`.
+func CreateTableSQL[T any](opts CreateTableOptions) (string, error) {
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return "", fmt.Errorf("clickhouse [CreateTableSQL]: expects a struct type, got %s", t)
+	}
+	if len(opts.Table) == 0 {
+		return "", errors.New("clickhouse [CreateTableSQL]: table name is required")
+	}
+	createTableTypesOnce.Do(initCreateTableTypes)
+
+	var (
+		columns = structColumns(t, opts.NameMapper)
+		seen    = make(map[string]bool, len(columns))
+		defs    = make([]string, 0, len(columns))
+	)
+	for _, c := range columns {
+		if seen[c.name] {
+			return "", fmt.Errorf("clickhouse [CreateTableSQL]: duplicate column %q in %s", c.name, t)
+		}
+		seen[c.name] = true
+		chType := c.tag.Type
+		if len(chType) == 0 {
+			var err error
+			if chType, err = createTableType(c.field.Type); err != nil {
+				return "", fmt.Errorf("clickhouse [CreateTableSQL]: field %s: %w", c.field.Name, err)
+			}
+		}
+		def := "    " + quoteIdentifier(c.name) + " " + chType
+		if codec, found := opts.Codecs[c.name]; found {
+			def += " CODEC(" + codec + ")"
+		}
+		defs = append(defs, def)
+	}
+	for name := range opts.Codecs {
+		if !seen[name] {
+			return "", fmt.Errorf("clickhouse [CreateTableSQL]: codec for unknown column %q", name)
+		}
+	}
+
+	var sql strings.Builder
+	sql.WriteString("CREATE TABLE ")
+	if opts.IfNotExists {
+		sql.WriteString("IF NOT EXISTS ")
+	}
+	sql.WriteString(opts.Table)
+	if len(opts.OnCluster) != 0 {
+		sql.WriteString(" ON CLUSTER " + opts.OnCluster)
+	}
+	sql.WriteString("\n(\n" + strings.Join(defs, ",\n") + "\n)\n")
+	engine := opts.Engine
+	if len(engine) == 0 {
+		engine = "MergeTree"
+	}
+	sql.WriteString("ENGINE = " + engine)
+	if len(opts.PartitionBy) != 0 {
+		sql.WriteString("\nPARTITION BY " + opts.PartitionBy)
+	}
+	if len(opts.PrimaryKey) != 0 {
+		sql.WriteString("\nPRIMARY KEY " + createTableExprList(opts.PrimaryKey))
+	}
+	switch {
+	case len(opts.OrderBy) != 0:
+		sql.WriteString("\nORDER BY " + createTableExprList(opts.OrderBy))
+	case strings.Contains(engine, "MergeTree"):
+		sql.WriteString("\nORDER BY tuple()")
+	}
+	if len(opts.TTL) != 0 {
+		sql.WriteString("\nTTL " + opts.TTL)
+	}
+	return sql.String(), nil
+}
+
+// createTableType returns the ClickHouse type of Go type t
+func createTableType(t reflect.Type) (string, error) {
+	if chType, found := createTableTypes[t]; found {
+		return chType, nil
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		chType, err := createTableType(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		if strings.HasPrefix(chType, "Array(") || strings.HasPrefix(chType, "Map(") || strings.HasPrefix(chType, "Nullable(") {
+			return "", fmt.Errorf("%s cannot be inside Nullable", chType)
+		}
+		return "Nullable(" + chType + ")", nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "String", nil
+		}
+		chType, err := createTableType(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return "Array(" + chType + ")", nil
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("FixedString(%d)", t.Len()), nil
+		}
+	case reflect.Map:
+		key, err := createTableType(t.Key())
+		if err != nil {
+			return "", err
+		}
+		value, err := createTableType(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return "Map(" + key + ", " + value + ")", nil
+	}
+	if chType, found := createTableKinds[t.Kind()]; found {
+		return chType, nil
+	}
+	return "", fmt.Errorf("no ClickHouse type for Go type %s, set one with the type option of the ch tag", t)
+}
+
+func createTableExprList(exprs []string) string {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return "(" + strings.Join(exprs, ", ") + ")"
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "\\`") + "`"
+}
@@ -0,0 +1,124 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTableSQL(t *testing.T) {
+	type Address struct {
+		City string
+	}
+	type Base struct {
+		ID uint64 `ch:"id"`
+	}
+	type status string
+	type Event struct {
+		Base
+		Timestamp time.Time `ch:"ts,type=DateTime64(3, 'UTC')"`
+		Name      string
+		Note      *string
+		Tags      []string
+		Attrs     map[string]int32
+		Status    status
+		UUID      uuid.UUID
+		IP        net.IP
+		Big       *big.Int
+		Hash      [4]byte
+		Counter   int
+		Home      Address `ch:",prefix=home_"`
+		Ignored   string  `ch:"-"`
+		internal  string
+	}
+	sql, err := CreateTableSQL[Event](CreateTableOptions{
+		Table:       "db.events",
+		IfNotExists: true,
+		OnCluster:   "cluster",
+		Engine:      "ReplacingMergeTree",
+		OrderBy:     []string{"id", "ts"},
+		PartitionBy: "toYYYYMM(ts)",
+		TTL:         "ts + INTERVAL 30 DAY",
+		Codecs:      map[string]string{"ts": "Delta, ZSTD(1)"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS db.events ON CLUSTER cluster\n"+
+		"(\n"+
+		"    `id` UInt64,\n"+
+		"    `ts` DateTime64(3, 'UTC') CODEC(Delta, ZSTD(1)),\n"+
+		"    `Name` String,\n"+
+		"    `Note` Nullable(String),\n"+
+		"    `Tags` Array(String),\n"+
+		"    `Attrs` Map(String, Int32),\n"+
+		"    `Status` String,\n"+
+		"    `UUID` UUID,\n"+
+		"    `IP` IPv6,\n"+
+		"    `Big` Int128,\n"+
+		"    `Hash` FixedString(4),\n"+
+		"    `Counter` Int64,\n"+
+		"    `home_City` String\n"+
+		")\n"+
+		"ENGINE = ReplacingMergeTree\n"+
+		"PARTITION BY toYYYYMM(ts)\n"+
+		"ORDER BY (id, ts)\n"+
+		"TTL ts + INTERVAL 30 DAY", sql)
+
+	type Simple struct {
+		UserID uint32
+	}
+	sql, err = CreateTableSQL[*Simple](CreateTableOptions{Table: "simple", NameMapper: SnakeCaseNameMapper})
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE TABLE simple\n(\n    `user_id` UInt32\n)\nENGINE = MergeTree\nORDER BY tuple()", sql)
+
+	sql, err = CreateTableSQL[Simple](CreateTableOptions{Table: "simple", Engine: "Memory"})
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE TABLE simple\n(\n    `UserID` UInt32\n)\nENGINE = Memory", sql)
+}
+
+func TestCreateTableSQLErrors(t *testing.T) {
+	type Simple struct {
+		A string
+	}
+	_, err := CreateTableSQL[Simple](CreateTableOptions{})
+	assert.ErrorContains(t, err, "table name is required")
+	_, err = CreateTableSQL[int](CreateTableOptions{Table: "t"})
+	assert.ErrorContains(t, err, "expects a struct type")
+	_, err = CreateTableSQL[Simple](CreateTableOptions{Table: "t", Codecs: map[string]string{"B": "ZSTD"}})
+	assert.ErrorContains(t, err, "codec for unknown column")
+
+	type Duplicate struct {
+		A string
+		B string `ch:"A"`
+	}
+	_, err = CreateTableSQL[Duplicate](CreateTableOptions{Table: "t"})
+	assert.ErrorContains(t, err, "duplicate column")
+
+	type Unsupported struct {
+		Ch  chan int
+		Arr *[]string
+	}
+	_, err = CreateTableSQL[Unsupported](CreateTableOptions{Table: "t"})
+	assert.ErrorContains(t, err, "no ClickHouse type for Go type chan int")
+}
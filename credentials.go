@@ -0,0 +1,156 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Credentials authenticate connections, they are returned by a CredentialsProvider. Set Username and
+// Password, JWT, or Username and Certificate.
+type Credentials struct {
+	Username string
+	Password string
+	// JWT authenticates with a JSON Web Token instead of the username and password, as GetJWT.
+	JWT string
+	// Certificate is the TLS client certificate of users identified with ssl_certificate.
+	Certificate *tls.Certificate
+	// Expiry is when the credentials expire. The pool closes native connections established with
+	// them, and HTTP connections whose TLS connections use their Certificate, once they expire.
+	// The next connections get new credentials. Zero means no expiry.
+	Expiry time.Time
+}
+
+// CredentialsProvider returns the credentials of connections. It is called when native connections
+// are dialed and for each HTTP request, so implementations should cache credentials until they expire.
+// An HTTP request that opens a TLS connection uses the same credentials for its headers and for
+// the client certificate of the handshake.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (*Credentials, error)
+}
+
+// CredentialsProviderFunc is a function implementing CredentialsProvider.
+type CredentialsProviderFunc func(ctx context.Context) (*Credentials, error)
+
+func (f CredentialsProviderFunc) Credentials(ctx context.Context) (*Credentials, error) {
+	return f(ctx)
+}
+
+type credentialsKey struct{}
+
+// withProvidedCredentials returns ctx with the credentials of the provider of opt, so that
+// providedCredentials returns them for the rest of an HTTP request without asking the provider again.
+func withProvidedCredentials(ctx context.Context, opt *Options) (context.Context, error) {
+	if queryOptionsJWT(ctx) != "" {
+		// the JWT of the query takes precedence, see applyOptionsToRequest
+		return ctx, nil
+	}
+	credentials, err := providedCredentials(ctx, opt)
+	if err != nil || credentials == nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, credentialsKey{}, credentials), nil
+}
+
+// providedCredentials returns the credentials of the provider of opt, nil without one.
+func providedCredentials(ctx context.Context, opt *Options) (*Credentials, error) {
+	if opt.CredentialsProvider == nil {
+		return nil, nil
+	}
+	if credentials, ok := ctx.Value(credentialsKey{}).(*Credentials); ok {
+		return credentials, nil
+	}
+	credentials, err := opt.CredentialsProvider.Credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+	if credentials == nil {
+		return nil, errors.New("failed to get credentials: no credentials")
+	}
+	return credentials, nil
+}
+
+// certificateExpiry is the earliest expiry of the provided client certificates of the TLS
+// connections of an HTTP transport.
+type certificateExpiry struct {
+	mutex  sync.Mutex
+	expiry time.Time
+}
+
+func (e *certificateExpiry) add(expiry time.Time) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if !expiry.IsZero() && (e.expiry.IsZero() || expiry.Before(e.expiry)) {
+		e.expiry = expiry
+	}
+}
+
+func (e *certificateExpiry) expired() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return !e.expiry.IsZero() && !time.Now().Before(e.expiry)
+}
+
+// providedCertificateTLSConfig returns a copy of opt.TLS that asks the provider of opt for the client
+// certificate of each handshake. Without a provided certificate, the certificate of opt.TLS is used.
+// The expiry of provided certificates is added to expiry.
+func providedCertificateTLSConfig(opt *Options, expiry *certificateExpiry) *tls.Config {
+	config := opt.TLS.Clone()
+	fallback := opt.TLS.GetClientCertificate
+	certificates := opt.TLS.Certificates
+	config.GetClientCertificate = func(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		credentials, err := providedCredentials(info.Context(), opt)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case credentials.Certificate != nil:
+			expiry.add(credentials.Expiry)
+			return credentials.Certificate, nil
+		case fallback != nil:
+			return fallback(info)
+		}
+		// as crypto/tls does for Certificates
+		for i := range certificates {
+			if info.SupportsCertificate(&certificates[i]) == nil {
+				return &certificates[i], nil
+			}
+		}
+		return &tls.Certificate{}, nil
+	}
+	return config
+}
+
+// withClientCertificate returns config, using certificate if it is not nil.
+func withClientCertificate(config *tls.Config, certificate *tls.Certificate) (*tls.Config, error) {
+	if certificate == nil {
+		return config, nil
+	}
+	if config == nil {
+		return nil, errors.New("clickhouse: a client certificate requires TLS")
+	}
+	config = config.Clone()
+	config.Certificates = []tls.Certificate{*certificate}
+	config.GetClientCertificate = nil
+	return config, nil
+}
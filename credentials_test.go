@@ -0,0 +1,165 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyOptionsToRequestCredentialsProvider(t *testing.T) {
+	var credentials Credentials
+	opt := &Options{
+		Auth: Auth{Username: "static", Password: "static"},
+		CredentialsProvider: CredentialsProviderFunc(func(context.Context) (*Credentials, error) {
+			return &credentials, nil
+		}),
+	}
+	request := func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPost, "http://localhost:8123/", nil)
+		require.NoError(t, err)
+		return req, applyOptionsToRequest(ctx, req, opt)
+	}
+
+	credentials = Credentials{Username: "user", Password: "rotated"}
+	req, err := request(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "user:rotated", req.URL.User.String())
+
+	credentials = Credentials{Username: "user", Certificate: &tls.Certificate{}}
+	_, err = request(context.Background())
+	assert.ErrorContains(t, err, "a client certificate requires TLS")
+
+	opt.TLS = &tls.Config{}
+	req, err = request(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "user", req.Header.Get("X-ClickHouse-User"))
+	assert.Equal(t, "on", req.Header.Get("X-ClickHouse-SSL-Certificate-Auth"))
+
+	credentials = Credentials{JWT: "provided"}
+	req, err = request(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer provided", req.Header.Get("Authorization"))
+
+	req, err = request(Context(context.Background(), WithJWT("query")))
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer query", req.Header.Get("Authorization"))
+}
+
+func TestProvidedCertificateTLSConfig(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		caFile   = filepath.Join(dir, "ca.pem")
+		certFile = filepath.Join(dir, "client.pem")
+		keyFile  = filepath.Join(dir, "client.key")
+		ca       = newTestCertificate(t, "ca", nil)
+		server   = newTestCertificate(t, "clickhouse.internal", ca)
+		provided = newTestCertificate(t, "provided", ca)
+	)
+	ca.write(t, caFile, "")
+	newTestCertificate(t, "client", ca).write(t, certFile, keyFile)
+
+	opt, err := ParseDSN("https://127.0.0.1:8443/?" + url.Values{
+		"tls_ca_file":     {caFile},
+		"tls_cert_file":   {certFile},
+		"tls_key_file":    {keyFile},
+		"tls_server_name": {"clickhouse.internal"},
+	}.Encode())
+	require.NoError(t, err)
+	var credentials Credentials
+	opt.CredentialsProvider = CredentialsProviderFunc(func(context.Context) (*Credentials, error) {
+		return &credentials, nil
+	})
+
+	// a password-only provider keeps the certificate of tls_cert_file
+	credentials = Credentials{Username: "user", Password: "rotated"}
+	assert.Equal(t, "client", clientCertificate(t, providedCertificateTLSConfig(opt, new(certificateExpiry)), server, ca).Subject.CommonName)
+
+	credentials = Credentials{Username: "user", Certificate: &tls.Certificate{Certificate: [][]byte{provided.cert.Raw}, PrivateKey: provided.key}}
+	assert.Equal(t, "provided", clientCertificate(t, providedCertificateTLSConfig(opt, new(certificateExpiry)), server, ca).Subject.CommonName)
+
+	// and the static certificates of the TLS config
+	opt.TLS.GetClientCertificate = nil
+	opt.TLS.Certificates = []tls.Certificate{*credentials.Certificate}
+	credentials = Credentials{Username: "user", Password: "rotated"}
+	assert.Equal(t, "provided", clientCertificate(t, providedCertificateTLSConfig(opt, new(certificateExpiry)), server, ca).Subject.CommonName)
+}
+
+func TestProvidedCredentialsPerRequest(t *testing.T) {
+	var calls int
+	opt := &Options{
+		CredentialsProvider: CredentialsProviderFunc(func(context.Context) (*Credentials, error) {
+			calls++
+			return &Credentials{Username: "user", Password: "rotated"}, nil
+		}),
+	}
+	ctx, err := withProvidedCredentials(context.Background(), opt)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		credentials, err := providedCredentials(ctx, opt)
+		require.NoError(t, err)
+		assert.Equal(t, "rotated", credentials.Password)
+	}
+	assert.Equal(t, 1, calls)
+
+	ctx, err = withProvidedCredentials(Context(context.Background(), WithJWT("query")), opt)
+	require.NoError(t, err)
+	assert.Nil(t, ctx.Value(credentialsKey{}))
+	assert.Equal(t, 1, calls)
+}
+
+func TestProvidedCertificateExpiry(t *testing.T) {
+	var (
+		ca       = newTestCertificate(t, "ca", nil)
+		server   = newTestCertificate(t, "clickhouse.internal", ca)
+		provided = newTestCertificate(t, "provided", ca)
+		roots    = x509.NewCertPool()
+		expiry   = new(certificateExpiry)
+	)
+	roots.AddCert(ca.cert)
+	credentials := Credentials{
+		Username:    "user",
+		Certificate: &tls.Certificate{Certificate: [][]byte{provided.cert.Raw}, PrivateKey: provided.key},
+		Expiry:      time.Now().Add(time.Hour),
+	}
+	opt := &Options{
+		TLS: &tls.Config{RootCAs: roots, ServerName: "clickhouse.internal"},
+		CredentialsProvider: CredentialsProviderFunc(func(context.Context) (*Credentials, error) {
+			return &credentials, nil
+		}),
+	}
+	conn := &httpConnect{certExpiry: expiry}
+	assert.False(t, conn.credentialsExpired())
+	clientCertificate(t, providedCertificateTLSConfig(opt, expiry), server, ca)
+	assert.False(t, conn.credentialsExpired())
+
+	// the earliest expiry of the handshakes counts
+	credentials.Expiry = time.Now().Add(-time.Second)
+	clientCertificate(t, providedCertificateTLSConfig(opt, expiry), server, ca)
+	assert.True(t, conn.credentialsExpired())
+	assert.False(t, (&httpConnect{}).credentialsExpired())
+}
@@ -0,0 +1,158 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+type TableColumn = driver.TableColumn
+
+// schemaChangeMatch matches statements after which cached table schemas are dropped. It is a
+// best effort only: the cache TTL is what bounds how stale a cached schema can be.
+var schemaChangeMatch = regexp.MustCompile(`(?i)^\s*(ALTER|DROP|RENAME|EXCHANGE|CREATE\s+OR\s+REPLACE|REPLACE)\s`)
+
+// schemaCache caches DESCRIBE TABLE results, a nil cache disables caching
+type schemaCache struct {
+	ttl     time.Duration
+	mutex   sync.Mutex
+	entries map[string]schemaCacheEntry
+}
+
+type schemaCacheEntry struct {
+	columns   []TableColumn
+	fetchedAt time.Time
+}
+
+func newSchemaCache(ttl time.Duration) *schemaCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &schemaCache{
+		ttl:     ttl,
+		entries: make(map[string]schemaCacheEntry),
+	}
+}
+
+// schemaCacheKey normalizes a table expression such as `db`.`table` to db.table
+func schemaCacheKey(table string) string {
+	return strings.NewReplacer("`", "", `"`, "").Replace(strings.TrimSpace(table))
+}
+
+func (c *schemaCache) describe(table string, fetch func() ([]TableColumn, error)) ([]TableColumn, error) {
+	if c == nil {
+		return fetch()
+	}
+	key := schemaCacheKey(table)
+	c.mutex.Lock()
+	entry, found := c.entries[key]
+	c.mutex.Unlock()
+	if found && time.Since(entry.fetchedAt) < c.ttl {
+		return slices.Clone(entry.columns), nil
+	}
+	columns, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	c.entries[key] = schemaCacheEntry{
+		columns:   columns,
+		fetchedAt: time.Now(),
+	}
+	c.mutex.Unlock()
+	return slices.Clone(columns), nil
+}
+
+func (c *schemaCache) invalidate(table string) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(table) == 0 {
+		clear(c.entries)
+		return
+	}
+	delete(c.entries, schemaCacheKey(table))
+}
+
+// invalidateOnSchemaChange drops all cached schemas when query may have changed one
+func (c *schemaCache) invalidateOnSchemaChange(query string) {
+	if c != nil && schemaChangeMatch.MatchString(query) {
+		c.invalidate("")
+	}
+}
+
+func describeTableQuery(database, table string) string {
+	if len(database) == 0 {
+		return "DESCRIBE TABLE " + quoteIdentifier(table)
+	}
+	return "DESCRIBE TABLE " + quoteIdentifier(database) + "." + quoteIdentifier(table)
+}
+
+func scanTableColumns(rows driver.Rows) ([]TableColumn, error) {
+	defer rows.Close()
+	var columns []TableColumn
+	for rows.Next() {
+		var c TableColumn
+		if err := rows.Scan(&c.Name, &c.Type, &c.DefaultKind, &c.DefaultExpression, &c.Comment, &c.CodecExpression, &c.TTLExpression); err != nil {
+			return nil, err
+		}
+		columns = append(columns, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+var _ driver.SchemaConn = (*clickhouse)(nil)
+
+// DescribeTable returns the columns of table in database, or in the connection database if
+// database is empty. When Options.SchemaCacheTTL is set, results are cached for that long or until
+// InvalidateTableSchema is called. ALTER, DROP, RENAME, EXCHANGE and CREATE OR REPLACE statements
+// run with Exec drop the cache as well, but schema changes made with Query or QueryRaw, by other
+// clients or by ON CLUSTER statements on other replicas are not seen before the TTL expires.
+func (ch *clickhouse) DescribeTable(ctx context.Context, database, table string) ([]TableColumn, error) {
+	key := table
+	if len(database) != 0 {
+		key = database + "." + table
+	}
+	return ch.opt.schemaCache.describe(key, func() ([]TableColumn, error) {
+		rows, err := ch.Query(ctx, describeTableQuery(database, table))
+		if err != nil {
+			return nil, err
+		}
+		return scanTableColumns(rows)
+	})
+}
+
+// InvalidateTableSchema drops the cached schema of table in database. An empty table drops all cached schemas.
+func (ch *clickhouse) InvalidateTableSchema(database, table string) {
+	if len(database) != 0 && len(table) != 0 {
+		table = database + "." + table
+	}
+	ch.opt.schemaCache.invalidate(table)
+}
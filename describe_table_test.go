@@ -0,0 +1,84 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaCache(t *testing.T) {
+	var (
+		fetches int
+		cache   = newSchemaCache(time.Hour)
+		fetch   = func() ([]TableColumn, error) {
+			fetches++
+			return []TableColumn{{Name: "id", Type: "UInt64"}}, nil
+		}
+	)
+	columns, err := cache.describe("`db`.`t`", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, []TableColumn{{Name: "id", Type: "UInt64"}}, columns)
+	columns[0].Name = "modified"
+
+	columns, err = cache.describe("db.t", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, "id", columns[0].Name)
+	assert.Equal(t, 1, fetches)
+
+	cache.invalidate("db.t")
+	_, err = cache.describe("db.t", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetches)
+
+	cache.invalidateOnSchemaChange("SELECT * FROM db.t")
+	_, _ = cache.describe("db.t", fetch)
+	assert.Equal(t, 2, fetches)
+
+	cache.invalidateOnSchemaChange("ALTER TABLE db.t ADD COLUMN x String")
+	_, _ = cache.describe("db.t", fetch)
+	assert.Equal(t, 3, fetches)
+
+	_, err = cache.describe("other", func() ([]TableColumn, error) {
+		return nil, errors.New("fetch failed")
+	})
+	assert.Error(t, err)
+
+	require.Nil(t, newSchemaCache(0))
+	disabled := newSchemaCache(-1)
+	require.Nil(t, disabled)
+	_, _ = disabled.describe("db.t", fetch)
+	_, _ = disabled.describe("db.t", fetch)
+	assert.Equal(t, 5, fetches)
+	disabled.invalidate("")
+
+	expiring := newSchemaCache(time.Nanosecond)
+	_, _ = expiring.describe("db.t", fetch)
+	time.Sleep(time.Millisecond)
+	_, _ = expiring.describe("db.t", fetch)
+	assert.Equal(t, 7, fetches)
+}
+
+func TestDescribeTableQuery(t *testing.T) {
+	assert.Equal(t, "DESCRIBE TABLE `t`", describeTableQuery("", "t"))
+	assert.Equal(t, "DESCRIBE TABLE `db`.`my\\`t`", describeTableQuery("db", "my`t"))
+}
@@ -0,0 +1,451 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+)
+
+var (
+	ErrInserterClosed    = errors.New("clickhouse [inserter]: inserter is closed")
+	ErrInserterQueueFull = errors.New("clickhouse [inserter]: queue is full, row dropped")
+)
+
+// InserterOptions configures an Inserter. Zero values are replaced with defaults.
+type InserterOptions struct {
+	MaxBatchRows  int           // default 10000 - rows per INSERT
+	FlushInterval time.Duration // default 1 second - max time a row waits before its batch is sent
+	Workers       int           // default 1 - number of concurrent INSERTs, each holding one pooled connection
+	QueueSize     int           // default MaxBatchRows * Workers - rows buffered before backpressure applies
+	DropWhenFull  bool          // return ErrInserterQueueFull instead of blocking when the queue is full
+	MaxRetries    int           // retries of a failed Send, 0 disables retrying
+	RetryBackoff  time.Duration // default 100 milliseconds, doubled after every attempt
+	// OnError is called from a worker goroutine whenever rows are lost,
+	// either because a row was rejected by Append or because a batch could not be sent.
+	OnError func(err error, rows int)
+	// BatchOptions are passed to every PrepareBatch call.
+	BatchOptions []driver.PrepareBatchOption
+}
+
+func (o InserterOptions) setDefaults() InserterOptions {
+	if o.MaxBatchRows <= 0 {
+		o.MaxBatchRows = 10000
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = time.Second
+	}
+	if o.Workers <= 0 {
+		o.Workers = 1
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = o.MaxBatchRows * o.Workers
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = 100 * time.Millisecond
+	}
+	return o
+}
+
+// InserterStats is a point-in-time snapshot of Inserter counters.
+type InserterStats struct {
+	Queued        int    // rows waiting in the queue
+	Appended      uint64 // rows accepted by Append/AppendStruct
+	Dropped       uint64 // rows rejected because the queue was full
+	Sent          uint64 // rows successfully sent
+	Failed        uint64 // rows lost to append or send errors
+	Batches       uint64 // batches successfully sent
+	FailedBatches uint64 // batches that could not be sent after all retries
+	Retries       uint64 // Send retry attempts
+}
+
+type inserterRow struct {
+	values []any
+	value  any // struct pointer from AppendStruct
+}
+
+func (r inserterRow) appendTo(b driver.Batch) error {
+	if r.value != nil {
+		return b.AppendStruct(r.value)
+	}
+	return b.Append(r.values...)
+}
+
+func (r inserterRow) check(c rowChecker) error {
+	if r.value != nil {
+		return c.checkAppendStruct(r.value)
+	}
+	return c.checkAppend(r.values...)
+}
+
+// rowChecker is implemented by the batches of this package. It reports whether Append and AppendStruct
+// would accept a row, without changing the batch, so that the Inserter drops all rejected rows of a batch
+// in one pass instead of preparing a new batch per rejected row.
+type rowChecker interface {
+	checkAppend(v ...any) error
+	checkAppendStruct(v any) error
+}
+
+var (
+	_ rowChecker = (*batch)(nil)
+	_ rowChecker = (*httpBatch)(nil)
+)
+
+// blockChecker appends rows to an empty copy of a block
+type blockChecker struct {
+	scratch *proto.Block
+}
+
+func (c *blockChecker) check(block *proto.Block, appendRow func(*proto.Block) error) error {
+	if c.scratch == nil {
+		scratch, err := newEmptyBlock(block)
+		if err != nil {
+			return err
+		}
+		c.scratch = scratch
+	}
+	if err := appendRow(c.scratch); err != nil {
+		// a failed append can leave the columns with different row counts
+		c.scratch = nil
+		return err
+	}
+	for _, col := range c.scratch.Columns {
+		col.Reset()
+	}
+	return nil
+}
+
+// appendStructToBlock appends v the way Batch.AppendStruct does
+func appendStructToBlock(block *proto.Block, structMap *structMap, v any) error {
+	if a, ok := v.(driver.StructAppender); ok {
+		return a.AppendTo(block.Columns)
+	}
+	values, err := structMap.appendValues(block, v)
+	if err != nil {
+		return err
+	}
+	return block.Append(values...)
+}
+
+// Inserter groups rows appended from any number of goroutines into batches and
+// sends them in the background. Batches are sent when they reach MaxBatchRows or
+// when FlushInterval elapses, whichever happens first.
+type Inserter struct {
+	conn   driver.Conn
+	query  string
+	opt    InserterOptions
+	queue  chan inserterRow
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	closeMutex sync.RWMutex
+	closed     bool
+	closing    chan struct{}  // closed by Close, unblocks the writers waiting for room in the queue
+	writers    sync.WaitGroup // Append calls in progress, the queue is closed once they are done
+
+	appended      atomic.Uint64
+	dropped       atomic.Uint64
+	sent          atomic.Uint64
+	failed        atomic.Uint64
+	batches       atomic.Uint64
+	failedBatches atomic.Uint64
+	retries       atomic.Uint64
+}
+
+// NewInserter starts opt.Workers background workers that INSERT rows using query.
+// Close must be called to flush buffered rows and stop the workers.
+func NewInserter(conn driver.Conn, query string, opt InserterOptions) (*Inserter, error) {
+	if conn == nil {
+		return nil, errors.New("clickhouse [inserter]: nil connection")
+	}
+	if _, _, _, err := extractNormalizedInsertQueryAndColumns(query); err != nil {
+		return nil, err
+	}
+
+	opt = opt.setDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	in := &Inserter{
+		conn:    conn,
+		query:   query,
+		opt:     opt,
+		queue:   make(chan inserterRow, opt.QueueSize),
+		ctx:     ctx,
+		cancel:  cancel,
+		closing: make(chan struct{}),
+	}
+	for i := 0; i < opt.Workers; i++ {
+		in.wg.Add(1)
+		go in.work()
+	}
+	return in, nil
+}
+
+// Append queues a row of values. It is safe for concurrent use.
+// The values must not be modified after Append returns.
+func (in *Inserter) Append(ctx context.Context, v ...any) error {
+	return in.enqueue(ctx, inserterRow{values: v})
+}
+
+// AppendStruct queues a struct row. It is safe for concurrent use.
+// The struct is copied, but slices and maps it references must not be modified after AppendStruct returns.
+func (in *Inserter) AppendStruct(ctx context.Context, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return &OpError{
+			Op:  "AppendStruct",
+			Err: fmt.Errorf("must pass a non-nil struct pointer, got %T", v),
+		}
+	}
+	cp := reflect.New(rv.Elem().Type())
+	cp.Elem().Set(rv.Elem())
+	return in.enqueue(ctx, inserterRow{value: cp.Interface()})
+}
+
+func (in *Inserter) enqueue(ctx context.Context, row inserterRow) error {
+	in.closeMutex.RLock()
+	if in.closed {
+		in.closeMutex.RUnlock()
+		return ErrInserterClosed
+	}
+	in.writers.Add(1)
+	in.closeMutex.RUnlock()
+	defer in.writers.Done()
+
+	if in.opt.DropWhenFull {
+		select {
+		case in.queue <- row:
+		default:
+			in.dropped.Add(1)
+			return ErrInserterQueueFull
+		}
+	} else {
+		select {
+		case in.queue <- row:
+		case <-in.closing:
+			return ErrInserterClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	in.appended.Add(1)
+	return nil
+}
+
+// Stats returns the current Inserter counters.
+func (in *Inserter) Stats() InserterStats {
+	return InserterStats{
+		Queued:        len(in.queue),
+		Appended:      in.appended.Load(),
+		Dropped:       in.dropped.Load(),
+		Sent:          in.sent.Load(),
+		Failed:        in.failed.Load(),
+		Batches:       in.batches.Load(),
+		FailedBatches: in.failedBatches.Load(),
+		Retries:       in.retries.Load(),
+	}
+}
+
+// Close stops accepting rows and waits for the workers to send everything queued.
+// Appends blocked on a full queue return ErrInserterClosed. If ctx is done first, in-flight
+// sends are cancelled, the rows not sent yet are reported as failed and the context error
+// is returned without waiting for the workers.
+func (in *Inserter) Close(ctx context.Context) error {
+	in.closeMutex.Lock()
+	if in.closed {
+		in.closeMutex.Unlock()
+		return nil
+	}
+	in.closed = true
+	close(in.closing)
+	in.closeMutex.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		in.cancel()
+		return err
+	}
+	if err := wait(ctx, &in.writers); err != nil {
+		in.cancel()
+		return err
+	}
+	close(in.queue)
+
+	err := wait(ctx, &in.wg)
+	in.cancel()
+	return err
+}
+
+// wait waits for wg or until ctx is done
+func wait(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (in *Inserter) work() {
+	defer in.wg.Done()
+
+	ticker := time.NewTicker(in.opt.FlushInterval)
+	defer ticker.Stop()
+
+	rows := make([]inserterRow, 0, in.opt.MaxBatchRows)
+	for {
+		select {
+		case row, ok := <-in.queue:
+			if !ok {
+				in.send(rows)
+				return
+			}
+			rows = append(rows, row)
+			if len(rows) < in.opt.MaxBatchRows {
+				continue
+			}
+		case <-ticker.C:
+		case <-in.ctx.Done():
+			// Close gave up waiting, the buffered and queued rows are lost
+			in.fail(in.ctx.Err(), len(rows)+in.drain())
+			return
+		}
+		in.send(rows)
+		rows = rows[:0]
+		ticker.Reset(in.opt.FlushInterval)
+	}
+}
+
+// drain empties the queue without blocking and returns the number of rows removed
+func (in *Inserter) drain() (n int) {
+	for {
+		select {
+		case _, ok := <-in.queue:
+			if !ok {
+				return n
+			}
+			n++
+		default:
+			return n
+		}
+	}
+}
+
+func (in *Inserter) send(rows []inserterRow) {
+	if len(rows) == 0 {
+		return
+	}
+
+	var (
+		err     error
+		backoff = in.opt.RetryBackoff
+	)
+	for attempt := 0; ; attempt++ {
+		if rows, err = in.sendOnce(rows); err == nil {
+			if len(rows) != 0 {
+				in.batches.Add(1)
+				in.sent.Add(uint64(len(rows)))
+			}
+			return
+		}
+		if attempt >= in.opt.MaxRetries || in.ctx.Err() != nil {
+			break
+		}
+		in.retries.Add(1)
+		select {
+		case <-time.After(backoff):
+		case <-in.ctx.Done():
+		}
+		backoff *= 2
+	}
+
+	in.failedBatches.Add(1)
+	in.fail(err, len(rows))
+}
+
+// sendOnce sends rows as a single batch. Rows rejected by Append are reported and
+// removed, the returned slice holds the rows that made it into the batch.
+// The batches of this package check the rows following the first rejected one up front,
+// so a batch is prepared at most twice however many rows are rejected.
+func (in *Inserter) sendOnce(rows []inserterRow) ([]inserterRow, error) {
+	for {
+		batch, err := in.conn.PrepareBatch(in.ctx, in.query, in.opt.BatchOptions...)
+		if err != nil {
+			return rows, err
+		}
+
+		rejected := -1
+		for i, row := range rows {
+			if err = row.appendTo(batch); err != nil {
+				rejected = i
+				break
+			}
+		}
+		if rejected == -1 {
+			if err = batch.Send(); err != nil {
+				return rows, err
+			}
+			return rows, nil
+		}
+
+		_ = batch.Abort()
+		in.fail(err, 1)
+		rows = append(rows[:rejected], rows[rejected+1:]...)
+		if checker, ok := batch.(rowChecker); ok {
+			rows = in.dropRejected(checker, rows, rejected)
+		}
+		if len(rows) == 0 {
+			return rows, nil
+		}
+	}
+}
+
+// dropRejected reports and removes the rows from index from on that checker rejects
+func (in *Inserter) dropRejected(checker rowChecker, rows []inserterRow, from int) []inserterRow {
+	kept := rows[:from]
+	for _, row := range rows[from:] {
+		if err := row.check(checker); err != nil {
+			in.fail(err, 1)
+			continue
+		}
+		kept = append(kept, row)
+	}
+	return kept
+}
+
+func (in *Inserter) fail(err error, n int) {
+	if n == 0 {
+		return
+	}
+	in.failed.Add(uint64(n))
+	if in.opt.OnError != nil {
+		in.opt.OnError(err, n)
+	}
+}
@@ -0,0 +1,296 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type inserterTestConn struct {
+	driver.Conn
+	mutex    sync.Mutex
+	batches  [][]any
+	sendErrs []error
+	prepared int
+	gate     chan struct{} // blocks PrepareBatch until closed, if set
+}
+
+func (c *inserterTestConn) PrepareBatch(context.Context, string, ...driver.PrepareBatchOption) (driver.Batch, error) {
+	if c.gate != nil {
+		<-c.gate
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.prepared++
+	return &inserterTestBatch{conn: c}, nil
+}
+
+type inserterTestBatch struct {
+	driver.Batch
+	conn *inserterTestConn
+	rows []any
+}
+
+func (b *inserterTestBatch) Append(v ...any) error {
+	if err := b.checkAppend(v...); err != nil {
+		return err
+	}
+	b.rows = append(b.rows, v[0])
+	return nil
+}
+
+func (b *inserterTestBatch) checkAppend(v ...any) error {
+	if len(v) != 1 {
+		return errors.New("expected one value")
+	}
+	return nil
+}
+
+func (b *inserterTestBatch) checkAppendStruct(v any) error {
+	return b.checkAppend(v)
+}
+
+func (b *inserterTestBatch) AppendStruct(v any) error {
+	return b.Append(v)
+}
+
+func (b *inserterTestBatch) Abort() error {
+	return nil
+}
+
+func (b *inserterTestBatch) Send() error {
+	b.conn.mutex.Lock()
+	defer b.conn.mutex.Unlock()
+	if len(b.conn.sendErrs) != 0 {
+		err := b.conn.sendErrs[0]
+		b.conn.sendErrs = b.conn.sendErrs[1:]
+		return err
+	}
+	b.conn.batches = append(b.conn.batches, b.rows)
+	return nil
+}
+
+func TestInserterBatchesBySize(t *testing.T) {
+	conn := &inserterTestConn{}
+	in, err := NewInserter(conn, "INSERT INTO t", InserterOptions{
+		MaxBatchRows:  10,
+		FlushInterval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 5; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				assert.NoError(t, in.Append(context.Background(), i))
+			}
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, in.Close(context.Background()))
+
+	assert.Len(t, conn.batches, 10)
+	for _, b := range conn.batches {
+		assert.Len(t, b, 10)
+	}
+	stats := in.Stats()
+	assert.Equal(t, uint64(100), stats.Appended)
+	assert.Equal(t, uint64(100), stats.Sent)
+	assert.Equal(t, uint64(10), stats.Batches)
+	assert.ErrorIs(t, in.Append(context.Background(), 1), ErrInserterClosed)
+}
+
+func TestInserterFlushInterval(t *testing.T) {
+	conn := &inserterTestConn{}
+	in, err := NewInserter(conn, "INSERT INTO t", InserterOptions{
+		MaxBatchRows:  1000,
+		FlushInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer in.Close(context.Background())
+
+	require.NoError(t, in.Append(context.Background(), 1))
+	assert.Eventually(t, func() bool {
+		return in.Stats().Sent == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestInserterRetry(t *testing.T) {
+	conn := &inserterTestConn{sendErrs: []error{errors.New("network"), errors.New("network")}}
+	in, err := NewInserter(conn, "INSERT INTO t", InserterOptions{
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, in.Append(context.Background(), 1))
+	require.NoError(t, in.Close(context.Background()))
+
+	stats := in.Stats()
+	assert.Equal(t, uint64(2), stats.Retries)
+	assert.Equal(t, uint64(1), stats.Sent)
+	assert.Equal(t, uint64(0), stats.FailedBatches)
+}
+
+func TestInserterFailure(t *testing.T) {
+	var lost int
+	conn := &inserterTestConn{sendErrs: []error{errors.New("network")}}
+	in, err := NewInserter(conn, "INSERT INTO t", InserterOptions{
+		OnError: func(err error, rows int) {
+			lost += rows
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, in.Append(context.Background(), 1))
+	require.NoError(t, in.Append(context.Background(), 1, 2))
+	require.NoError(t, in.Close(context.Background()))
+
+	stats := in.Stats()
+	assert.Equal(t, 2, lost)
+	assert.Equal(t, uint64(2), stats.Failed)
+	assert.Equal(t, uint64(1), stats.FailedBatches)
+}
+
+func TestInserterRejectedRows(t *testing.T) {
+	var lost int
+	conn := &inserterTestConn{}
+	in, err := NewInserter(conn, "INSERT INTO t", InserterOptions{
+		MaxBatchRows:  10,
+		FlushInterval: time.Hour,
+		OnError: func(err error, rows int) {
+			lost += rows
+		},
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		if i%3 == 1 {
+			require.NoError(t, in.Append(context.Background(), i, "bad"))
+			continue
+		}
+		require.NoError(t, in.Append(context.Background(), i))
+	}
+	require.NoError(t, in.Close(context.Background()))
+
+	// all rejected rows are dropped after the first one, with one more PrepareBatch
+	assert.Equal(t, 2, conn.prepared)
+	assert.Equal(t, [][]any{{0, 2, 3, 5, 6, 8, 9}}, conn.batches)
+	assert.Equal(t, 3, lost)
+	assert.Equal(t, uint64(3), in.Stats().Failed)
+}
+
+func TestInserterDropWhenFull(t *testing.T) {
+	conn := &inserterTestConn{gate: make(chan struct{})}
+	in, err := NewInserter(conn, "INSERT INTO t", InserterOptions{
+		MaxBatchRows: 1,
+		QueueSize:    1,
+		DropWhenFull: true,
+	})
+	require.NoError(t, err)
+
+	// the worker takes the first row and blocks in PrepareBatch, the second row fills the queue
+	require.NoError(t, in.Append(context.Background(), 1))
+	require.Eventually(t, func() bool {
+		return in.Stats().Queued == 0
+	}, time.Second, time.Millisecond)
+	require.NoError(t, in.Append(context.Background(), 2))
+	assert.ErrorIs(t, in.Append(context.Background(), 3), ErrInserterQueueFull)
+	assert.Equal(t, uint64(1), in.Stats().Dropped)
+
+	close(conn.gate)
+	require.NoError(t, in.Close(context.Background()))
+	assert.Equal(t, uint64(2), in.Stats().Sent)
+}
+
+func TestInserterCloseDeadline(t *testing.T) {
+	conn := &inserterTestConn{gate: make(chan struct{})}
+	defer close(conn.gate)
+	in, err := NewInserter(conn, "INSERT INTO t", InserterOptions{
+		MaxBatchRows: 1,
+		QueueSize:    1,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, in.Append(context.Background(), 1))
+	require.Eventually(t, func() bool {
+		return in.Stats().Queued == 0
+	}, time.Second, time.Millisecond)
+	require.NoError(t, in.Append(context.Background(), 2))
+
+	// a writer blocked on the full queue while the worker is stuck
+	blocked := make(chan error)
+	go func() {
+		blocked <- in.Append(context.Background(), 3)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.ErrorIs(t, in.Close(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.ErrorIs(t, <-blocked, ErrInserterClosed)
+}
+
+func TestInserterAppendStructCopies(t *testing.T) {
+	type row struct {
+		Col1 string
+	}
+	conn := &inserterTestConn{}
+	in, err := NewInserter(conn, "INSERT INTO t", InserterOptions{})
+	require.NoError(t, err)
+
+	v := row{Col1: "a"}
+	require.NoError(t, in.AppendStruct(context.Background(), &v))
+	v.Col1 = "b"
+	require.NoError(t, in.Close(context.Background()))
+
+	require.Len(t, conn.batches, 1)
+	assert.Equal(t, &row{Col1: "a"}, conn.batches[0][0])
+	assert.Error(t, in.AppendStruct(context.Background(), v))
+}
+
+func TestBatchCheckAppend(t *testing.T) {
+	block := &proto.Block{}
+	require.NoError(t, block.AddColumn("col1", "Int64"))
+	require.NoError(t, block.AddColumn("col2", "Array(String)"))
+	b := &batch{block: block, conn: &connect{structMap: &structMap{}}}
+
+	assert.NoError(t, b.checkAppend(int64(1), []string{"a"}))
+	assert.Error(t, b.checkAppend(int64(1), "a"))
+	assert.NoError(t, b.checkAppend(int64(2), []string{}))
+	assert.Error(t, b.checkAppendStruct(&struct {
+		Col1 int64 `ch:"col1"`
+	}{}))
+	assert.NoError(t, b.checkAppendStruct(&struct {
+		Col1 int64    `ch:"col1"`
+		Col2 []string `ch:"col2"`
+	}{}))
+	assert.Equal(t, 0, block.Rows())
+}
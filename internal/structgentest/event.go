@@ -0,0 +1,52 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package structgentest holds a struct with methods generated by lib/structgen, to compile,
+// test and benchmark the generated code.
+package structgentest
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//go:generate go run github.com/ClickHouse/clickhouse-go/v2/lib/structgen -type Event -schema event.schema -snake
+
+type Address struct {
+	City string
+	Zip  string `ch:"zip"`
+}
+
+type Base struct {
+	ID uint64
+}
+
+type Event struct {
+	Base
+	UserName string
+	Home     Address `ch:",prefix=home_"`
+	Score    float64
+	Active   bool
+	Day      time.Time
+	At       time.Time
+	Key      uuid.UUID
+	Referrer *string
+	Tags     []string
+	Attrs    map[string]string
+	Ignored  string `ch:"-"`
+}
@@ -0,0 +1,164 @@
+// Code generated by structgen DO NOT EDIT.
+// structgen -type Event -schema event.schema -snake
+
+package structgentest
+
+import (
+	"fmt"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+)
+
+// Event columns: id UInt64, user_name String, home_city LowCardinality(String), home_zip String, score Float64, active Bool, day Date, at DateTime64(3, 'UTC'), key UUID, referrer Nullable(String), tags Array(String), attrs Map(String, String)
+
+// AppendTo appends v as a row to the columns of a batch, it implements driver.StructAppender.
+func (v *Event) AppendTo(columns []column.Interface) error {
+	if len(columns) != 12 {
+		return fmt.Errorf("clickhouse [AppendStruct]: expected the 12 columns of *Event, got %d", len(columns))
+	}
+	c0, ok := columns[0].(*column.UInt64)
+	if !ok || c0.Name() != "id" {
+		return fmt.Errorf("clickhouse [AppendStruct]: column 0 is %s %s, *Event expects id UInt64", columns[0].Name(), columns[0].Type())
+	}
+	c1, ok := columns[1].(*column.String)
+	if !ok || c1.Name() != "user_name" {
+		return fmt.Errorf("clickhouse [AppendStruct]: column 1 is %s %s, *Event expects user_name String", columns[1].Name(), columns[1].Type())
+	}
+	c2 := columns[2]
+	if c2.Name() != "home_city" {
+		return fmt.Errorf("clickhouse [AppendStruct]: column 2 is %s %s, *Event expects home_city LowCardinality(String)", columns[2].Name(), columns[2].Type())
+	}
+	c3, ok := columns[3].(*column.String)
+	if !ok || c3.Name() != "home_zip" {
+		return fmt.Errorf("clickhouse [AppendStruct]: column 3 is %s %s, *Event expects home_zip String", columns[3].Name(), columns[3].Type())
+	}
+	c4, ok := columns[4].(*column.Float64)
+	if !ok || c4.Name() != "score" {
+		return fmt.Errorf("clickhouse [AppendStruct]: column 4 is %s %s, *Event expects score Float64", columns[4].Name(), columns[4].Type())
+	}
+	c5, ok := columns[5].(*column.Bool)
+	if !ok || c5.Name() != "active" {
+		return fmt.Errorf("clickhouse [AppendStruct]: column 5 is %s %s, *Event expects active Bool", columns[5].Name(), columns[5].Type())
+	}
+	c6, ok := columns[6].(*column.Date)
+	if !ok || c6.Name() != "day" {
+		return fmt.Errorf("clickhouse [AppendStruct]: column 6 is %s %s, *Event expects day Date", columns[6].Name(), columns[6].Type())
+	}
+	c7, ok := columns[7].(*column.DateTime64)
+	if !ok || c7.Name() != "at" {
+		return fmt.Errorf("clickhouse [AppendStruct]: column 7 is %s %s, *Event expects at DateTime64(3, 'UTC')", columns[7].Name(), columns[7].Type())
+	}
+	c8, ok := columns[8].(*column.UUID)
+	if !ok || c8.Name() != "key" {
+		return fmt.Errorf("clickhouse [AppendStruct]: column 8 is %s %s, *Event expects key UUID", columns[8].Name(), columns[8].Type())
+	}
+	c9 := columns[9]
+	if c9.Name() != "referrer" {
+		return fmt.Errorf("clickhouse [AppendStruct]: column 9 is %s %s, *Event expects referrer Nullable(String)", columns[9].Name(), columns[9].Type())
+	}
+	c10 := columns[10]
+	if c10.Name() != "tags" {
+		return fmt.Errorf("clickhouse [AppendStruct]: column 10 is %s %s, *Event expects tags Array(String)", columns[10].Name(), columns[10].Type())
+	}
+	c11 := columns[11]
+	if c11.Name() != "attrs" {
+		return fmt.Errorf("clickhouse [AppendStruct]: column 11 is %s %s, *Event expects attrs Map(String, String)", columns[11].Name(), columns[11].Type())
+	}
+	c0.AppendValue(v.Base.ID)
+	c1.AppendValue(v.UserName)
+	if err := c2.AppendRow(v.Home.City); err != nil {
+		return fmt.Errorf("clickhouse [AppendStruct]: column home_city: %w", err)
+	}
+	c3.AppendValue(v.Home.Zip)
+	c4.AppendValue(v.Score)
+	c5.AppendValue(v.Active)
+	c6.AppendValue(v.Day)
+	c7.AppendValue(v.At)
+	c8.AppendValue(v.Key)
+	if err := c9.AppendRow(v.Referrer); err != nil {
+		return fmt.Errorf("clickhouse [AppendStruct]: column referrer: %w", err)
+	}
+	if err := c10.AppendRow(v.Tags); err != nil {
+		return fmt.Errorf("clickhouse [AppendStruct]: column tags: %w", err)
+	}
+	if err := c11.AppendRow(v.Attrs); err != nil {
+		return fmt.Errorf("clickhouse [AppendStruct]: column attrs: %w", err)
+	}
+	return nil
+}
+
+// ScanFrom scans row of the columns of a block into v, it implements driver.StructScanner.
+func (v *Event) ScanFrom(columns []column.Interface, row int) error {
+	if len(columns) != 12 {
+		return fmt.Errorf("clickhouse [ScanStruct]: expected the 12 columns of *Event, got %d", len(columns))
+	}
+	c0, ok := columns[0].(*column.UInt64)
+	if !ok || c0.Name() != "id" {
+		return fmt.Errorf("clickhouse [ScanStruct]: column 0 is %s %s, *Event expects id UInt64", columns[0].Name(), columns[0].Type())
+	}
+	c1, ok := columns[1].(*column.String)
+	if !ok || c1.Name() != "user_name" {
+		return fmt.Errorf("clickhouse [ScanStruct]: column 1 is %s %s, *Event expects user_name String", columns[1].Name(), columns[1].Type())
+	}
+	c2 := columns[2]
+	if c2.Name() != "home_city" {
+		return fmt.Errorf("clickhouse [ScanStruct]: column 2 is %s %s, *Event expects home_city LowCardinality(String)", columns[2].Name(), columns[2].Type())
+	}
+	c3, ok := columns[3].(*column.String)
+	if !ok || c3.Name() != "home_zip" {
+		return fmt.Errorf("clickhouse [ScanStruct]: column 3 is %s %s, *Event expects home_zip String", columns[3].Name(), columns[3].Type())
+	}
+	c4, ok := columns[4].(*column.Float64)
+	if !ok || c4.Name() != "score" {
+		return fmt.Errorf("clickhouse [ScanStruct]: column 4 is %s %s, *Event expects score Float64", columns[4].Name(), columns[4].Type())
+	}
+	c5, ok := columns[5].(*column.Bool)
+	if !ok || c5.Name() != "active" {
+		return fmt.Errorf("clickhouse [ScanStruct]: column 5 is %s %s, *Event expects active Bool", columns[5].Name(), columns[5].Type())
+	}
+	c6, ok := columns[6].(*column.Date)
+	if !ok || c6.Name() != "day" {
+		return fmt.Errorf("clickhouse [ScanStruct]: column 6 is %s %s, *Event expects day Date", columns[6].Name(), columns[6].Type())
+	}
+	c7, ok := columns[7].(*column.DateTime64)
+	if !ok || c7.Name() != "at" {
+		return fmt.Errorf("clickhouse [ScanStruct]: column 7 is %s %s, *Event expects at DateTime64(3, 'UTC')", columns[7].Name(), columns[7].Type())
+	}
+	c8, ok := columns[8].(*column.UUID)
+	if !ok || c8.Name() != "key" {
+		return fmt.Errorf("clickhouse [ScanStruct]: column 8 is %s %s, *Event expects key UUID", columns[8].Name(), columns[8].Type())
+	}
+	c9 := columns[9]
+	if c9.Name() != "referrer" {
+		return fmt.Errorf("clickhouse [ScanStruct]: column 9 is %s %s, *Event expects referrer Nullable(String)", columns[9].Name(), columns[9].Type())
+	}
+	c10 := columns[10]
+	if c10.Name() != "tags" {
+		return fmt.Errorf("clickhouse [ScanStruct]: column 10 is %s %s, *Event expects tags Array(String)", columns[10].Name(), columns[10].Type())
+	}
+	c11 := columns[11]
+	if c11.Name() != "attrs" {
+		return fmt.Errorf("clickhouse [ScanStruct]: column 11 is %s %s, *Event expects attrs Map(String, String)", columns[11].Name(), columns[11].Type())
+	}
+	v.Base.ID = c0.Value(row)
+	v.UserName = c1.Value(row)
+	if err := c2.ScanRow(&v.Home.City, row); err != nil {
+		return fmt.Errorf("clickhouse [ScanStruct]: column home_city: %w", err)
+	}
+	v.Home.Zip = c3.Value(row)
+	v.Score = c4.Value(row)
+	v.Active = c5.Value(row)
+	v.Day = c6.Value(row)
+	v.At = c7.Value(row)
+	v.Key = c8.Value(row)
+	if err := c9.ScanRow(&v.Referrer, row); err != nil {
+		return fmt.Errorf("clickhouse [ScanStruct]: column referrer: %w", err)
+	}
+	if err := c10.ScanRow(&v.Tags, row); err != nil {
+		return fmt.Errorf("clickhouse [ScanStruct]: column tags: %w", err)
+	}
+	if err := c11.ScanRow(&v.Attrs, row); err != nil {
+		return fmt.Errorf("clickhouse [ScanStruct]: column attrs: %w", err)
+	}
+	return nil
+}
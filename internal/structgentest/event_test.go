@@ -0,0 +1,107 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package structgentest
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBlock returns an empty block with the columns of event.schema
+func newBlock(t testing.TB) *proto.Block {
+	schema, err := os.ReadFile("event.schema")
+	require.NoError(t, err)
+	block := &proto.Block{}
+	for _, line := range strings.Split(strings.TrimSpace(string(schema)), "\n") {
+		name, typ, _ := strings.Cut(line, "\t")
+		require.NoError(t, block.AddColumn(name, column.Type(typ)))
+	}
+	return block
+}
+
+func testEvents() []Event {
+	var (
+		referrer = "https://example.com"
+		at       = time.Date(2024, 5, 6, 7, 8, 9, 123000000, time.UTC)
+	)
+	return []Event{
+		{
+			Base:     Base{ID: 1},
+			UserName: "alice",
+			Home:     Address{City: "Amsterdam", Zip: "1011"},
+			Score:    1.5,
+			Active:   true,
+			Day:      time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC),
+			At:       at,
+			Key:      uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8"),
+			Referrer: &referrer,
+			Tags:     []string{"a", "b"},
+			Attrs:    map[string]string{"k": "v"},
+		},
+		{
+			Base:     Base{ID: 2},
+			UserName: "bob",
+			Home:     Address{City: "Berlin", Zip: "10115"},
+			Day:      time.Date(2024, 5, 7, 0, 0, 0, 0, time.UTC),
+			At:       at.Add(time.Second),
+			Tags:     []string{},
+			Attrs:    map[string]string{},
+		},
+	}
+}
+
+func TestEventRoundTrip(t *testing.T) {
+	block := newBlock(t)
+	events := testEvents()
+	for i := range events {
+		require.NoError(t, events[i].AppendTo(block.Columns))
+	}
+	require.Equal(t, len(events), block.Rows())
+
+	var buffer chproto.Buffer
+	require.NoError(t, block.Encode(&buffer, proto.DBMS_TCP_PROTOCOL_VERSION))
+	decoded := &proto.Block{}
+	require.NoError(t, decoded.Decode(chproto.NewReader(bytes.NewReader(buffer.Buf)), proto.DBMS_TCP_PROTOCOL_VERSION))
+
+	for i, expected := range events {
+		var event Event
+		require.NoError(t, event.ScanFrom(decoded.Columns, i))
+		assert.Equal(t, expected, event)
+	}
+}
+
+func TestEventColumnMismatch(t *testing.T) {
+	block := newBlock(t)
+	event := testEvents()[0]
+	assert.ErrorContains(t, event.AppendTo(block.Columns[1:]), "expected the 12 columns of *Event, got 11")
+
+	block.Columns[0], block.Columns[1] = block.Columns[1], block.Columns[0]
+	assert.ErrorContains(t, event.AppendTo(block.Columns), "column 0 is user_name String, *Event expects id UInt64")
+	assert.Equal(t, 0, block.Rows())
+	assert.ErrorContains(t, event.ScanFrom(block.Columns, 0), "column 0 is user_name String, *Event expects id UInt64")
+}
@@ -0,0 +1,71 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package structtag maps Go struct fields to columns the same way for the driver and for
+// the code generated by lib/structgen.
+package structtag
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tag is a parsed ch struct tag: `ch:"name,prefix=addr_,type=DateTime64(3)"`.
+// The type option must come last as type expressions may contain commas.
+type Tag struct {
+	Name      string
+	Prefix    string
+	HasPrefix bool
+	Type      string
+}
+
+// Parse parses the value of a ch struct tag.
+func Parse(tag string) Tag {
+	name, opts, _ := strings.Cut(tag, ",")
+	t := Tag{Name: name}
+	for len(opts) != 0 {
+		if chType, ok := strings.CutPrefix(opts, "type="); ok {
+			t.Type = strings.TrimSpace(chType)
+			break
+		}
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if prefix, ok := strings.CutPrefix(opt, "prefix="); ok {
+			t.Prefix, t.HasPrefix = prefix, true
+		}
+	}
+	return t
+}
+
+// SnakeCase maps field names such as UserID to user_id.
+func SnakeCase(field string) string {
+	var (
+		runes = []rune(field)
+		out   = make([]rune, 0, len(runes)+4)
+	)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				out = append(out, '_')
+			}
+			r = unicode.ToLower(r)
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
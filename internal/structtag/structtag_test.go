@@ -0,0 +1,31 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package structtag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	assert.Equal(t, Tag{Name: "col"}, Parse("col"))
+	assert.Equal(t, Tag{Prefix: "addr_", HasPrefix: true}, Parse(",prefix=addr_"))
+	assert.Equal(t, Tag{Name: "ts", Type: "DateTime64(3, 'UTC')"}, Parse("ts,type=DateTime64(3, 'UTC')"))
+	assert.Equal(t, Tag{Name: "-"}, Parse("-"))
+}
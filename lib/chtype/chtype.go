@@ -0,0 +1,265 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package chtype parses ClickHouse type expressions such as
+// Array(Nullable(DateTime64(3, 'UTC'))) into a syntax tree and prints them back in canonical form.
+package chtype
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ArgKind is the kind of a type argument.
+type ArgKind uint8
+
+const (
+	TypeArg       ArgKind = iota // nested type, named when Name is set: Tuple(a String), JSON(a.b UInt32)
+	NumberArg                    // numeric literal: FixedString(16), Decimal(18, 4)
+	StringArg                    // string literal: DateTime('UTC'), Enum8('a')
+	EnumArg                      // enum value: Enum8('a' = 1)
+	SettingArg                   // setting: JSON(max_dynamic_paths=16)
+	SkipArg                      // JSON path hint: JSON(SKIP a.b)
+	SkipRegexpArg                // JSON path hint: JSON(SKIP REGEXP 'a.*')
+)
+
+// Type is a parsed ClickHouse type expression.
+type Type struct {
+	Name string
+	Args []Arg
+}
+
+// Arg is an argument of a parameterized type.
+type Arg struct {
+	Kind ArgKind
+	// Name is the element name of a named TypeArg, the value of an EnumArg,
+	// the setting of a SettingArg or the path of a SkipArg.
+	Name string
+	// Type is set for TypeArg.
+	Type *Type
+	// Value is the literal of NumberArg, SettingArg and EnumArg, and the unquoted
+	// string of StringArg and SkipRegexpArg.
+	Value string
+}
+
+// EnumValue is a named value of an Enum8 or Enum16 type.
+type EnumValue struct {
+	Name  string
+	Value int
+}
+
+// String returns the canonical form of t.
+func (t *Type) String() string {
+	var b strings.Builder
+	t.write(&b)
+	return b.String()
+}
+
+func (t *Type) write(b *strings.Builder) {
+	b.WriteString(t.Name)
+	if len(t.Args) == 0 {
+		return
+	}
+	b.WriteByte('(')
+	for i, arg := range t.Args {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		arg.write(b)
+	}
+	b.WriteByte(')')
+}
+
+// String returns the canonical form of a.
+func (a Arg) String() string {
+	var b strings.Builder
+	a.write(&b)
+	return b.String()
+}
+
+func (a Arg) write(b *strings.Builder) {
+	switch a.Kind {
+	case TypeArg:
+		if len(a.Name) != 0 {
+			b.WriteString(quoteName(a.Name))
+			b.WriteByte(' ')
+		}
+		a.Type.write(b)
+	case NumberArg:
+		b.WriteString(a.Value)
+	case StringArg:
+		b.WriteString(quoteString(a.Value))
+	case EnumArg:
+		b.WriteString(quoteString(a.Name))
+		b.WriteString(" = ")
+		b.WriteString(a.Value)
+	case SettingArg:
+		b.WriteString(a.Name)
+		b.WriteByte('=')
+		b.WriteString(a.Value)
+	case SkipArg:
+		b.WriteString("SKIP ")
+		b.WriteString(quoteName(a.Name))
+	case SkipRegexpArg:
+		b.WriteString("SKIP REGEXP ")
+		b.WriteString(quoteString(a.Value))
+	}
+}
+
+// TypeArgs returns the nested type arguments of t, e.g. the elements of a Tuple
+// or the key and value of a Map.
+func (t *Type) TypeArgs() []Arg {
+	var args []Arg
+	for _, arg := range t.Args {
+		if arg.Kind == TypeArg {
+			args = append(args, arg)
+		}
+	}
+	return args
+}
+
+// Elem returns the first nested type of t, e.g. the element type of Array, Nullable
+// and LowCardinality, or nil if there is none.
+func (t *Type) Elem() *Type {
+	for _, arg := range t.Args {
+		if arg.Kind == TypeArg {
+			return arg.Type
+		}
+	}
+	return nil
+}
+
+// IsNullable reports whether t is Nullable or LowCardinality(Nullable).
+func (t *Type) IsNullable() bool {
+	switch t.Name {
+	case "Nullable":
+		return true
+	case "LowCardinality":
+		if elem := t.Elem(); elem != nil {
+			return elem.Name == "Nullable"
+		}
+	}
+	return false
+}
+
+// Unwrap returns t without its LowCardinality and Nullable wrappers.
+func (t *Type) Unwrap() *Type {
+	for (t.Name == "LowCardinality" || t.Name == "Nullable") && t.Elem() != nil {
+		t = t.Elem()
+	}
+	return t
+}
+
+// Precision returns the precision of Decimal and DateTime64 types.
+func (t *Type) Precision() (int, bool) {
+	switch t.Name {
+	case "Decimal", "DateTime64", "Time64":
+		return t.number(0)
+	case "Decimal32":
+		return 9, true
+	case "Decimal64":
+		return 18, true
+	case "Decimal128":
+		return 38, true
+	case "Decimal256":
+		return 76, true
+	}
+	return 0, false
+}
+
+// Scale returns the scale of Decimal types.
+func (t *Type) Scale() (int, bool) {
+	switch t.Name {
+	case "Decimal":
+		if len(t.Args) == 1 {
+			return 0, true
+		}
+		return t.number(1)
+	case "Decimal32", "Decimal64", "Decimal128", "Decimal256":
+		return t.number(0)
+	}
+	return 0, false
+}
+
+// Length returns the length of FixedString types.
+func (t *Type) Length() (int, bool) {
+	if t.Name == "FixedString" {
+		return t.number(0)
+	}
+	return 0, false
+}
+
+// Timezone returns the timezone of DateTime and DateTime64 types, or an empty string.
+func (t *Type) Timezone() string {
+	for _, arg := range t.Args {
+		if arg.Kind == StringArg && (t.Name == "DateTime" || t.Name == "DateTime64") {
+			return arg.Value
+		}
+	}
+	return ""
+}
+
+// EnumValues returns the values of Enum8 and Enum16 types. Values without an explicit
+// number are numbered from 1 or from the previous value, as ClickHouse does.
+func (t *Type) EnumValues() []EnumValue {
+	if t.Name != "Enum8" && t.Name != "Enum16" && t.Name != "Enum" {
+		return nil
+	}
+	var (
+		values = make([]EnumValue, 0, len(t.Args))
+		next   = 1
+	)
+	for _, arg := range t.Args {
+		switch arg.Kind {
+		case EnumArg:
+			v, err := strconv.Atoi(arg.Value)
+			if err != nil {
+				return nil
+			}
+			values, next = append(values, EnumValue{Name: arg.Name, Value: v}), v+1
+		case StringArg:
+			values = append(values, EnumValue{Name: arg.Value, Value: next})
+			next++
+		}
+	}
+	return values
+}
+
+// number returns the i-th argument of t as an integer
+func (t *Type) number(i int) (int, bool) {
+	if i >= len(t.Args) || t.Args[i].Kind != NumberArg {
+		return 0, false
+	}
+	n, err := strconv.Atoi(t.Args[i].Value)
+	return n, err == nil
+}
+
+func quoteString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func quoteName(name string) string {
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; !isIdentChar(c) && c != '.' || i == 0 && '0' <= c && c <= '9' {
+			return "`" + strings.NewReplacer("\\", "\\\\", "`", "\\`").Replace(name) + "`"
+		}
+	}
+	if len(name) == 0 {
+		return "``"
+	}
+	return name
+}
@@ -0,0 +1,134 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package chtype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCanonical(t *testing.T) {
+	for expr, canonical := range map[string]string{
+		"String":                                    "String",
+		"Array( Nullable(String) )":                 "Array(Nullable(String))",
+		"DateTime64(3,'UTC')":                       "DateTime64(3, 'UTC')",
+		"Decimal(18,4)":                             "Decimal(18, 4)",
+		"Map(String,Array(UInt64))":                 "Map(String, Array(UInt64))",
+		"Map(DateTime64(3, 'UTC'), Int8)":           "Map(DateTime64(3, 'UTC'), Int8)",
+		"Tuple(a String,b Tuple(c Int8))":           "Tuple(a String, b Tuple(c Int8))",
+		"Tuple(`a b` String, `1x` Int8)":            "Tuple(`a b` String, `1x` Int8)",
+		"Tuple(String, Int64)":                      "Tuple(String, Int64)",
+		"Enum8('a'=1,'b, c' = -2)":                  "Enum8('a' = 1, 'b, c' = -2)",
+		`Enum8('it\'s' = 1)`:                        `Enum8('it\'s' = 1)`,
+		"Enum16('a', 'b')":                          "Enum16('a', 'b')",
+		"LowCardinality(Nullable(FixedString(16)))": "LowCardinality(Nullable(FixedString(16)))",
+		"JSON(max_dynamic_paths=16, a.b UInt32, SKIP a.c, SKIP REGEXP 'x.*')": "JSON(max_dynamic_paths=16, a.b UInt32, SKIP a.c, SKIP REGEXP 'x.*')",
+		"JSON":           "JSON",
+		"Object('json')": "Object('json')",
+		"AggregateFunction(quantiles(0.5, 0.9), UInt64)": "AggregateFunction(quantiles(0.5, 0.9), UInt64)",
+		"Nested(a String, b Array(Int8))":                "Nested(a String, b Array(Int8))",
+		"Variant(String, UInt64)":                        "Variant(String, UInt64)",
+	} {
+		typ, err := Parse(expr)
+		require.NoError(t, err, expr)
+		assert.Equal(t, canonical, typ.String(), expr)
+
+		again, err := Parse(typ.String())
+		require.NoError(t, err, canonical)
+		assert.Equal(t, typ, again, canonical)
+	}
+}
+
+func TestParseAST(t *testing.T) {
+	typ, err := Parse("Tuple(a LowCardinality(Nullable(String)), b DateTime64(6, 'Europe/Berlin'))")
+	require.NoError(t, err)
+	assert.Equal(t, &Type{
+		Name: "Tuple",
+		Args: []Arg{
+			{Kind: TypeArg, Name: "a", Type: &Type{Name: "LowCardinality", Args: []Arg{
+				{Kind: TypeArg, Type: &Type{Name: "Nullable", Args: []Arg{
+					{Kind: TypeArg, Type: &Type{Name: "String"}},
+				}}},
+			}}},
+			{Kind: TypeArg, Name: "b", Type: &Type{Name: "DateTime64", Args: []Arg{
+				{Kind: NumberArg, Value: "6"},
+				{Kind: StringArg, Value: "Europe/Berlin"},
+			}}},
+		},
+	}, typ)
+
+	elements := typ.TypeArgs()
+	require.Len(t, elements, 2)
+	assert.True(t, elements[0].Type.IsNullable())
+	assert.Equal(t, "String", elements[0].Type.Unwrap().String())
+	assert.Equal(t, "Europe/Berlin", elements[1].Type.Timezone())
+	precision, ok := elements[1].Type.Precision()
+	assert.True(t, ok)
+	assert.Equal(t, 6, precision)
+}
+
+func TestTypeAccessors(t *testing.T) {
+	for expr, expected := range map[string][2]int{
+		"Decimal(18, 4)": {18, 4},
+		"Decimal(10)":    {10, 0},
+		"Decimal32(3)":   {9, 3},
+		"Decimal256(20)": {76, 20},
+	} {
+		typ := MustParse(expr)
+		precision, ok := typ.Precision()
+		assert.True(t, ok, expr)
+		scale, ok := typ.Scale()
+		assert.True(t, ok, expr)
+		assert.Equal(t, expected, [2]int{precision, scale}, expr)
+	}
+
+	length, ok := MustParse("FixedString(16)").Length()
+	assert.True(t, ok)
+	assert.Equal(t, 16, length)
+	_, ok = MustParse("String").Length()
+	assert.False(t, ok)
+
+	assert.Equal(t, "UTC", MustParse("DateTime('UTC')").Timezone())
+	assert.Equal(t, "", MustParse("DateTime").Timezone())
+	assert.Equal(t, "UInt8", MustParse("Array(UInt8)").Elem().String())
+	assert.Nil(t, MustParse("String").Elem())
+	assert.False(t, MustParse("LowCardinality(String)").IsNullable())
+
+	assert.Equal(t, []EnumValue{{"a", 1}, {"b", 2}}, MustParse("Enum8('a', 'b')").EnumValues())
+	assert.Equal(t, []EnumValue{{"a", -1}, {"b", 5}}, MustParse("Enum16('a' = -1, 'b' = 5)").EnumValues())
+	assert.Nil(t, MustParse("String").EnumValues())
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"Array(String",
+		"Array(String))",
+		"Enum8('a' = )",
+		"Enum8('a)",
+		"Tuple(a)b",
+		"JSON(SKIP )",
+		"(String)",
+	} {
+		_, err := Parse(expr)
+		var syntaxErr *SyntaxError
+		assert.ErrorAs(t, err, &syntaxErr, expr)
+	}
+}
@@ -0,0 +1,322 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package chtype
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError is returned by Parse for malformed type expressions.
+type SyntaxError struct {
+	Expr   string
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("chtype: %s at offset %d in %q", e.Msg, e.Offset, e.Expr)
+}
+
+// Parse parses a ClickHouse type expression.
+func Parse(expr string) (*Type, error) {
+	p := parser{src: expr}
+	t, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos != len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+	return t, nil
+}
+
+// MustParse is like Parse but panics if expr cannot be parsed.
+func MustParse(expr string) *Type {
+	t, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{
+		Expr:   p.src,
+		Offset: p.pos,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t' || p.src[p.pos] == '\n' || p.src[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+func (p *parser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+func (p *parser) parseType() (*Type, error) {
+	p.skipSpace()
+	name := p.ident(false)
+	if len(name) == 0 {
+		return nil, p.errorf("expected type name")
+	}
+	t := &Type{Name: name}
+	if p.skipSpace(); p.peek() != '(' {
+		return t, nil
+	}
+	p.pos++
+	if p.skipSpace(); p.peek() == ')' {
+		p.pos++
+		return t, nil
+	}
+	for {
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		t.Args = append(t.Args, arg)
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return t, nil
+		case 0:
+			return nil, p.errorf("missing closing parenthesis")
+		default:
+			return nil, p.errorf("unexpected %q", p.src[p.pos:p.pos+1])
+		}
+	}
+}
+
+func (p *parser) parseArg() (Arg, error) {
+	p.skipSpace()
+	switch c := p.peek(); {
+	case c == '\'':
+		s, err := p.string()
+		if err != nil {
+			return Arg{}, err
+		}
+		if p.skipSpace(); p.peek() != '=' {
+			return Arg{Kind: StringArg, Value: s}, nil
+		}
+		p.pos++
+		p.skipSpace()
+		n := p.number()
+		if len(n) == 0 {
+			return Arg{}, p.errorf("expected enum value number")
+		}
+		return Arg{Kind: EnumArg, Name: s, Value: n}, nil
+	case c == '-' || c == '+' || isDigit(c):
+		n := p.number()
+		if len(n) == 0 {
+			return Arg{}, p.errorf("invalid number")
+		}
+		return Arg{Kind: NumberArg, Value: n}, nil
+	case c == '`' || c == '"':
+		name, err := p.quotedIdent()
+		if err != nil {
+			return Arg{}, err
+		}
+		t, err := p.parseType()
+		if err != nil {
+			return Arg{}, err
+		}
+		return Arg{Kind: TypeArg, Name: name, Type: t}, nil
+	case isIdentStart(c):
+		start := p.pos
+		word := p.ident(true)
+		if strings.EqualFold(word, "SKIP") && p.peekSpace() {
+			return p.parseSkip()
+		}
+		p.skipSpace()
+		switch c := p.peek(); {
+		case c == '=':
+			p.pos++
+			p.skipSpace()
+			value, err := p.literal()
+			if err != nil {
+				return Arg{}, err
+			}
+			return Arg{Kind: SettingArg, Name: word, Value: value}, nil
+		case c == '(' || c == ',' || c == ')' || c == 0:
+			// an unnamed type
+			p.pos = start
+			t, err := p.parseType()
+			if err != nil {
+				return Arg{}, err
+			}
+			return Arg{Kind: TypeArg, Type: t}, nil
+		}
+		t, err := p.parseType()
+		if err != nil {
+			return Arg{}, err
+		}
+		return Arg{Kind: TypeArg, Name: word, Type: t}, nil
+	}
+	return Arg{}, p.errorf("unexpected %q", p.src[p.pos:min(p.pos+1, len(p.src))])
+}
+
+func (p *parser) parseSkip() (Arg, error) {
+	p.skipSpace()
+	start := p.pos
+	if strings.EqualFold(p.ident(false), "REGEXP") && p.peekSpace() {
+		p.skipSpace()
+		s, err := p.string()
+		if err != nil {
+			return Arg{}, err
+		}
+		return Arg{Kind: SkipRegexpArg, Value: s}, nil
+	}
+	p.pos = start
+	var (
+		path string
+		err  error
+	)
+	switch c := p.peek(); {
+	case c == '`' || c == '"':
+		path, err = p.quotedIdent()
+	case isIdentStart(c):
+		path = p.ident(true)
+	default:
+		err = p.errorf("expected path after SKIP")
+	}
+	if err != nil {
+		return Arg{}, err
+	}
+	return Arg{Kind: SkipArg, Name: path}, nil
+}
+
+func (p *parser) peekSpace() bool {
+	c := p.peek()
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// ident reads an identifier, with path set dotted paths such as a.b.c are allowed
+func (p *parser) ident(path bool) string {
+	start := p.pos
+	if !isIdentStart(p.peek()) {
+		return ""
+	}
+	for p.pos < len(p.src) && (isIdentChar(p.src[p.pos]) || path && p.src[p.pos] == '.') {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *parser) quotedIdent() (string, error) {
+	return p.quoted(p.peek())
+}
+
+func (p *parser) string() (string, error) {
+	return p.quoted('\'')
+}
+
+// quoted reads a literal enclosed in q, backslash escapes and doubled quotes are supported
+func (p *parser) quoted(q byte) (string, error) {
+	start := p.pos
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == '\\' && p.pos+1 < len(p.src):
+			b.WriteByte(p.src[p.pos+1])
+			p.pos += 2
+		case c == q && p.pos+1 < len(p.src) && p.src[p.pos+1] == q:
+			b.WriteByte(q)
+			p.pos += 2
+		case c == q:
+			p.pos++
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	p.pos = start
+	return "", p.errorf("unterminated quoted literal")
+}
+
+func (p *parser) number() string {
+	start := p.pos
+	if c := p.peek(); c == '-' || c == '+' {
+		p.pos++
+	}
+	digits := p.pos
+	for p.pos < len(p.src) && (isDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+		p.pos++
+	}
+	if p.pos == digits {
+		p.pos = start
+		return ""
+	}
+	if c := p.peek(); c == 'e' || c == 'E' {
+		p.pos++
+		if c := p.peek(); c == '-' || c == '+' {
+			p.pos++
+		}
+		for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+			p.pos++
+		}
+	}
+	return p.src[start:p.pos]
+}
+
+// literal reads a setting value: a number, a string or an identifier, returned as written
+func (p *parser) literal() (string, error) {
+	switch c := p.peek(); {
+	case c == '\'':
+		start := p.pos
+		if _, err := p.string(); err != nil {
+			return "", err
+		}
+		return p.src[start:p.pos], nil
+	case c == '-' || c == '+' || isDigit(c):
+		if n := p.number(); len(n) != 0 {
+			return n, nil
+		}
+	case isIdentStart(c):
+		return p.ident(false), nil
+	}
+	return "", p.errorf("expected setting value")
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
@@ -21,8 +21,8 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 	"reflect"
-	"strings"
 	"time"
 )
 
@@ -55,21 +55,18 @@ func (col *Array) Name() string {
 
 func (col *Array) parse(t Type, tz *time.Location) (_ *Array, err error) {
 	col.chType = t
-	var typeStr = string(t)
-
-parse:
-	for {
-		switch {
-		case strings.HasPrefix(typeStr, "Array("):
-			col.depth++
-			typeStr = strings.TrimPrefix(typeStr, "Array(")
-			typeStr = strings.TrimSuffix(typeStr, ")")
-		default:
-			break parse
+	parsed, err := chtype.Parse(string(t))
+	if err != nil {
+		return nil, &UnsupportedColumnTypeError{
+			t: t,
 		}
 	}
+	for parsed.Name == "Array" && parsed.Elem() != nil {
+		col.depth++
+		parsed = parsed.Elem()
+	}
 	if col.depth != 0 {
-		if col.values, err = Type(typeStr).Column(col.name, tz); err != nil {
+		if col.values, err = Type(parsed.String()).Column(col.name, tz); err != nil {
 			return nil, err
 		}
 		offsetScanTypes := make([]reflect.Type, 0, col.depth)
@@ -252,6 +249,55 @@ func (col *Array) Encode(buffer *proto.Buffer) {
 	col.values.Encode(buffer)
 }
 
+func (col *Array) DecodeRowBinary(reader *proto.Reader) error {
+	return col.decodeRowBinary(reader, 0)
+}
+
+func (col *Array) decodeRowBinary(reader *proto.Reader, level int) error {
+	n, err := reader.UVarInt()
+	if err != nil {
+		return err
+	}
+	col.appendOffset(level, n)
+	for i := uint64(0); i < n; i++ {
+		if level == len(col.offsets)-1 {
+			err = col.values.DecodeRowBinary(reader)
+		} else {
+			err = col.decodeRowBinary(reader, level+1)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (col *Array) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	return col.encodeRowBinary(buffer, 0, row)
+}
+
+func (col *Array) encodeRowBinary(buffer *proto.Buffer, level, row int) error {
+	offsets := col.offsets[level].values.col
+	var start uint64
+	if row > 0 {
+		start = offsets.Row(row - 1)
+	}
+	end := offsets.Row(row)
+	buffer.PutUVarInt(end - start)
+	for i := int(start); i < int(end); i++ {
+		var err error
+		if level == len(col.offsets)-1 {
+			err = col.values.EncodeRowBinary(buffer, i)
+		} else {
+			err = col.encodeRowBinary(buffer, level+1, i)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (col *Array) ReadStatePrefix(reader *proto.Reader) error {
 	if serialize, ok := col.values.(CustomSerialization); ok {
 		if err := serialize.ReadStatePrefix(reader); err != nil {
@@ -162,6 +162,63 @@ func (col *BigInt) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *BigInt) DecodeRowBinary(reader *proto.Reader) error {
+	switch vCol := col.col.(type) {
+	case *proto.ColInt128:
+		v, err := reader.Int128()
+		if err != nil {
+			return err
+		}
+		vCol.Append(v)
+	case *proto.ColUInt128:
+		v, err := reader.UInt128()
+		if err != nil {
+			return err
+		}
+		vCol.Append(v)
+	case *proto.ColInt256:
+		low, high, err := readUInt256Halves(reader)
+		if err != nil {
+			return err
+		}
+		vCol.Append(proto.Int256{Low: low, High: high})
+	case *proto.ColUInt256:
+		low, high, err := readUInt256Halves(reader)
+		if err != nil {
+			return err
+		}
+		vCol.Append(proto.UInt256{Low: low, High: high})
+	}
+	return nil
+}
+
+func (col *BigInt) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	switch vCol := col.col.(type) {
+	case *proto.ColInt128:
+		buffer.PutInt128(vCol.Row(row))
+	case *proto.ColUInt128:
+		buffer.PutUInt128(vCol.Row(row))
+	case *proto.ColInt256:
+		v := vCol.Row(row)
+		buffer.PutUInt128(v.Low)
+		buffer.PutUInt128(v.High)
+	case *proto.ColUInt256:
+		v := vCol.Row(row)
+		buffer.PutUInt128(v.Low)
+		buffer.PutUInt128(v.High)
+	}
+	return nil
+}
+
+// readUInt256Halves reads a 256 bits value, low half first
+func readUInt256Halves(reader *proto.Reader) (low, high proto.UInt128, err error) {
+	if low, err = reader.UInt128(); err != nil {
+		return low, high, err
+	}
+	high, err = reader.UInt128()
+	return low, high, err
+}
+
 func (col *BigInt) row(i int) *big.Int {
 	b := make([]byte, col.size)
 	switch vCol := col.col.(type) {
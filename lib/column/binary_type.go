@@ -0,0 +1,574 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package column
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
+)
+
+// Binary encoding of data types, written before the values of Dynamic columns in RowBinary.
+// https://clickhouse.com/docs/en/sql-reference/data-types/data-types-binary-encoding
+const (
+	binaryTypeNothing         = 0x00
+	binaryTypeDateTime        = 0x11
+	binaryTypeDateTimeZone    = 0x12
+	binaryTypeDateTime64      = 0x13
+	binaryTypeDateTime64Zone  = 0x14
+	binaryTypeFixedString     = 0x16
+	binaryTypeEnum8           = 0x17
+	binaryTypeEnum16          = 0x18
+	binaryTypeDecimal32       = 0x19
+	binaryTypeDecimal64       = 0x1A
+	binaryTypeDecimal128      = 0x1B
+	binaryTypeDecimal256      = 0x1C
+	binaryTypeArray           = 0x1E
+	binaryTypeTuple           = 0x1F
+	binaryTypeNamedTuple      = 0x20
+	binaryTypeInterval        = 0x22
+	binaryTypeNullable        = 0x23
+	binaryTypeLowCardinality  = 0x26
+	binaryTypeMap             = 0x27
+	binaryTypeVariant         = 0x2A
+	binaryTypeDynamic         = 0x2B
+	binaryTypeCustom          = 0x2C
+	binaryTypeSimpleAggregate = 0x2E
+	binaryTypeNested          = 0x2F
+	binaryTypeJSON            = 0x30
+	binaryTypeTime64          = 0x34
+)
+
+// binaryTypes are the codes of the types without parameters
+var binaryTypes = map[string]byte{
+	"Nothing":  binaryTypeNothing,
+	"UInt8":    0x01,
+	"UInt16":   0x02,
+	"UInt32":   0x03,
+	"UInt64":   0x04,
+	"UInt128":  0x05,
+	"UInt256":  0x06,
+	"Int8":     0x07,
+	"Int16":    0x08,
+	"Int32":    0x09,
+	"Int64":    0x0A,
+	"Int128":   0x0B,
+	"Int256":   0x0C,
+	"Float32":  0x0D,
+	"Float64":  0x0E,
+	"Date":     0x0F,
+	"Date32":   0x10,
+	"String":   0x15,
+	"UUID":     0x1D,
+	"IPv4":     0x28,
+	"IPv6":     0x29,
+	"Bool":     0x2D,
+	"BFloat16": 0x31,
+	"Time":     0x32,
+}
+
+var binaryTypeNames = func() map[byte]string {
+	names := make(map[byte]string, len(binaryTypes))
+	for name, code := range binaryTypes {
+		names[code] = name
+	}
+	return names
+}()
+
+// binaryIntervalKinds are the codes of the units of Interval types
+var binaryIntervalKinds = map[string]byte{
+	"Nanosecond":  0x00,
+	"Microsecond": 0x01,
+	"Millisecond": 0x02,
+	"Second":      0x03,
+	"Minute":      0x04,
+	"Hour":        0x05,
+	"Day":         0x06,
+	"Week":        0x07,
+	"Month":       0x08,
+	"Quarter":     0x09,
+	"Year":        0x0A,
+}
+
+// binaryCustomTypes are encoded by name
+var binaryCustomTypes = map[string]struct{}{
+	"Point":           {},
+	"Ring":            {},
+	"Polygon":         {},
+	"MultiPolygon":    {},
+	"LineString":      {},
+	"MultiLineString": {},
+}
+
+// encodeBinaryType writes the binary encoding of t
+func encodeBinaryType(buffer *proto.Buffer, t *chtype.Type) error {
+	if code, ok := binaryTypes[t.Name]; ok && len(t.Args) == 0 {
+		buffer.PutByte(code)
+		return nil
+	}
+	if _, ok := binaryCustomTypes[t.Name]; ok && len(t.Args) == 0 {
+		buffer.PutByte(binaryTypeCustom)
+		buffer.PutString(t.Name)
+		return nil
+	}
+	if kind, ok := binaryIntervalKinds[strings.TrimPrefix(t.Name, "Interval")]; ok && len(t.Args) == 0 {
+		buffer.PutByte(binaryTypeInterval)
+		buffer.PutByte(kind)
+		return nil
+	}
+	switch t.Name {
+	case "DateTime":
+		if tz := t.Timezone(); len(tz) != 0 {
+			buffer.PutByte(binaryTypeDateTimeZone)
+			buffer.PutString(tz)
+			return nil
+		}
+		buffer.PutByte(binaryTypeDateTime)
+		return nil
+	case "DateTime64":
+		precision, ok := t.Precision()
+		if !ok {
+			break
+		}
+		if tz := t.Timezone(); len(tz) != 0 {
+			buffer.PutByte(binaryTypeDateTime64Zone)
+			buffer.PutUInt8(uint8(precision))
+			buffer.PutString(tz)
+			return nil
+		}
+		buffer.PutByte(binaryTypeDateTime64)
+		buffer.PutUInt8(uint8(precision))
+		return nil
+	case "Time64":
+		precision, ok := t.Precision()
+		if !ok {
+			break
+		}
+		buffer.PutByte(binaryTypeTime64)
+		buffer.PutUInt8(uint8(precision))
+		return nil
+	case "FixedString":
+		length, ok := t.Length()
+		if !ok {
+			break
+		}
+		buffer.PutByte(binaryTypeFixedString)
+		buffer.PutUVarInt(uint64(length))
+		return nil
+	case "Enum8", "Enum16":
+		values := t.EnumValues()
+		if t.Name == "Enum8" {
+			buffer.PutByte(binaryTypeEnum8)
+		} else {
+			buffer.PutByte(binaryTypeEnum16)
+		}
+		buffer.PutUVarInt(uint64(len(values)))
+		for _, v := range values {
+			buffer.PutString(v.Name)
+			if t.Name == "Enum8" {
+				buffer.PutInt8(int8(v.Value))
+			} else {
+				buffer.PutInt16(int16(v.Value))
+			}
+		}
+		return nil
+	case "Decimal", "Decimal32", "Decimal64", "Decimal128", "Decimal256":
+		precision, ok := t.Precision()
+		scale, okScale := t.Scale()
+		if !ok || !okScale {
+			break
+		}
+		switch {
+		case precision <= 9:
+			buffer.PutByte(binaryTypeDecimal32)
+		case precision <= 18:
+			buffer.PutByte(binaryTypeDecimal64)
+		case precision <= 38:
+			buffer.PutByte(binaryTypeDecimal128)
+		default:
+			buffer.PutByte(binaryTypeDecimal256)
+		}
+		buffer.PutUInt8(uint8(precision))
+		buffer.PutUInt8(uint8(scale))
+		return nil
+	case "Array", "Nullable", "LowCardinality":
+		elem := t.Elem()
+		if elem == nil {
+			break
+		}
+		switch t.Name {
+		case "Array":
+			buffer.PutByte(binaryTypeArray)
+		case "Nullable":
+			buffer.PutByte(binaryTypeNullable)
+		default:
+			buffer.PutByte(binaryTypeLowCardinality)
+		}
+		return encodeBinaryType(buffer, elem)
+	case "Map":
+		args := t.TypeArgs()
+		if len(args) != 2 {
+			break
+		}
+		buffer.PutByte(binaryTypeMap)
+		if err := encodeBinaryType(buffer, args[0].Type); err != nil {
+			return err
+		}
+		return encodeBinaryType(buffer, args[1].Type)
+	case "Tuple", "Nested", "Variant":
+		args := t.TypeArgs()
+		named := len(args) != 0
+		for _, arg := range args {
+			named = named && len(arg.Name) != 0
+		}
+		switch {
+		case t.Name == "Variant":
+			buffer.PutByte(binaryTypeVariant)
+		case t.Name == "Nested":
+			buffer.PutByte(binaryTypeNested)
+		case named:
+			buffer.PutByte(binaryTypeNamedTuple)
+		default:
+			buffer.PutByte(binaryTypeTuple)
+		}
+		buffer.PutUVarInt(uint64(len(args)))
+		for _, arg := range args {
+			if t.Name == "Nested" || t.Name == "Tuple" && named {
+				buffer.PutString(arg.Name)
+			}
+			if err := encodeBinaryType(buffer, arg.Type); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "Dynamic":
+		maxTypes := DefaultMaxDynamicTypes
+		for _, arg := range t.Args {
+			if arg.Kind == chtype.SettingArg && arg.Name == "max_types" {
+				maxTypes, _ = strconv.Atoi(arg.Value)
+			}
+		}
+		buffer.PutByte(binaryTypeDynamic)
+		buffer.PutUInt8(uint8(maxTypes))
+		return nil
+	case "JSON":
+		var (
+			maxPaths                 = DefaultMaxDynamicPaths
+			maxTypes                 = DefaultMaxDynamicTypes
+			typed, skip, skipRegexps []chtype.Arg
+		)
+		for _, arg := range t.Args {
+			switch arg.Kind {
+			case chtype.SettingArg:
+				switch arg.Name {
+				case "max_dynamic_paths":
+					maxPaths, _ = strconv.Atoi(arg.Value)
+				case "max_dynamic_types":
+					maxTypes, _ = strconv.Atoi(arg.Value)
+				}
+			case chtype.TypeArg:
+				typed = append(typed, arg)
+			case chtype.SkipArg:
+				skip = append(skip, arg)
+			case chtype.SkipRegexpArg:
+				skipRegexps = append(skipRegexps, arg)
+			}
+		}
+		buffer.PutByte(binaryTypeJSON)
+		buffer.PutUInt8(0) // serialization version of the type
+		buffer.PutUVarInt(uint64(maxPaths))
+		buffer.PutUInt8(uint8(maxTypes))
+		buffer.PutUVarInt(uint64(len(typed)))
+		for _, arg := range typed {
+			buffer.PutString(arg.Name)
+			if err := encodeBinaryType(buffer, arg.Type); err != nil {
+				return err
+			}
+		}
+		buffer.PutUVarInt(uint64(len(skip)))
+		for _, arg := range skip {
+			buffer.PutString(arg.Name)
+		}
+		buffer.PutUVarInt(uint64(len(skipRegexps)))
+		for _, arg := range skipRegexps {
+			buffer.PutString(arg.Value)
+		}
+		return nil
+	case "SimpleAggregateFunction":
+		args := t.TypeArgs()
+		if len(args) < 2 || len(args[0].Type.Args) != 0 {
+			// the parameters of the function are encoded as fields, which are not supported
+			break
+		}
+		buffer.PutByte(binaryTypeSimpleAggregate)
+		buffer.PutString(args[0].Type.Name)
+		buffer.PutUVarInt(0)
+		buffer.PutUVarInt(uint64(len(args) - 1))
+		for _, arg := range args[1:] {
+			if err := encodeBinaryType(buffer, arg.Type); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return &Error{
+		ColumnType: t.String(),
+		Err:        fmt.Errorf("no binary encoding for type %s", t),
+	}
+}
+
+// decodeBinaryType reads a type in the binary encoding
+func decodeBinaryType(reader *proto.Reader) (*chtype.Type, error) {
+	code, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if name, ok := binaryTypeNames[code]; ok {
+		return &chtype.Type{Name: name}, nil
+	}
+	t := &chtype.Type{}
+	number := func(n int) chtype.Arg {
+		return chtype.Arg{Kind: chtype.NumberArg, Value: strconv.Itoa(n)}
+	}
+	typeArg := func(name string) error {
+		elem, err := decodeBinaryType(reader)
+		if err != nil {
+			return err
+		}
+		t.Args = append(t.Args, chtype.Arg{Kind: chtype.TypeArg, Name: name, Type: elem})
+		return nil
+	}
+	switch code {
+	case binaryTypeDateTime:
+		t.Name = "DateTime"
+	case binaryTypeDateTimeZone:
+		tz, err := reader.Str()
+		if err != nil {
+			return nil, err
+		}
+		t.Name, t.Args = "DateTime", []chtype.Arg{{Kind: chtype.StringArg, Value: tz}}
+	case binaryTypeDateTime64, binaryTypeDateTime64Zone, binaryTypeTime64:
+		precision, err := reader.UInt8()
+		if err != nil {
+			return nil, err
+		}
+		t.Name, t.Args = "DateTime64", []chtype.Arg{number(int(precision))}
+		switch code {
+		case binaryTypeTime64:
+			t.Name = "Time64"
+		case binaryTypeDateTime64Zone:
+			tz, err := reader.Str()
+			if err != nil {
+				return nil, err
+			}
+			t.Args = append(t.Args, chtype.Arg{Kind: chtype.StringArg, Value: tz})
+		}
+	case binaryTypeFixedString:
+		length, err := reader.UVarInt()
+		if err != nil {
+			return nil, err
+		}
+		t.Name, t.Args = "FixedString", []chtype.Arg{number(int(length))}
+	case binaryTypeEnum8, binaryTypeEnum16:
+		t.Name = "Enum8"
+		if code == binaryTypeEnum16 {
+			t.Name = "Enum16"
+		}
+		n, err := reader.UVarInt()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < n; i++ {
+			name, err := reader.Str()
+			if err != nil {
+				return nil, err
+			}
+			var value int
+			if code == binaryTypeEnum8 {
+				v, err := reader.Int8()
+				if err != nil {
+					return nil, err
+				}
+				value = int(v)
+			} else {
+				v, err := reader.Int16()
+				if err != nil {
+					return nil, err
+				}
+				value = int(v)
+			}
+			t.Args = append(t.Args, chtype.Arg{Kind: chtype.EnumArg, Name: name, Value: strconv.Itoa(value)})
+		}
+	case binaryTypeDecimal32, binaryTypeDecimal64, binaryTypeDecimal128, binaryTypeDecimal256:
+		precision, err := reader.UInt8()
+		if err != nil {
+			return nil, err
+		}
+		scale, err := reader.UInt8()
+		if err != nil {
+			return nil, err
+		}
+		t.Name, t.Args = "Decimal", []chtype.Arg{number(int(precision)), number(int(scale))}
+	case binaryTypeArray, binaryTypeNullable, binaryTypeLowCardinality:
+		switch code {
+		case binaryTypeArray:
+			t.Name = "Array"
+		case binaryTypeNullable:
+			t.Name = "Nullable"
+		default:
+			t.Name = "LowCardinality"
+		}
+		if err := typeArg(""); err != nil {
+			return nil, err
+		}
+	case binaryTypeMap:
+		t.Name = "Map"
+		if err := typeArg(""); err != nil {
+			return nil, err
+		}
+		if err := typeArg(""); err != nil {
+			return nil, err
+		}
+	case binaryTypeTuple, binaryTypeNamedTuple, binaryTypeNested, binaryTypeVariant:
+		switch code {
+		case binaryTypeVariant:
+			t.Name = "Variant"
+		case binaryTypeNested:
+			t.Name = "Nested"
+		default:
+			t.Name = "Tuple"
+		}
+		n, err := reader.UVarInt()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < n; i++ {
+			var name string
+			if code == binaryTypeNamedTuple || code == binaryTypeNested {
+				if name, err = reader.Str(); err != nil {
+					return nil, err
+				}
+			}
+			if err := typeArg(name); err != nil {
+				return nil, err
+			}
+		}
+	case binaryTypeInterval:
+		kind, err := reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		for name, k := range binaryIntervalKinds {
+			if k == kind {
+				t.Name = "Interval" + name
+			}
+		}
+		if len(t.Name) == 0 {
+			return nil, fmt.Errorf("unknown interval kind 0x%02x", kind)
+		}
+	case binaryTypeDynamic:
+		maxTypes, err := reader.UInt8()
+		if err != nil {
+			return nil, err
+		}
+		t.Name = "Dynamic"
+		if maxTypes != DefaultMaxDynamicTypes {
+			t.Args = []chtype.Arg{{Kind: chtype.SettingArg, Name: "max_types", Value: strconv.Itoa(int(maxTypes))}}
+		}
+	case binaryTypeCustom:
+		if t.Name, err = reader.Str(); err != nil {
+			return nil, err
+		}
+	case binaryTypeJSON:
+		if _, err := reader.UInt8(); err != nil { // serialization version of the type
+			return nil, err
+		}
+		maxPaths, err := reader.UVarInt()
+		if err != nil {
+			return nil, err
+		}
+		maxTypes, err := reader.UInt8()
+		if err != nil {
+			return nil, err
+		}
+		t.Name = "JSON"
+		if maxPaths != DefaultMaxDynamicPaths {
+			t.Args = append(t.Args, chtype.Arg{Kind: chtype.SettingArg, Name: "max_dynamic_paths", Value: strconv.FormatUint(maxPaths, 10)})
+		}
+		if maxTypes != DefaultMaxDynamicTypes {
+			t.Args = append(t.Args, chtype.Arg{Kind: chtype.SettingArg, Name: "max_dynamic_types", Value: strconv.Itoa(int(maxTypes))})
+		}
+		n, err := reader.UVarInt()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < n; i++ {
+			path, err := reader.Str()
+			if err != nil {
+				return nil, err
+			}
+			if err := typeArg(path); err != nil {
+				return nil, err
+			}
+		}
+		for _, kind := range []chtype.ArgKind{chtype.SkipArg, chtype.SkipRegexpArg} {
+			n, err := reader.UVarInt()
+			if err != nil {
+				return nil, err
+			}
+			for i := uint64(0); i < n; i++ {
+				path, err := reader.Str()
+				if err != nil {
+					return nil, err
+				}
+				if kind == chtype.SkipArg {
+					t.Args = append(t.Args, chtype.Arg{Kind: kind, Name: path})
+				} else {
+					t.Args = append(t.Args, chtype.Arg{Kind: kind, Value: path})
+				}
+			}
+		}
+	case binaryTypeSimpleAggregate:
+		function, err := reader.Str()
+		if err != nil {
+			return nil, err
+		}
+		if n, err := reader.UVarInt(); err != nil {
+			return nil, err
+		} else if n != 0 {
+			return nil, fmt.Errorf("parameters of SimpleAggregateFunction %s are not supported", function)
+		}
+		t.Name = "SimpleAggregateFunction"
+		t.Args = []chtype.Arg{{Kind: chtype.TypeArg, Type: &chtype.Type{Name: function}}}
+		n, err := reader.UVarInt()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < n; i++ {
+			if err := typeArg(""); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported binary type encoding 0x%02x", code)
+	}
+	return t, nil
+}
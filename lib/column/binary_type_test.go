@@ -0,0 +1,141 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package column
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryTypeRoundTrip(t *testing.T) {
+	cases := []string{
+		"Nothing",
+		"UInt8",
+		"Int256",
+		"Float64",
+		"String",
+		"UUID",
+		"IPv6",
+		"Bool",
+		"Date32",
+		"DateTime",
+		"DateTime('Europe/Amsterdam')",
+		"DateTime64(3)",
+		"DateTime64(9, 'UTC')",
+		"FixedString(16)",
+		"Decimal(18, 4)",
+		"Decimal(76, 10)",
+		"Enum8('a' = 1, 'b' = 2)",
+		"Enum16('x' = -1000, 'y' = 1000)",
+		"Array(Nullable(String))",
+		"LowCardinality(Nullable(String))",
+		"Map(String, Array(UInt64))",
+		"Tuple(UInt8, String)",
+		"Tuple(a UInt8, b Array(String))",
+		"Variant(String, UInt64)",
+		"Dynamic",
+		"Dynamic(max_types=10)",
+		"JSON",
+		"JSON(a UInt32, SKIP b, SKIP REGEXP 'c.*')",
+		"Point",
+		"MultiPolygon",
+		"IntervalDay",
+		"SimpleAggregateFunction(sum, UInt64)",
+	}
+
+	for _, typ := range cases {
+		parsed, err := chtype.Parse(typ)
+		require.NoError(t, err, typ)
+
+		var buffer proto.Buffer
+		require.NoError(t, encodeBinaryType(&buffer, parsed), typ)
+
+		decoded, err := decodeBinaryType(proto.NewReader(bytes.NewReader(buffer.Buf)))
+		require.NoError(t, err, typ)
+		assert.Equal(t, parsed.String(), decoded.String(), typ)
+	}
+}
+
+func TestBinaryTypeEncoding(t *testing.T) {
+	cases := []struct {
+		typ      string
+		expected []byte
+	}{
+		{typ: "String", expected: []byte{0x15}},
+		{typ: "Nullable(Int64)", expected: []byte{binaryTypeNullable, 0x0A}},
+		{typ: "Array(String)", expected: []byte{binaryTypeArray, 0x15}},
+		{typ: "FixedString(4)", expected: []byte{binaryTypeFixedString, 4}},
+		{typ: "DateTime64(3, 'UTC')", expected: []byte{binaryTypeDateTime64Zone, 3, 3, 'U', 'T', 'C'}},
+	}
+
+	for _, c := range cases {
+		parsed, err := chtype.Parse(c.typ)
+		require.NoError(t, err, c.typ)
+
+		var buffer proto.Buffer
+		require.NoError(t, encodeBinaryType(&buffer, parsed), c.typ)
+		assert.Equal(t, c.expected, buffer.Buf, c.typ)
+	}
+}
+
+func TestBinaryTypeUnsupported(t *testing.T) {
+	parsed, err := chtype.Parse("AggregateFunction(sum, UInt64)")
+	require.NoError(t, err)
+
+	var buffer proto.Buffer
+	assert.Error(t, encodeBinaryType(&buffer, parsed))
+}
+
+func TestBinaryTypeIntervalKinds(t *testing.T) {
+	cases := []struct {
+		encoded  []byte
+		expected string
+	}{
+		{encoded: []byte{binaryTypeInterval, 0x00}, expected: "IntervalNanosecond"},
+		{encoded: []byte{binaryTypeInterval, 0x01}, expected: "IntervalMicrosecond"},
+		{encoded: []byte{binaryTypeInterval, 0x02}, expected: "IntervalMillisecond"},
+		{encoded: []byte{binaryTypeInterval, 0x03}, expected: "IntervalSecond"},
+		{encoded: []byte{binaryTypeInterval, 0x04}, expected: "IntervalMinute"},
+		{encoded: []byte{binaryTypeInterval, 0x05}, expected: "IntervalHour"},
+		{encoded: []byte{binaryTypeInterval, 0x06}, expected: "IntervalDay"},
+		{encoded: []byte{binaryTypeInterval, 0x07}, expected: "IntervalWeek"},
+		{encoded: []byte{binaryTypeInterval, 0x08}, expected: "IntervalMonth"},
+		{encoded: []byte{binaryTypeInterval, 0x09}, expected: "IntervalQuarter"},
+		{encoded: []byte{binaryTypeInterval, 0x0A}, expected: "IntervalYear"},
+	}
+
+	for _, c := range cases {
+		decoded, err := decodeBinaryType(proto.NewReader(bytes.NewReader(c.encoded)))
+		require.NoError(t, err, c.expected)
+		assert.Equal(t, c.expected, decoded.String())
+
+		parsed, err := chtype.Parse(c.expected)
+		require.NoError(t, err, c.expected)
+		var buffer proto.Buffer
+		require.NoError(t, encodeBinaryType(&buffer, parsed), c.expected)
+		assert.Equal(t, c.encoded, buffer.Buf, c.expected)
+	}
+
+	_, err := decodeBinaryType(proto.NewReader(bytes.NewReader([]byte{binaryTypeInterval, 0x1A})))
+	assert.ErrorContains(t, err, "unknown interval kind 0x1a")
+}
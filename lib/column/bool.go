@@ -58,6 +58,22 @@ func (col *Bool) Row(i int, ptr bool) any {
 	return val
 }
 
+// Data returns the column values without copying.
+// The slice is only valid until the column is reset or decoded into again.
+func (col *Bool) Data() []bool {
+	return col.col
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *Bool) AppendValue(v bool) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *Bool) Value(i int) bool {
+	return col.row(i)
+}
+
 func (col *Bool) ScanRow(dest any, row int) error {
 	switch d := dest.(type) {
 	case *bool:
@@ -183,6 +199,20 @@ func (col *Bool) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *Bool) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.Bool()
+	if err != nil {
+		return err
+	}
+	col.col.Append(v)
+	return nil
+}
+
+func (col *Bool) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutBool(col.col[row])
+	return nil
+}
+
 func (col *Bool) row(i int) bool {
 	return col.col.Row(i)
 }
@@ -21,19 +21,21 @@
 package column
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
 	"math/big"
+	"net"
 	"reflect"
 	"strings"
-	"fmt"
 	"time"
-	"net"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chcol"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 	"github.com/google/uuid"
 	"github.com/paulmach/orb"
 	"github.com/shopspring/decimal"
-	"database/sql"
-	"database/sql/driver"
-	"github.com/ClickHouse/ch-go/proto"
-	"github.com/ClickHouse/clickhouse-go/v2/lib/chcol"
 )
 
 func (t Type) Column(name string, tz *time.Location) (Interface, error) {
@@ -126,39 +128,50 @@ func (t Type) Column(name string, tz *time.Location) (Interface, error) {
 	    return &JSONObject{name: name, root: true, tz: tz}, nil
 	}
 
-	switch strType := string(t); {
-	case strings.HasPrefix(string(t), "Map("):
+	parsed, err := chtype.Parse(string(t))
+	if err != nil {
+		return nil, &UnsupportedColumnTypeError{
+			t: t,
+		}
+	}
+	switch parsed.Name {
+	case "Map":
 		return (&Map{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "Tuple("):
+	case "Tuple":
 		return (&Tuple{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "Variant("):
+	case "Variant":
 		return (&Variant{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "Dynamic"):
+	case "Dynamic":
 		return (&Dynamic{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "JSON"):
+	case "JSON":
 		return (&JSON{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "Decimal("):
+	case "Decimal":
 		return (&Decimal{name: name}).parse(t)
-	case strings.HasPrefix(strType, "Nested("):
+	case "Nested":
 		return (&Nested{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "Array("):
+	case "Array":
 		return (&Array{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "Interval"):
-		return (&Interval{name: name}).parse(t)
-	case strings.HasPrefix(string(t), "Nullable"):
+	case "Nullable":
 		return (&Nullable{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "FixedString"):
+	case "FixedString":
 		return (&FixedString{name: name}).parse(t)
-	case strings.HasPrefix(string(t), "LowCardinality"):
+	case "LowCardinality":
 		return (&LowCardinality{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "SimpleAggregateFunction"):
+	case "SimpleAggregateFunction":
 		return (&SimpleAggregateFunction{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "Enum8") || strings.HasPrefix(string(t), "Enum16"):
+	case "Enum8", "Enum16":
 		return Enum(t, name)
-	case strings.HasPrefix(string(t), "DateTime64"):
+	case "DateTime64":
 		return (&DateTime64{name: name}).parse(t, tz)
-	case strings.HasPrefix(strType, "DateTime") && !strings.HasPrefix(strType, "DateTime64"):
+	case "DateTime":
 		return (&DateTime{name: name}).parse(t, tz)
+	case "Time64":
+		return (&Time64{name: name}).parse(t, tz)
+	case "Time":
+		return (&Time{name: name}).parse(t, tz)
+	}
+	if strings.HasPrefix(parsed.Name, "Interval") {
+		return (&Interval{name: name}).parse(t)
 	}
 	return nil, &UnsupportedColumnTypeError{
 		t: t,
@@ -272,6 +285,22 @@ func (col *{{ .ChType }}) Row(i int, ptr bool) any {
 	return value
 }
 
+// Data returns the column values without copying.
+// The slice is only valid until the column is reset or decoded into again.
+func (col *{{ .ChType }}) Data() []{{ .GoType }} {
+	return col.col
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *{{ .ChType }}) AppendValue(v {{ .GoType }}) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *{{ .ChType }}) Value(i int) {{ .GoType }} {
+	return col.col.Row(i)
+}
+
 func (col *{{ .ChType }}) Append(v any) (nulls []uint8,err error) {
 	switch v := v.(type) {
 	case []{{ .GoType }}:
@@ -444,4 +473,18 @@ func (col *{{ .ChType }}) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *{{ .ChType }}) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.{{ .ChType }}()
+	if err != nil {
+		return err
+	}
+	col.col.Append(v)
+	return nil
+}
+
+func (col *{{ .ChType }}) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.Put{{ .ChType }}(col.col[row])
+	return nil
+}
+
 {{- end }}
@@ -34,15 +34,6 @@ var colUnEscape = strings.NewReplacer("\\`", "`", "\\\\", "\\")
 
 type Type string
 
-func (t Type) params() string {
-	switch start, end := strings.Index(string(t), "("), strings.LastIndex(string(t), ")"); {
-	case len(t) == 0, start <= 0, end <= 0, end < start:
-		return ""
-	default:
-		return string(t[start+1 : end])
-	}
-}
-
 type Error struct {
 	ColumnType string
 	Err        error
@@ -84,10 +75,28 @@ type Interface interface {
 	AppendRow(v any) error
 	Decode(reader *proto.Reader, rows int) error
 	Encode(buffer *proto.Buffer)
+	// DecodeRowBinary appends a row read in the RowBinary format
+	DecodeRowBinary(reader *proto.Reader) error
+	// EncodeRowBinary writes the row in the RowBinary format
+	EncodeRowBinary(buffer *proto.Buffer, row int) error
 	ScanType() reflect.Type
 	Reset()
 }
 
+// Values returns the values of col as a typed slice, using the Data method of the column.
+// Fixed size numeric, Bool and UUID columns return their backing slice without copying,
+// String, Date and DateTime columns return a copy.
+func Values[T any](col Interface) ([]T, error) {
+	if c, ok := col.(interface{ Data() []T }); ok {
+		return c.Data(), nil
+	}
+	return nil, &ColumnConverterError{
+		Op:   "Values",
+		To:   fmt.Sprintf("[]%s", reflect.TypeFor[T]()),
+		From: string(col.Type()),
+	}
+}
+
 type CustomSerialization interface {
 	ReadStatePrefix(*proto.Reader) error
 	WriteStatePrefix(*proto.Buffer) error
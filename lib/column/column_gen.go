@@ -32,6 +32,7 @@ import (
 
 	"github.com/ClickHouse/ch-go/proto"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/chcol"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 	"github.com/google/uuid"
 	"github.com/paulmach/orb"
 	"github.com/shopspring/decimal"
@@ -143,44 +144,51 @@ func (t Type) Column(name string, tz *time.Location) (Interface, error) {
 		return &JSONObject{name: name, root: true, tz: tz}, nil
 	}
 
-	switch strType := string(t); {
-	case strings.HasPrefix(string(t), "Map("):
+	parsed, err := chtype.Parse(string(t))
+	if err != nil {
+		return nil, &UnsupportedColumnTypeError{
+			t: t,
+		}
+	}
+	switch parsed.Name {
+	case "Map":
 		return (&Map{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "Tuple("):
+	case "Tuple":
 		return (&Tuple{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "Variant("):
+	case "Variant":
 		return (&Variant{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "Dynamic"):
+	case "Dynamic":
 		return (&Dynamic{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "JSON"):
+	case "JSON":
 		return (&JSON{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "Decimal("):
+	case "Decimal":
 		return (&Decimal{name: name}).parse(t)
-	case strings.HasPrefix(strType, "Nested("):
+	case "Nested":
 		return (&Nested{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "Array("):
+	case "Array":
 		return (&Array{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "Interval"):
-		return (&Interval{name: name}).parse(t)
-	case strings.HasPrefix(string(t), "Nullable"):
+	case "Nullable":
 		return (&Nullable{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "FixedString"):
+	case "FixedString":
 		return (&FixedString{name: name}).parse(t)
-	case strings.HasPrefix(string(t), "LowCardinality"):
+	case "LowCardinality":
 		return (&LowCardinality{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "SimpleAggregateFunction"):
+	case "SimpleAggregateFunction":
 		return (&SimpleAggregateFunction{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "Enum8") || strings.HasPrefix(string(t), "Enum16"):
+	case "Enum8", "Enum16":
 		return Enum(t, name)
-	case strings.HasPrefix(string(t), "DateTime64"):
+	case "DateTime64":
 		return (&DateTime64{name: name}).parse(t, tz)
-	case strings.HasPrefix(strType, "DateTime") && !strings.HasPrefix(strType, "DateTime64"):
+	case "DateTime":
 		return (&DateTime{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "Time64"):
+	case "Time64":
 		return (&Time64{name: name}).parse(t, tz)
-	case strings.HasPrefix(string(t), "Time"):
+	case "Time":
 		return (&Time{name: name}).parse(t, tz)
 	}
+	if strings.HasPrefix(parsed.Name, "Interval") {
+		return (&Interval{name: name}).parse(t)
+	}
 	return nil, &UnsupportedColumnTypeError{
 		t: t,
 	}
@@ -322,6 +330,22 @@ func (col *Float32) Row(i int, ptr bool) any {
 	return value
 }
 
+// Data returns the column values without copying.
+// The slice is only valid until the column is reset or decoded into again.
+func (col *Float32) Data() []float32 {
+	return col.col
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *Float32) AppendValue(v float32) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *Float32) Value(i int) float32 {
+	return col.col.Row(i)
+}
+
 func (col *Float32) Append(v any) (nulls []uint8, err error) {
 	switch v := v.(type) {
 	case []float32:
@@ -413,6 +437,20 @@ func (col *Float32) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *Float32) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.Float32()
+	if err != nil {
+		return err
+	}
+	col.col.Append(v)
+	return nil
+}
+
+func (col *Float32) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutFloat32(col.col[row])
+	return nil
+}
+
 func (col *Float64) Name() string {
 	return col.name
 }
@@ -465,6 +503,22 @@ func (col *Float64) Row(i int, ptr bool) any {
 	return value
 }
 
+// Data returns the column values without copying.
+// The slice is only valid until the column is reset or decoded into again.
+func (col *Float64) Data() []float64 {
+	return col.col
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *Float64) AppendValue(v float64) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *Float64) Value(i int) float64 {
+	return col.col.Row(i)
+}
+
 func (col *Float64) Append(v any) (nulls []uint8, err error) {
 	switch v := v.(type) {
 	case []float64:
@@ -583,6 +637,20 @@ func (col *Float64) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *Float64) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.Float64()
+	if err != nil {
+		return err
+	}
+	col.col.Append(v)
+	return nil
+}
+
+func (col *Float64) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutFloat64(col.col[row])
+	return nil
+}
+
 func (col *Int8) Name() string {
 	return col.name
 }
@@ -640,6 +708,22 @@ func (col *Int8) Row(i int, ptr bool) any {
 	return value
 }
 
+// Data returns the column values without copying.
+// The slice is only valid until the column is reset or decoded into again.
+func (col *Int8) Data() []int8 {
+	return col.col
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *Int8) AppendValue(v int8) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *Int8) Value(i int) int8 {
+	return col.col.Row(i)
+}
+
 func (col *Int8) Append(v any) (nulls []uint8, err error) {
 	switch v := v.(type) {
 	case []int8:
@@ -763,6 +847,20 @@ func (col *Int8) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *Int8) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.Int8()
+	if err != nil {
+		return err
+	}
+	col.col.Append(v)
+	return nil
+}
+
+func (col *Int8) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutInt8(col.col[row])
+	return nil
+}
+
 func (col *Int16) Name() string {
 	return col.name
 }
@@ -815,6 +913,22 @@ func (col *Int16) Row(i int, ptr bool) any {
 	return value
 }
 
+// Data returns the column values without copying.
+// The slice is only valid until the column is reset or decoded into again.
+func (col *Int16) Data() []int16 {
+	return col.col
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *Int16) AppendValue(v int16) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *Int16) Value(i int) int16 {
+	return col.col.Row(i)
+}
+
 func (col *Int16) Append(v any) (nulls []uint8, err error) {
 	switch v := v.(type) {
 	case []int16:
@@ -933,6 +1047,20 @@ func (col *Int16) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *Int16) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.Int16()
+	if err != nil {
+		return err
+	}
+	col.col.Append(v)
+	return nil
+}
+
+func (col *Int16) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutInt16(col.col[row])
+	return nil
+}
+
 func (col *Int32) Name() string {
 	return col.name
 }
@@ -985,6 +1113,22 @@ func (col *Int32) Row(i int, ptr bool) any {
 	return value
 }
 
+// Data returns the column values without copying.
+// The slice is only valid until the column is reset or decoded into again.
+func (col *Int32) Data() []int32 {
+	return col.col
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *Int32) AppendValue(v int32) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *Int32) Value(i int) int32 {
+	return col.col.Row(i)
+}
+
 func (col *Int32) Append(v any) (nulls []uint8, err error) {
 	switch v := v.(type) {
 	case []int32:
@@ -1103,6 +1247,20 @@ func (col *Int32) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *Int32) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.Int32()
+	if err != nil {
+		return err
+	}
+	col.col.Append(v)
+	return nil
+}
+
+func (col *Int32) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutInt32(col.col[row])
+	return nil
+}
+
 func (col *Int64) Name() string {
 	return col.name
 }
@@ -1157,6 +1315,22 @@ func (col *Int64) Row(i int, ptr bool) any {
 	return value
 }
 
+// Data returns the column values without copying.
+// The slice is only valid until the column is reset or decoded into again.
+func (col *Int64) Data() []int64 {
+	return col.col
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *Int64) AppendValue(v int64) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *Int64) Value(i int) int64 {
+	return col.col.Row(i)
+}
+
 func (col *Int64) Append(v any) (nulls []uint8, err error) {
 	switch v := v.(type) {
 	case []int64:
@@ -1279,6 +1453,20 @@ func (col *Int64) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *Int64) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.Int64()
+	if err != nil {
+		return err
+	}
+	col.col.Append(v)
+	return nil
+}
+
+func (col *Int64) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutInt64(col.col[row])
+	return nil
+}
+
 func (col *UInt8) Name() string {
 	return col.name
 }
@@ -1336,6 +1524,22 @@ func (col *UInt8) Row(i int, ptr bool) any {
 	return value
 }
 
+// Data returns the column values without copying.
+// The slice is only valid until the column is reset or decoded into again.
+func (col *UInt8) Data() []uint8 {
+	return col.col
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *UInt8) AppendValue(v uint8) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *UInt8) Value(i int) uint8 {
+	return col.col.Row(i)
+}
+
 func (col *UInt8) Append(v any) (nulls []uint8, err error) {
 	switch v := v.(type) {
 	case []uint8:
@@ -1433,6 +1637,20 @@ func (col *UInt8) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *UInt8) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.UInt8()
+	if err != nil {
+		return err
+	}
+	col.col.Append(v)
+	return nil
+}
+
+func (col *UInt8) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutUInt8(col.col[row])
+	return nil
+}
+
 func (col *UInt16) Name() string {
 	return col.name
 }
@@ -1483,6 +1701,22 @@ func (col *UInt16) Row(i int, ptr bool) any {
 	return value
 }
 
+// Data returns the column values without copying.
+// The slice is only valid until the column is reset or decoded into again.
+func (col *UInt16) Data() []uint16 {
+	return col.col
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *UInt16) AppendValue(v uint16) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *UInt16) Value(i int) uint16 {
+	return col.col.Row(i)
+}
+
 func (col *UInt16) Append(v any) (nulls []uint8, err error) {
 	switch v := v.(type) {
 	case []uint16:
@@ -1574,6 +1808,20 @@ func (col *UInt16) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *UInt16) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.UInt16()
+	if err != nil {
+		return err
+	}
+	col.col.Append(v)
+	return nil
+}
+
+func (col *UInt16) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutUInt16(col.col[row])
+	return nil
+}
+
 func (col *UInt32) Name() string {
 	return col.name
 }
@@ -1624,6 +1872,22 @@ func (col *UInt32) Row(i int, ptr bool) any {
 	return value
 }
 
+// Data returns the column values without copying.
+// The slice is only valid until the column is reset or decoded into again.
+func (col *UInt32) Data() []uint32 {
+	return col.col
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *UInt32) AppendValue(v uint32) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *UInt32) Value(i int) uint32 {
+	return col.col.Row(i)
+}
+
 func (col *UInt32) Append(v any) (nulls []uint8, err error) {
 	switch v := v.(type) {
 	case []uint32:
@@ -1715,6 +1979,20 @@ func (col *UInt32) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *UInt32) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.UInt32()
+	if err != nil {
+		return err
+	}
+	col.col.Append(v)
+	return nil
+}
+
+func (col *UInt32) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutUInt32(col.col[row])
+	return nil
+}
+
 func (col *UInt64) Name() string {
 	return col.name
 }
@@ -1765,6 +2043,22 @@ func (col *UInt64) Row(i int, ptr bool) any {
 	return value
 }
 
+// Data returns the column values without copying.
+// The slice is only valid until the column is reset or decoded into again.
+func (col *UInt64) Data() []uint64 {
+	return col.col
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *UInt64) AppendValue(v uint64) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *UInt64) Value(i int) uint64 {
+	return col.col.Row(i)
+}
+
 func (col *UInt64) Append(v any) (nulls []uint8, err error) {
 	switch v := v.(type) {
 	case []uint64:
@@ -1855,3 +2149,17 @@ func (col *UInt64) Decode(reader *proto.Reader, rows int) error {
 func (col *UInt64) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
+
+func (col *UInt64) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.UInt64()
+	if err != nil {
+		return err
+	}
+	col.col.Append(v)
+	return nil
+}
+
+func (col *UInt64) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutUInt64(col.col[row])
+	return nil
+}
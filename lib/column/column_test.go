@@ -0,0 +1,114 @@
+package column
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValues(t *testing.T) {
+	int64Col, err := Type("Int64").Column("a", nil)
+	require.NoError(t, err)
+	require.NoError(t, int64Col.AppendRow(int64(1)))
+	require.NoError(t, int64Col.AppendRow(int64(2)))
+
+	ints, err := Values[int64](int64Col)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, ints)
+
+	stringCol, err := Type("String").Column("b", nil)
+	require.NoError(t, err)
+	require.NoError(t, stringCol.AppendRow("x"))
+
+	strs, err := Values[string](stringCol)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x"}, strs)
+
+	now := time.Unix(time.Now().Unix(), 0).UTC()
+	dateTimeCol, err := Type("DateTime('UTC')").Column("c", nil)
+	require.NoError(t, err)
+	require.NoError(t, dateTimeCol.AppendRow(now))
+
+	times, err := Values[time.Time](dateTimeCol)
+	require.NoError(t, err)
+	require.Len(t, times, 1)
+	assert.True(t, now.Equal(times[0]))
+
+	_, err = Values[string](int64Col)
+	assert.Error(t, err)
+}
+
+func TestParsedColumnTypes(t *testing.T) {
+	col, err := Type("Map(DateTime64(3, 'UTC'), Tuple(`a b` String, c Decimal(18, 4)))").Column("m", time.UTC)
+	require.NoError(t, err)
+	m := col.(*Map)
+	assert.Equal(t, Type("DateTime64(3, 'UTC')"), m.keys.Type())
+	tuple := m.values.(*Tuple)
+	assert.True(t, tuple.isNamed)
+	assert.Equal(t, "a b", tuple.columns[0].Name())
+	assert.Equal(t, Type("Decimal(18, 4)"), tuple.columns[1].Type())
+
+	col, err = Type("DateTime64(6,'Europe/Berlin')").Column("d", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", col.(*DateTime64).col.Location.String())
+
+	col, err = Type("Array(Array(Nullable(FixedString(4))))").Column("a", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, 2, col.(*Array).depth)
+	assert.Equal(t, Type("Nullable(FixedString(4))"), col.(*Array).values.Type())
+
+	col, err = Type("Nested(`a b` UInt8, c Nested(d String))").Column("n", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, Type("Array(Tuple(`a b` UInt8, c Array(Tuple(d String))))"), col.Type())
+
+	col, err = Type("SimpleAggregateFunction(groupArrayArray(10), Array(String))").Column("s", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, Type("Array(String)"), col.(*SimpleAggregateFunction).base.Type())
+
+	col, err = Type("Time64(6, 'Europe/Berlin')").Column("t", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", col.(*Time64).timezone.String())
+
+	col, err = Type("JSON(max_dynamic_paths=8, `a.b` DateTime64(3, 'UTC'), SKIP c, SKIP REGEXP 'd.*')").Column("j", time.UTC)
+	require.NoError(t, err)
+	json := col.(*JSON)
+	assert.Equal(t, 8, json.maxDynamicPaths)
+	assert.Equal(t, []string{"a.b"}, json.typedPaths)
+	assert.Equal(t, []string{"c", "d.*"}, json.skipPaths)
+
+	col, err = Type("Variant(String, Array(UInt8))").Column("v", time.UTC)
+	require.NoError(t, err)
+	assert.Len(t, col.(*Variant).columns, 2)
+
+	for _, invalid := range []Type{"Map(String)", "DateTime64(3, 'UTC'", "Decimal(x, 2)", "DateTime('UTC'", "Nullable()", "FixedString(x)", "Array(String"} {
+		_, err = invalid.Column("x", time.UTC)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestZero(t *testing.T) {
+	for typ, expected := range map[Type]any{
+		"String":                               "",
+		"UInt64":                               uint64(0),
+		"Array(String)":                        []string{},
+		"Map(String, UInt64)":                  map[string]uint64{},
+		"Nullable(String)":                     nil,
+		"Enum8('b' = 2, 'a' = -1)":             "a",
+		"Enum16('x' = 300, 'y' = 1)":           "y",
+		"Tuple(String, Enum8('a' = 1))":        []any{"", "a"},
+		"Tuple(a Array(String), b UInt8)":      map[string]any{"a": []string{}, "b": uint8(0)},
+		"SimpleAggregateFunction(sum, UInt64)": uint64(0),
+	} {
+		col, err := typ.Column("x", time.UTC)
+		require.NoError(t, err)
+		require.NoError(t, col.AppendRow(Zero(col)), typ)
+		switch expected {
+		case nil:
+			assert.Nil(t, col.Row(0, true), typ)
+		default:
+			assert.Equal(t, expected, col.Row(0, false), typ)
+		}
+	}
+}
@@ -76,6 +76,25 @@ func (col *Date) Row(i int, ptr bool) any {
 	return value
 }
 
+// Data returns a copy of the column values.
+func (col *Date) Data() []time.Time {
+	values := make([]time.Time, col.Rows())
+	for i := range values {
+		values[i] = col.row(i)
+	}
+	return values
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *Date) AppendValue(v time.Time) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *Date) Value(i int) time.Time {
+	return col.row(i)
+}
+
 func (col *Date) ScanRow(dest any, row int) error {
 	switch d := dest.(type) {
 	case *time.Time:
@@ -267,6 +286,20 @@ func (col *Date) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *Date) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.UInt16()
+	if err != nil {
+		return err
+	}
+	col.col = append(col.col, proto.Date(v))
+	return nil
+}
+
+func (col *Date) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutUInt16(uint16(col.col[row]))
+	return nil
+}
+
 func (col *Date) row(i int) time.Time {
 	t := col.col.Row(i)
 
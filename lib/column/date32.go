@@ -66,6 +66,25 @@ func (col *Date32) Row(i int, ptr bool) any {
 	return value
 }
 
+// Data returns a copy of the column values.
+func (col *Date32) Data() []time.Time {
+	values := make([]time.Time, col.Rows())
+	for i := range values {
+		values[i] = col.row(i)
+	}
+	return values
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *Date32) AppendValue(v time.Time) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *Date32) Value(i int) time.Time {
+	return col.row(i)
+}
+
 func (col *Date32) ScanRow(dest any, row int) error {
 	switch d := dest.(type) {
 	case *time.Time:
@@ -244,6 +263,20 @@ func (col *Date32) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *Date32) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.Int32()
+	if err != nil {
+		return err
+	}
+	col.col = append(col.col, proto.Date32(v))
+	return nil
+}
+
+func (col *Date32) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutInt32(int32(col.col[row]))
+	return nil
+}
+
 func (col *Date32) row(i int) time.Time {
 	t := col.col.Row(i)
 
@@ -22,11 +22,11 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"reflect"
-	"strings"
 	"time"
 
 	"github.com/ClickHouse/ch-go/proto"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/timezone"
 )
 
@@ -59,8 +59,13 @@ func (col *DateTime) parse(t Type, tz *time.Location) (_ *DateTime, err error) {
 		col.col.Location = tz
 		return col, nil
 	}
-	var name = strings.TrimSuffix(strings.TrimPrefix(string(t), "DateTime('"), "')")
-	timezone, err := timezone.Load(name)
+	parsed, err := chtype.Parse(string(t))
+	if err != nil || len(parsed.Timezone()) == 0 {
+		return nil, &UnsupportedColumnTypeError{
+			t: t,
+		}
+	}
+	timezone, err := timezone.Load(parsed.Timezone())
 	if err != nil {
 		return nil, err
 	}
@@ -88,6 +93,25 @@ func (col *DateTime) Row(i int, ptr bool) any {
 	return value
 }
 
+// Data returns a copy of the column values.
+func (col *DateTime) Data() []time.Time {
+	values := make([]time.Time, col.Rows())
+	for i := range values {
+		values[i] = col.row(i)
+	}
+	return values
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *DateTime) AppendValue(v time.Time) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *DateTime) Value(i int) time.Time {
+	return col.row(i)
+}
+
 func (col *DateTime) ScanRow(dest any, row int) error {
 	switch d := dest.(type) {
 	case *time.Time:
@@ -297,6 +321,20 @@ func (col *DateTime) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *DateTime) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.UInt32()
+	if err != nil {
+		return err
+	}
+	col.col.Data = append(col.col.Data, proto.DateTime(v))
+	return nil
+}
+
+func (col *DateTime) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutUInt32(uint32(col.col.Data[row]))
+	return nil
+}
+
 func (col *DateTime) row(i int) time.Time {
 	v := col.col.Row(i)
 	return v
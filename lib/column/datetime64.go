@@ -23,12 +23,11 @@ import (
 	"fmt"
 	"math"
 	"reflect"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/ClickHouse/ch-go/proto"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/timezone"
 )
 
@@ -59,32 +58,25 @@ func (col *DateTime64) Name() string {
 
 func (col *DateTime64) parse(t Type, tz *time.Location) (_ Interface, err error) {
 	col.chType = t
-	switch params := strings.Split(t.params(), ","); len(params) {
-	case 2:
-		precision, err := strconv.ParseInt(params[0], 10, 8)
-		if err != nil {
-			return nil, err
-		}
-		p := byte(precision)
-		col.col.WithPrecision(proto.Precision(p))
-		timezone, err := timezone.Load(params[1][2 : len(params[1])-1])
-		if err != nil {
-			return nil, err
-		}
-		col.col.WithLocation(timezone)
-	case 1:
-		precision, err := strconv.ParseInt(params[0], 10, 8)
-		if err != nil {
-			return nil, err
+	parsed, err := chtype.Parse(string(t))
+	if err != nil || len(parsed.Args) == 0 || len(parsed.Args) > 2 {
+		return nil, &UnsupportedColumnTypeError{
+			t: t,
 		}
-		p := byte(precision)
-		col.col.WithPrecision(proto.Precision(p))
-		col.col.WithLocation(tz)
-	default:
+	}
+	precision, ok := parsed.Precision()
+	if !ok || precision < 0 || precision > 9 {
 		return nil, &UnsupportedColumnTypeError{
 			t: t,
 		}
 	}
+	col.col.WithPrecision(proto.Precision(precision))
+	if name := parsed.Timezone(); len(name) != 0 {
+		if tz, err = timezone.Load(name); err != nil {
+			return nil, err
+		}
+	}
+	col.col.WithLocation(tz)
 	return col, nil
 }
 
@@ -112,6 +104,25 @@ func (col *DateTime64) Row(i int, ptr bool) any {
 	return value
 }
 
+// Data returns a copy of the column values.
+func (col *DateTime64) Data() []time.Time {
+	values := make([]time.Time, col.Rows())
+	for i := range values {
+		values[i] = col.row(i)
+	}
+	return values
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *DateTime64) AppendValue(v time.Time) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *DateTime64) Value(i int) time.Time {
+	return col.row(i)
+}
+
 func (col *DateTime64) ScanRow(dest any, row int) error {
 	switch d := dest.(type) {
 	case *time.Time:
@@ -295,6 +306,20 @@ func (col *DateTime64) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *DateTime64) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.Int64()
+	if err != nil {
+		return err
+	}
+	col.col.Data = append(col.col.Data, proto.DateTime64(v))
+	return nil
+}
+
+func (col *DateTime64) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutInt64(int64(col.col.Data[row]))
+	return nil
+}
+
 func (col *DateTime64) row(i int) time.Time {
 	time := col.col.Row(i)
 	if col.timezone != nil {
@@ -25,10 +25,9 @@ import (
 	"fmt"
 	"math/big"
 	"reflect"
-	"strconv"
-	"strings"
 
 	"github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 
 	"github.com/shopspring/decimal"
 )
@@ -51,21 +50,23 @@ func (col *Decimal) Reset() {
 
 func (col *Decimal) parse(t Type) (_ *Decimal, err error) {
 	col.chType = t
-	params := strings.Split(t.params(), ",")
-	if len(params) != 2 {
+	parsed, err := chtype.Parse(string(t))
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not Decimal type: %s", t, err)
+	}
+	if len(parsed.Args) != 2 {
 		return nil, fmt.Errorf("invalid Decimal format: '%s'", t)
 	}
-	params[0] = strings.TrimSpace(params[0])
-	params[1] = strings.TrimSpace(params[1])
 
-	if col.precision, err = strconv.Atoi(params[0]); err != nil {
-		return nil, fmt.Errorf("'%s' is not Decimal type: %s", t, err)
+	var ok bool
+	if col.precision, ok = parsed.Precision(); !ok {
+		return nil, fmt.Errorf("'%s' is not Decimal type: invalid precision", t)
 	} else if col.precision < 1 {
 		return nil, errors.New("wrong precision of Decimal type")
 	}
 
-	if col.scale, err = strconv.Atoi(params[1]); err != nil {
-		return nil, fmt.Errorf("'%s' is not Decimal type: %s", t, err)
+	if col.scale, ok = parsed.Scale(); !ok {
+		return nil, fmt.Errorf("'%s' is not Decimal type: invalid scale", t)
 	} else if col.scale < 0 || col.scale > col.precision {
 		return nil, errors.New("wrong scale of Decimal type")
 	}
@@ -310,6 +311,52 @@ func (col *Decimal) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *Decimal) DecodeRowBinary(reader *proto.Reader) error {
+	switch vCol := col.col.(type) {
+	case *proto.ColDecimal32:
+		v, err := reader.Int32()
+		if err != nil {
+			return err
+		}
+		vCol.Append(proto.Decimal32(v))
+	case *proto.ColDecimal64:
+		v, err := reader.Int64()
+		if err != nil {
+			return err
+		}
+		vCol.Append(proto.Decimal64(v))
+	case *proto.ColDecimal128:
+		v, err := reader.Int128()
+		if err != nil {
+			return err
+		}
+		vCol.Append(proto.Decimal128(v))
+	case *proto.ColDecimal256:
+		low, high, err := readUInt256Halves(reader)
+		if err != nil {
+			return err
+		}
+		vCol.Append(proto.Decimal256{Low: low, High: high})
+	}
+	return nil
+}
+
+func (col *Decimal) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	switch vCol := col.col.(type) {
+	case *proto.ColDecimal32:
+		buffer.PutInt32(int32(vCol.Row(row)))
+	case *proto.ColDecimal64:
+		buffer.PutInt64(int64(vCol.Row(row)))
+	case *proto.ColDecimal128:
+		buffer.PutInt128(proto.Int128(vCol.Row(row)))
+	case *proto.ColDecimal256:
+		v := vCol.Row(row)
+		buffer.PutUInt128(v.Low)
+		buffer.PutUInt128(v.High)
+	}
+	return nil
+}
+
 func (col *Decimal) Scale() int64 {
 	return int64(col.scale)
 }
@@ -22,43 +22,39 @@ import (
 	"fmt"
 	"math"
 	"reflect"
-	"strings"
 	"time"
 
 	"github.com/ClickHouse/ch-go/proto"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/chcol"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 )
 
 const SupportedDynamicSerializationVersion = 3
 const DeprecatedSupportedDynamicSerializationVersion = 1
 const DefaultMaxDynamicTypes = 32
-const DynamicNullDiscriminator = -1 // The Null index changes as data is being built, use -1 as placeholder for writes.
+const DynamicNullDiscriminator = -1 // The Null index changes as data is being built, use -1 as placeholder.
 
 type Dynamic struct {
 	chType Type
 	tz     *time.Location
 	name   string
 
-	totalTypes     int // Null is last type index + 1, so this doubles as the Null type index for reads.
+	totalTypes     int // Null is last type index + 1 in Native, so this doubles as the Null type index on the wire.
 	discriminators []int
 	offsets        []int
 
 	columns           []Interface
 	columnIndexByName map[string]int
+	binaryTypes       [][]byte // binary encoding of the types of the columns, written before the values in RowBinary
 }
 
 func (c *Dynamic) parse(t Type, tz *time.Location) (_ *Dynamic, err error) {
 	c.chType = t
 	c.tz = tz
-	tStr := string(t)
 
 	c.columnIndexByName = make(map[string]int)
 
-	if tStr == "Dynamic" {
-		return c, nil
-	}
-
-	if !strings.HasPrefix(tStr, "Dynamic(") || !strings.HasSuffix(tStr, ")") {
+	if parsed, err := chtype.Parse(string(t)); err != nil || parsed.Name != "Dynamic" {
 		return nil, &UnsupportedColumnTypeError{t: t}
 	}
 
@@ -87,11 +83,12 @@ func (c *Dynamic) Rows() int {
 }
 
 func (c *Dynamic) Row(i int, ptr bool) any {
+	c.syncOffsets()
 	typeIndex := c.discriminators[i]
 	offsetIndex := c.offsets[i]
 	var value any
 	var chType string
-	if typeIndex != c.totalTypes {
+	if typeIndex != DynamicNullDiscriminator {
 		value = c.columns[typeIndex].Row(offsetIndex, ptr)
 		chType = string(c.columns[typeIndex].Type())
 	}
@@ -105,11 +102,12 @@ func (c *Dynamic) Row(i int, ptr bool) any {
 }
 
 func (c *Dynamic) ScanRow(dest any, row int) error {
+	c.syncOffsets()
 	typeIndex := c.discriminators[row]
 	offsetIndex := c.offsets[row]
 	var value any
 	var chType string
-	if typeIndex != c.totalTypes {
+	if typeIndex != DynamicNullDiscriminator {
 		value = c.columns[typeIndex].Row(offsetIndex, false)
 		chType = string(c.columns[typeIndex].Type())
 	}
@@ -122,7 +120,7 @@ func (c *Dynamic) ScanRow(dest any, row int) error {
 		dyn := chcol.NewDynamicWithType(value, chType)
 		**v = dyn
 	default:
-		if typeIndex == c.totalTypes {
+		if typeIndex == DynamicNullDiscriminator {
 			return nil
 		}
 
@@ -302,12 +300,79 @@ func (c *Dynamic) Encode(buffer *proto.Buffer) {
 	c.encodeData(buffer)
 }
 
+func (c *Dynamic) DecodeRowBinary(reader *proto.Reader) error {
+	t, err := decodeBinaryType(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read dynamic value type: %w", err)
+	}
+	c.syncOffsets()
+	if t.Name == "Nothing" {
+		c.discriminators, c.offsets = append(c.discriminators, DynamicNullDiscriminator), append(c.offsets, 0)
+		return nil
+	}
+	typeName := t.String()
+	colIndex, ok := c.columnIndexByName[typeName]
+	if !ok {
+		col, err := Type(typeName).Column("", c.tz)
+		if err != nil {
+			return fmt.Errorf("failed to add dynamic column with type %s: %w", typeName, err)
+		}
+		colIndex = c.addColumn(col)
+	}
+	col := c.columns[colIndex]
+	c.discriminators, c.offsets = append(c.discriminators, colIndex), append(c.offsets, col.Rows())
+	if err := col.DecodeRowBinary(reader); err != nil {
+		return fmt.Errorf("failed to decode dynamic column with %s type: %w", col.Type(), err)
+	}
+	return nil
+}
+
+func (c *Dynamic) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	c.syncOffsets()
+	typeIndex := c.discriminators[row]
+	if typeIndex == DynamicNullDiscriminator {
+		buffer.PutByte(binaryTypeNothing)
+		return nil
+	}
+	for len(c.binaryTypes) < len(c.columns) {
+		t, err := chtype.Parse(string(c.columns[len(c.binaryTypes)].Type()))
+		if err != nil {
+			return err
+		}
+		var typeBuffer proto.Buffer
+		if err := encodeBinaryType(&typeBuffer, t); err != nil {
+			return err
+		}
+		c.binaryTypes = append(c.binaryTypes, typeBuffer.Buf)
+	}
+	buffer.PutRaw(c.binaryTypes[typeIndex])
+	return c.columns[typeIndex].EncodeRowBinary(buffer, c.offsets[row])
+}
+
+// syncOffsets sets the offsets of the rows appended without them
+func (c *Dynamic) syncOffsets() {
+	if len(c.offsets) == len(c.discriminators) {
+		return
+	}
+	c.offsets = c.offsets[:0]
+	rowCountByType := make([]int, len(c.columns))
+	for _, typeIndex := range c.discriminators {
+		if typeIndex == DynamicNullDiscriminator {
+			c.offsets = append(c.offsets, 0)
+			continue
+		}
+		c.offsets = append(c.offsets, rowCountByType[typeIndex])
+		rowCountByType[typeIndex]++
+	}
+}
+
 func (c *Dynamic) ScanType() reflect.Type {
 	return scanTypeDynamic
 }
 
 func (c *Dynamic) Reset() {
 	c.discriminators = c.discriminators[:0]
+	c.offsets = c.offsets[:0]
 
 	for _, col := range c.columns {
 		col.Reset()
@@ -332,6 +397,7 @@ func (c *Dynamic) decodeHeader(reader *proto.Reader) error {
 	}
 
 	c.columns = make([]Interface, 0, totalTypes)
+	c.binaryTypes = nil
 	c.columnIndexByName = make(map[string]int, totalTypes)
 	for i := uint64(0); i < totalTypes; i++ {
 		typeName, err := reader.Str()
@@ -395,11 +461,13 @@ func (c *Dynamic) decodeData(reader *proto.Reader, rows int) error {
 			return fmt.Errorf("failed to read discriminator at index %d: %w", i, err)
 		}
 
-		c.discriminators[i] = disc
-		if disc != c.totalTypes {
-			c.offsets[i] = rowCountByType[disc]
-			rowCountByType[disc]++
+		if disc == c.totalTypes {
+			c.discriminators[i] = DynamicNullDiscriminator
+			continue
 		}
+		c.discriminators[i] = disc
+		c.offsets[i] = rowCountByType[disc]
+		rowCountByType[disc]++
 	}
 
 	for i, col := range c.columns {
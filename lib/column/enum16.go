@@ -278,4 +278,18 @@ func (col *Enum16) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *Enum16) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.Int16()
+	if err != nil {
+		return err
+	}
+	col.col = append(col.col, proto.Enum16(v))
+	return nil
+}
+
+func (col *Enum16) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutInt16(int16(col.col[row]))
+	return nil
+}
+
 var _ Interface = (*Enum16)(nil)
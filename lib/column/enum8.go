@@ -269,4 +269,18 @@ func (col *Enum8) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *Enum8) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.Int8()
+	if err != nil {
+		return err
+	}
+	col.col = append(col.col, proto.Enum8(v))
+	return nil
+}
+
+func (col *Enum8) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutInt8(int8(col.col[row]))
+	return nil
+}
+
 var _ Interface = (*Enum8)(nil)
@@ -27,6 +27,7 @@ import (
 	"github.com/ClickHouse/ch-go/proto"
 
 	"github.com/ClickHouse/clickhouse-go/v2/lib/binary"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 )
 
 type FixedString struct {
@@ -43,9 +44,15 @@ func (col *FixedString) Name() string {
 }
 
 func (col *FixedString) parse(t Type) (*FixedString, error) {
-	if _, err := fmt.Sscanf(string(t), "FixedString(%d)", &col.col.Size); err != nil {
+	parsed, err := chtype.Parse(string(t))
+	if err != nil {
 		return nil, err
 	}
+	if col.col.Size, _ = parsed.Length(); col.col.Size <= 0 {
+		return nil, &UnsupportedColumnTypeError{
+			t: t,
+		}
+	}
 	return col, nil
 }
 
@@ -332,6 +339,20 @@ func (col *FixedString) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *FixedString) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.ReadRaw(col.col.Size)
+	if err != nil {
+		return err
+	}
+	col.col.Append(v)
+	return nil
+}
+
+func (col *FixedString) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutRaw(col.col.Row(row))
+	return nil
+}
+
 func (col *FixedString) row(i int) string {
 	v := col.col.Row(i)
 	return string(v)
@@ -153,6 +153,14 @@ func (col *MultiPolygon) Encode(buffer *proto.Buffer) {
 	col.set.Encode(buffer)
 }
 
+func (col *MultiPolygon) DecodeRowBinary(reader *proto.Reader) error {
+	return col.set.DecodeRowBinary(reader)
+}
+
+func (col *MultiPolygon) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	return col.set.EncodeRowBinary(buffer, row)
+}
+
 func (col *MultiPolygon) row(i int) orb.MultiPolygon {
 	var value []orb.Polygon
 	{
@@ -163,6 +163,25 @@ func (col *Point) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *Point) DecodeRowBinary(reader *proto.Reader) error {
+	x, err := reader.Float64()
+	if err != nil {
+		return err
+	}
+	y, err := reader.Float64()
+	if err != nil {
+		return err
+	}
+	col.col.Append(proto.Point{X: x, Y: y})
+	return nil
+}
+
+func (col *Point) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutFloat64(col.col.X[row])
+	buffer.PutFloat64(col.col.Y[row])
+	return nil
+}
+
 func (col *Point) row(i int) orb.Point {
 	p := col.col.Row(i)
 	return orb.Point{
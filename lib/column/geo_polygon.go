@@ -153,6 +153,14 @@ func (col *Polygon) Encode(buffer *proto.Buffer) {
 	col.set.Encode(buffer)
 }
 
+func (col *Polygon) DecodeRowBinary(reader *proto.Reader) error {
+	return col.set.DecodeRowBinary(reader)
+}
+
+func (col *Polygon) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	return col.set.EncodeRowBinary(buffer, row)
+}
+
 func (col *Polygon) row(i int) orb.Polygon {
 	var value []orb.Ring
 	{
@@ -153,6 +153,14 @@ func (col *Ring) Encode(buffer *proto.Buffer) {
 	col.set.Encode(buffer)
 }
 
+func (col *Ring) DecodeRowBinary(reader *proto.Reader) error {
+	return col.set.DecodeRowBinary(reader)
+}
+
+func (col *Ring) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	return col.set.EncodeRowBinary(buffer, row)
+}
+
 func (col *Ring) row(i int) orb.Ring {
 	var value []orb.Point
 	{
@@ -97,6 +97,20 @@ func (col *Interval) Decode(reader *proto.Reader, rows int) error {
 func (Interval) Encode(buffer *proto.Buffer) {
 }
 
+func (col *Interval) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.Int64()
+	if err != nil {
+		return err
+	}
+	col.col.Append(v)
+	return nil
+}
+
+func (col *Interval) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutInt64(col.col[row])
+	return nil
+}
+
 func (col *Interval) row(i int) string {
 	val := col.col.Row(i)
 	v := fmt.Sprintf("%d %s", val, strings.TrimPrefix(string(col.chType), "Interval"))
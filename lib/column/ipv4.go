@@ -313,6 +313,20 @@ func (col *IPv4) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *IPv4) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.UInt32()
+	if err != nil {
+		return err
+	}
+	col.col = append(col.col, proto.IPv4(v))
+	return nil
+}
+
+func (col *IPv4) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutUInt32(uint32(col.col[row]))
+	return nil
+}
+
 // TODO: This should probably return an netip.Addr
 func (col *IPv4) row(i int) net.IP {
 	src := col.col.Row(i).ToIP()
@@ -360,6 +360,20 @@ func (col *IPv6) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *IPv6) DecodeRowBinary(reader *proto.Reader) error {
+	var v proto.IPv6
+	if err := reader.ReadFull(v[:]); err != nil {
+		return err
+	}
+	col.col.Append(v)
+	return nil
+}
+
+func (col *IPv6) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutRaw(col.col[row][:])
+	return nil
+}
+
 func IPv6ToBytes(ip net.IP) [16]byte {
 	if ip == nil {
 		return [16]byte{}
@@ -18,8 +18,10 @@
 package column
 
 import (
+	"errors"
 	"fmt"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/chcol"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 	"math"
 	"reflect"
 	"strconv"
@@ -64,7 +66,6 @@ type JSON struct {
 func (c *JSON) parse(t Type, tz *time.Location) (_ *JSON, err error) {
 	c.chType = t
 	c.tz = tz
-	tStr := string(t)
 
 	c.serializationVersion = JSONUnsetSerializationVersion
 	c.typedPathsIndex = make(map[string]int)
@@ -73,74 +74,47 @@ func (c *JSON) parse(t Type, tz *time.Location) (_ *JSON, err error) {
 	c.maxDynamicPaths = DefaultMaxDynamicPaths
 	c.maxDynamicTypes = DefaultMaxDynamicTypes
 
-	if tStr == "JSON" {
-		return c, nil
-	}
-
-	if !strings.HasPrefix(tStr, "JSON(") || !strings.HasSuffix(tStr, ")") {
+	parsed, err := chtype.Parse(string(t))
+	if err != nil || parsed.Name != "JSON" {
 		return nil, &UnsupportedColumnTypeError{t: t}
 	}
 
-	typePartsStr := strings.TrimPrefix(tStr, "JSON(")
-	typePartsStr = strings.TrimSuffix(typePartsStr, ")")
-
-	typeParts := splitWithDelimiters(typePartsStr)
-	for _, typePart := range typeParts {
-		typePart = strings.TrimSpace(typePart)
-
-		if strings.HasPrefix(typePart, "max_dynamic_paths=") {
-			v := strings.TrimPrefix(typePart, "max_dynamic_paths=")
-			if maxPaths, err := strconv.Atoi(v); err == nil {
-				c.maxDynamicPaths = maxPaths
+	for _, arg := range parsed.Args {
+		switch arg.Kind {
+		case chtype.SettingArg:
+			switch arg.Name {
+			case "max_dynamic_paths":
+				if maxPaths, err := strconv.Atoi(arg.Value); err == nil {
+					c.maxDynamicPaths = maxPaths
+				}
+			case "max_dynamic_types":
+				if maxTypes, err := strconv.Atoi(arg.Value); err == nil {
+					c.maxDynamicTypes = maxTypes
+				}
 			}
-
-			continue
-		}
-
-		if strings.HasPrefix(typePart, "max_dynamic_types=") {
-			v := strings.TrimPrefix(typePart, "max_dynamic_types=")
-			if maxTypes, err := strconv.Atoi(v); err == nil {
-				c.maxDynamicTypes = maxTypes
+		case chtype.SkipArg, chtype.SkipRegexpArg:
+			path := arg.Name
+			if arg.Kind == chtype.SkipRegexpArg {
+				path = arg.Value
 			}
-
-			continue
-		}
-
-		if strings.HasPrefix(typePart, "SKIP REGEXP") {
-			pattern := strings.TrimPrefix(typePart, "SKIP REGEXP")
-			pattern = strings.Trim(pattern, " '")
-			c.skipPaths = append(c.skipPaths, pattern)
-			c.skipPathsIndex[pattern] = len(c.skipPaths) - 1
-
-			continue
-		}
-
-		if strings.HasPrefix(typePart, "SKIP") {
-			path := strings.TrimPrefix(typePart, "SKIP")
-			path = strings.Trim(path, " `")
 			c.skipPaths = append(c.skipPaths, path)
 			c.skipPathsIndex[path] = len(c.skipPaths) - 1
+		case chtype.TypeArg:
+			if len(arg.Name) == 0 {
+				continue
+			}
+			typedPath, typeName := arg.Name, arg.Type.String()
 
-			continue
-		}
-
-		typedPathParts := strings.SplitN(typePart, " ", 2)
-		if len(typedPathParts) != 2 {
-			continue
-		}
-
-		typedPath := strings.Trim(typedPathParts[0], "`")
-		typeName := strings.TrimSpace(typedPathParts[1])
+			c.typedPaths = append(c.typedPaths, typedPath)
+			c.typedPathsIndex[typedPath] = len(c.typedPaths) - 1
 
-		c.typedPaths = append(c.typedPaths, typedPath)
-		c.typedPathsIndex[typedPath] = len(c.typedPaths) - 1
+			col, err := Type(typeName).Column("", tz)
+			if err != nil {
+				return nil, fmt.Errorf("failed to init column of type \"%s\" at path \"%s\": %w", typeName, typedPath, err)
+			}
 
-		col, err := Type(typeName).Column("", tz)
-		if err != nil {
-			return nil, fmt.Errorf("failed to init column of type \"%s\" at path \"%s\": %w", typeName, typedPath, err)
+			c.typedColumns = append(c.typedColumns, col)
 		}
-
-		c.typedColumns = append(c.typedColumns, col)
 	}
 
 	return c, nil
@@ -753,39 +727,116 @@ func (c *JSON) Decode(reader *proto.Reader, rows int) error {
 	}
 }
 
-// splitWithDelimiters splits the string while considering backticks and parentheses
-func splitWithDelimiters(s string) []string {
-	var parts []string
-	var currentPart strings.Builder
-	var brackets int
-	inBackticks := false
-
-	for i := 0; i < len(s); i++ {
-		switch s[i] {
-		case '`':
-			inBackticks = !inBackticks
-			currentPart.WriteByte(s[i])
-		case '(':
-			brackets++
-			currentPart.WriteByte(s[i])
-		case ')':
-			brackets--
-			currentPart.WriteByte(s[i])
-		case ',':
-			if !inBackticks && brackets == 0 {
-				parts = append(parts, currentPart.String())
-				currentPart.Reset()
-			} else {
-				currentPart.WriteByte(s[i])
+// DecodeRowBinary reads the number of paths of the row and then each path with its value,
+// in the type of a typed path or as Dynamic.
+func (c *JSON) DecodeRowBinary(reader *proto.Reader) error {
+	switch c.serializationVersion {
+	case JSONUnsetSerializationVersion:
+		c.serializationVersion = JSONObjectSerializationVersion
+	case JSONObjectSerializationVersion:
+	default:
+		return fmt.Errorf("unsupported JSON serialization version for RowBinary decode: %d", c.serializationVersion)
+	}
+
+	totalPaths, err := reader.UVarInt()
+	if err != nil {
+		return fmt.Errorf("failed to read total paths for json row: %w", err)
+	}
+
+	for i := uint64(0); i < totalPaths; i++ {
+		path, err := reader.Str()
+		if err != nil {
+			return fmt.Errorf("failed to read path name at index %d for json row: %w", i, err)
+		}
+
+		if typedPathIndex, ok := c.typedPathsIndex[path]; ok {
+			col := c.typedColumns[typedPathIndex]
+			if err := col.DecodeRowBinary(reader); err != nil {
+				return fmt.Errorf("failed to decode %s typed path \"%s\" for json column: %w", col.Type(), path, err)
+			}
+
+			continue
+		}
+
+		dynamicPathIndex, ok := c.dynamicPathsIndex[path]
+		if !ok {
+			// Path doesn't exist, add new dynamic path + column
+			parsedColDynamic, _ := Type("Dynamic").Column("", c.tz)
+			colDynamic := parsedColDynamic.(*Dynamic)
+
+			// New path must back-fill nils for each row
+			for i := 0; i < c.rows; i++ {
+				colDynamic.appendNullRow()
 			}
-		default:
-			currentPart.WriteByte(s[i])
+
+			c.dynamicPaths = append(c.dynamicPaths, path)
+			c.dynamicPathsIndex[path] = len(c.dynamicPaths) - 1
+			c.dynamicColumns = append(c.dynamicColumns, colDynamic)
+			c.totalDynamicPaths++
+			dynamicPathIndex = len(c.dynamicPaths) - 1
+		}
+
+		if err := c.dynamicColumns[dynamicPathIndex].DecodeRowBinary(reader); err != nil {
+			return fmt.Errorf("failed to decode dynamic path \"%s\" for json column: %w", path, err)
+		}
+	}
+
+	// Paths missing from the row get a zero value for typed paths and a nil for dynamic paths
+	for i, col := range c.typedColumns {
+		if col.Rows() > c.rows {
+			continue
+		}
+
+		if err := col.AppendRow(nil); err != nil {
+			return fmt.Errorf("failed to append type %s to json column at typed path %s: %w", col.Type(), c.typedPaths[i], err)
+		}
+	}
+
+	for _, col := range c.dynamicColumns {
+		if col.Rows() <= c.rows {
+			col.appendNullRow()
+		}
+	}
+
+	c.rows++
+	return nil
+}
+
+// EncodeRowBinary writes the number of paths of the row and then each path with its value,
+// leaving out the dynamic paths that are nil in the row.
+func (c *JSON) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	if c.serializationVersion != JSONObjectSerializationVersion {
+		return &Error{
+			ColumnType: string(c.chType),
+			Err:        errors.New("JSON strings are not supported in RowBinary, append chcol.JSON, structs or maps instead"),
+		}
+	}
+
+	totalPaths := len(c.typedColumns)
+	for _, col := range c.dynamicColumns {
+		if col.discriminators[row] != DynamicNullDiscriminator {
+			totalPaths++
 		}
 	}
+	buffer.PutUVarInt(uint64(totalPaths))
 
-	if currentPart.Len() > 0 {
-		parts = append(parts, currentPart.String())
+	for i, col := range c.typedColumns {
+		buffer.PutString(c.typedPaths[i])
+		if err := col.EncodeRowBinary(buffer, row); err != nil {
+			return fmt.Errorf("failed to encode %s typed path \"%s\" for json column: %w", col.Type(), c.typedPaths[i], err)
+		}
 	}
 
-	return parts
+	for i, col := range c.dynamicColumns {
+		if col.discriminators[row] == DynamicNullDiscriminator {
+			continue
+		}
+
+		buffer.PutString(c.dynamicPaths[i])
+		if err := col.EncodeRowBinary(buffer, row); err != nil {
+			return fmt.Errorf("failed to encode dynamic path \"%s\" for json column: %w", c.dynamicPaths[i], err)
+		}
+	}
+
+	return nil
 }
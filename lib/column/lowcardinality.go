@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 	"math"
 	"reflect"
 	"time"
@@ -87,7 +88,13 @@ func (col *LowCardinality) Name() string {
 func (col *LowCardinality) parse(t Type, tz *time.Location) (_ *LowCardinality, err error) {
 	col.chType = t
 	col.append.index = make(map[any]int)
-	if col.index, err = Type(t.params()).Column(col.name, tz); err != nil {
+	parsed, err := chtype.Parse(string(t))
+	if err != nil || parsed.Elem() == nil {
+		return nil, &UnsupportedColumnTypeError{
+			t: t,
+		}
+	}
+	if col.index, err = Type(parsed.Elem().String()).Column(col.name, tz); err != nil {
 		return nil, err
 	}
 	if nullable, ok := col.index.(*Nullable); ok {
@@ -252,6 +259,45 @@ func (col *LowCardinality) Encode(buffer *proto.Buffer) {
 	keys.Encode(buffer)
 }
 
+// DecodeRowBinary appends the value of the row to the dictionary, values are not deduplicated
+func (col *LowCardinality) DecodeRowBinary(reader *proto.Reader) error {
+	if col.index.Rows() == 0 { // init
+		if col.index.AppendRow(nil); col.nullable {
+			col.index.AppendRow(nil)
+		}
+	}
+	col.key = keyUInt64
+	if col.nullable {
+		null, err := reader.UInt8()
+		if err != nil {
+			return err
+		}
+		if null != 0 {
+			col.keys64.col.Append(0)
+			col.rows++
+			return nil
+		}
+	}
+	if err := col.index.DecodeRowBinary(reader); err != nil {
+		return err
+	}
+	col.keys64.col.Append(uint64(col.index.Rows() - 1))
+	col.rows++
+	return nil
+}
+
+func (col *LowCardinality) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	idx := col.indexRowNum(row)
+	if col.nullable {
+		if idx == 0 {
+			buffer.PutUInt8(1)
+			return nil
+		}
+		buffer.PutUInt8(0)
+	}
+	return col.index.EncodeRowBinary(buffer, idx)
+}
+
 func (col *LowCardinality) ReadStatePrefix(reader *proto.Reader) error {
 	keyVersion, err := reader.UInt64()
 	if err != nil {
@@ -284,6 +330,10 @@ func (col *LowCardinality) keys() Interface {
 }
 
 func (col *LowCardinality) indexRowNum(row int) int {
+	if col.keys().Rows() == 0 {
+		// appended rows, not encoded yet
+		return col.append.keys[row]
+	}
 	switch v := col.keys().Row(row, false).(type) {
 	case uint8:
 		return int(v)
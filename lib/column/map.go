@@ -22,10 +22,10 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"reflect"
-	"strings"
 	"time"
 
 	"github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 )
 
 // https://github.com/ClickHouse/ClickHouse/blob/master/src/Columns/ColumnMap.cpp
@@ -67,21 +67,17 @@ func (col *Map) Name() string {
 
 func (col *Map) parse(t Type, tz *time.Location) (_ Interface, err error) {
 	col.chType = t
-	types := make([]string, 2, 2)
-	typeParams := t.params()
-	idx := strings.Index(typeParams, ",")
-	if strings.HasPrefix(typeParams, "Enum") {
-		idx = strings.Index(typeParams, "),") + 1
-	}
-	if idx > 0 {
-		types[0] = typeParams[:idx]
-		types[1] = typeParams[idx+1:]
+	parsed, err := chtype.Parse(string(t))
+	if err != nil {
+		return nil, &UnsupportedColumnTypeError{
+			t: t,
+		}
 	}
-	if types[0] != "" && types[1] != "" {
-		if col.keys, err = Type(strings.TrimSpace(types[0])).Column(col.name, tz); err != nil {
+	if types := parsed.TypeArgs(); len(types) == 2 {
+		if col.keys, err = Type(types[0].Type.String()).Column(col.name, tz); err != nil {
 			return nil, err
 		}
-		if col.values, err = Type(strings.TrimSpace(types[1])).Column(col.name, tz); err != nil {
+		if col.values, err = Type(types[1].Type.String()).Column(col.name, tz); err != nil {
 			return nil, err
 		}
 
@@ -294,6 +290,45 @@ func (col *Map) Encode(buffer *proto.Buffer) {
 	col.values.Encode(buffer)
 }
 
+func (col *Map) DecodeRowBinary(reader *proto.Reader) error {
+	n, err := reader.UVarInt()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < n; i++ {
+		if err := col.keys.DecodeRowBinary(reader); err != nil {
+			return err
+		}
+		if err := col.values.DecodeRowBinary(reader); err != nil {
+			return err
+		}
+	}
+	var prev int64
+	if n := col.offsets.Rows(); n != 0 {
+		prev = col.offsets.col.Row(n - 1)
+	}
+	col.offsets.col.Append(prev + int64(n))
+	return nil
+}
+
+func (col *Map) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	var start int64
+	if row > 0 {
+		start = col.offsets.col.Row(row - 1)
+	}
+	end := col.offsets.col.Row(row)
+	buffer.PutUVarInt(uint64(end - start))
+	for i := int(start); i < int(end); i++ {
+		if err := col.keys.EncodeRowBinary(buffer, i); err != nil {
+			return err
+		}
+		if err := col.values.EncodeRowBinary(buffer, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (col *Map) ReadStatePrefix(reader *proto.Reader) error {
 	if serialize, ok := col.keys.(CustomSerialization); ok {
 		if err := serialize.ReadStatePrefix(reader); err != nil {
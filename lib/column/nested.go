@@ -18,11 +18,10 @@
 package column
 
 import (
-	"fmt"
-	"strings"
 	"time"
 
 	"github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 )
 
 type Nested struct {
@@ -34,58 +33,33 @@ func (col *Nested) Reset() {
 	col.Interface.Reset()
 }
 
-func asDDL(cols []namedCol) string {
-	sCols := make([]string, len(cols), len(cols))
-	for i := range cols {
-		sCols[i] = fmt.Sprintf("%s %s", cols[i].name, cols[i].colType)
-	}
-	return strings.Join(sCols, ", ")
-}
-
 func (col *Nested) parse(t Type, tz *time.Location) (_ Interface, err error) {
-	columns := fmt.Sprintf("Array(Tuple(%s))", asDDL(nestedColumns(t.params())))
-	if col.Interface, err = (&Array{name: col.name}).parse(Type(columns), tz); err != nil {
+	parsed, err := chtype.Parse(string(t))
+	if err != nil || len(parsed.TypeArgs()) == 0 {
+		return nil, &UnsupportedColumnTypeError{
+			t: t,
+		}
+	}
+	columns := Type(nestedArray(parsed).String())
+	if col.Interface, err = (&Array{name: col.name}).parse(columns, tz); err != nil {
 		return nil, err
 	}
 	return col, nil
 }
 
-func nestedColumns(raw string) (columns []namedCol) {
-	var (
-		nBegin   int
-		begin    int
-		brackets int
-	)
-	for i, r := range raw + "," {
-		switch r {
-		case '(':
-			brackets++
-		case ')':
-			brackets--
-		case ' ':
-			if brackets == 0 {
-				begin = i + 1
-			}
-		case ',':
-			if brackets == 0 {
-				columns, begin = append(columns, namedCol{
-					name:    strings.TrimSpace(raw[nBegin:begin]),
-					colType: Type(raw[begin:i]),
-				}), i+1
-				nBegin = i + 1
-				continue
-			}
+// nestedArray returns the Array(Tuple(...)) type a Nested type is stored as.
+func nestedArray(t *chtype.Type) *chtype.Type {
+	tuple := &chtype.Type{Name: "Tuple"}
+	for _, arg := range t.TypeArgs() {
+		if arg.Type.Name == "Nested" {
+			arg.Type = nestedArray(arg.Type)
 		}
+		tuple.Args = append(tuple.Args, arg)
 	}
-	for i, column := range columns {
-		if strings.HasPrefix(string(column.colType), "Nested(") {
-			columns[i] = namedCol{
-				colType: Type(fmt.Sprintf("Array(Tuple(%s))", asDDL(nestedColumns(column.colType.params())))),
-				name:    column.name,
-			}
-		}
+	return &chtype.Type{
+		Name: "Array",
+		Args: []chtype.Arg{{Kind: chtype.TypeArg, Type: tuple}},
 	}
-	return
 }
 
 func (col *Nested) ReadStatePrefix(reader *proto.Reader) error {
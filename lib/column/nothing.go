@@ -63,4 +63,15 @@ func (col Nothing) Decode(reader *proto.Reader, rows int) error {
 func (Nothing) Encode(buffer *proto.Buffer) {
 }
 
+// DecodeRowBinary skips the placeholder byte of the row, as Decode does
+func (Nothing) DecodeRowBinary(reader *proto.Reader) error {
+	_, err := reader.ReadByte()
+	return err
+}
+
+func (Nothing) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutByte(0)
+	return nil
+}
+
 var _ Interface = (*Nothing)(nil)
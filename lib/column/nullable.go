@@ -21,6 +21,7 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 	"reflect"
 	"time"
 )
@@ -44,7 +45,13 @@ func (col *Nullable) Name() string {
 
 func (col *Nullable) parse(t Type, tz *time.Location) (_ *Nullable, err error) {
 	col.enable = true
-	if col.base, err = Type(t.params()).Column(col.name, tz); err != nil {
+	parsed, err := chtype.Parse(string(t))
+	if err != nil || parsed.Elem() == nil {
+		return nil, &UnsupportedColumnTypeError{
+			t: t,
+		}
+	}
+	if col.base, err = Type(parsed.Elem().String()).Column(col.name, tz); err != nil {
 		return nil, err
 	}
 	switch base := col.base.ScanType(); {
@@ -183,4 +190,34 @@ func (col *Nullable) Encode(buffer *proto.Buffer) {
 	col.base.Encode(buffer)
 }
 
+func (col *Nullable) DecodeRowBinary(reader *proto.Reader) error {
+	if !col.enable {
+		return col.base.DecodeRowBinary(reader)
+	}
+	null, err := reader.UInt8()
+	if err != nil {
+		return err
+	}
+	col.nulls.Append(null)
+	if null == 0 {
+		return col.base.DecodeRowBinary(reader)
+	}
+	// as in Native, the base keeps a value for the null rows
+	if _, ok := col.base.(*Nothing); ok {
+		return nil
+	}
+	return col.base.AppendRow(nil)
+}
+
+func (col *Nullable) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	if !col.enable {
+		return col.base.EncodeRowBinary(buffer, row)
+	}
+	buffer.PutUInt8(col.nulls[row])
+	if col.nulls[row] != 0 {
+		return nil
+	}
+	return col.base.EncodeRowBinary(buffer, row)
+}
+
 var _ Interface = (*Nullable)(nil)
@@ -18,6 +18,8 @@
 package column
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -938,6 +940,56 @@ func (jCol *JSONObject) Encode(buffer *proto.Buffer) {
 	}
 }
 
+// DecodeRowBinary fails as for Decode, ClickHouse returns Object('json') columns as tuples
+func (jCol *JSONObject) DecodeRowBinary(reader *proto.Reader) error {
+	return &Error{
+		ColumnType: string(jCol.Type()),
+		Err:        errors.New("reading Object('json') columns is not supported"),
+	}
+}
+
+// EncodeRowBinary writes the row as a JSON string, the RowBinary format of Object('json')
+func (jCol *JSONObject) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	if jCol.encoding == 1 {
+		return jCol.columns[0].EncodeRowBinary(buffer, row)
+	}
+	data, err := json.Marshal(jCol.rowMap(row))
+	if err != nil {
+		return &Error{
+			ColumnType: string(jCol.Type()),
+			Err:        err,
+		}
+	}
+	buffer.PutUVarInt(uint64(len(data)))
+	buffer.PutRaw(data)
+	return nil
+}
+
+// rowMap returns the row as a map of the names of the columns to their values
+func (jCol *JSONObject) rowMap(row int) map[string]any {
+	values := make(map[string]any, len(jCol.columns))
+	for _, c := range jCol.columns {
+		switch c := c.(type) {
+		case *JSONObject:
+			values[c.Name()] = c.rowMap(row)
+		case *JSONList:
+			offsets := c.offsets[0].values.col
+			var start uint64
+			if row > 0 {
+				start = offsets.Row(row - 1)
+			}
+			objects := make([]map[string]any, 0, offsets.Row(row)-start)
+			for i := start; i < offsets.Row(row); i++ {
+				objects = append(objects, c.values.(*JSONObject).rowMap(int(i)))
+			}
+			values[c.Name()] = objects
+		default:
+			values[c.Name()] = c.Row(row, false)
+		}
+	}
+	return values
+}
+
 func (jCol *JSONObject) ReadStatePrefix(reader *proto.Reader) error {
 	_, err := reader.UInt8()
 	return err
@@ -19,8 +19,8 @@ package column
 
 import (
 	"github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 	"reflect"
-	"strings"
 	"time"
 )
 
@@ -40,9 +40,14 @@ func (col *SimpleAggregateFunction) Name() string {
 
 func (col *SimpleAggregateFunction) parse(t Type, tz *time.Location) (_ Interface, err error) {
 	col.chType = t
-	base := strings.TrimSpace(strings.SplitN(t.params(), ",", 2)[1])
-	if col.base, err = Type(base).Column(col.name, tz); err == nil {
-		return col, nil
+	// the first argument is the aggregate function, the last one is the stored type
+	if parsed, err := chtype.Parse(string(t)); err == nil {
+		if args := parsed.TypeArgs(); len(args) >= 2 {
+			base := Type(args[len(args)-1].Type.String())
+			if col.base, err = base.Column(col.name, tz); err == nil {
+				return col, nil
+			}
+		}
 	}
 	return nil, &UnsupportedColumnTypeError{
 		t: t,
@@ -77,4 +82,12 @@ func (col *SimpleAggregateFunction) Encode(buffer *proto.Buffer) {
 	col.base.Encode(buffer)
 }
 
+func (col *SimpleAggregateFunction) DecodeRowBinary(reader *proto.Reader) error {
+	return col.base.DecodeRowBinary(reader)
+}
+
+func (col *SimpleAggregateFunction) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	return col.base.EncodeRowBinary(buffer, row)
+}
+
 var _ Interface = (*SimpleAggregateFunction)(nil)
@@ -62,6 +62,25 @@ func (col *String) Row(i int, ptr bool) any {
 	return val
 }
 
+// Data returns a copy of the column values.
+func (col *String) Data() []string {
+	values := make([]string, col.col.Rows())
+	for i := range values {
+		values[i] = col.col.Row(i)
+	}
+	return values
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *String) AppendValue(v string) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *String) Value(i int) string {
+	return col.col.Row(i)
+}
+
 func (col *String) ScanRow(dest any, row int) error {
 	val := col.Row(row, false).(string)
 	switch d := dest.(type) {
@@ -227,4 +246,20 @@ func (col *String) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *String) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.StrRaw()
+	if err != nil {
+		return err
+	}
+	col.col.AppendBytes(v)
+	return nil
+}
+
+func (col *String) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	v := col.col.RowBytes(row)
+	buffer.PutUVarInt(uint64(len(v)))
+	buffer.PutRaw(v)
+	return nil
+}
+
 var _ Interface = (*String)(nil)
@@ -23,11 +23,11 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/ClickHouse/ch-go/proto"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/timezone"
 )
 
@@ -55,20 +55,23 @@ func (col *Time) Name() string {
 // parse parses the ClickHouse type definition and sets timezone if present.
 func (col *Time) parse(t Type, tz *time.Location) (_ Interface, err error) {
 	col.chType = t
-	// Handle Time('UTC') format
-	if strings.HasPrefix(string(t), "Time('") {
-		timezoneName := strings.TrimSuffix(strings.TrimPrefix(string(t), "Time('"), "')")
-		timezone, err := timezone.Load(timezoneName)
-		if err != nil {
-			return nil, err
+	parsed, err := chtype.Parse(string(t))
+	if err != nil || parsed.Name != "Time" {
+		return nil, &UnsupportedColumnTypeError{
+			t: t,
 		}
-		col.timezone = timezone
-		return col, nil
 	}
+	switch {
 	// Handle plain Time format
-	if string(t) == "Time" {
+	case len(parsed.Args) == 0:
 		col.timezone = tz
 		return col, nil
+	// Handle Time('UTC') format
+	case len(parsed.Args) == 1 && parsed.Args[0].Kind == chtype.StringArg:
+		if col.timezone, err = timezone.Load(parsed.Args[0].Value); err != nil {
+			return nil, err
+		}
+		return col, nil
 	}
 	return nil, &UnsupportedColumnTypeError{
 		t: t,
@@ -302,6 +305,20 @@ func (col *Time) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *Time) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.Int32()
+	if err != nil {
+		return err
+	}
+	col.col.Data = append(col.col.Data, proto.Time32(v))
+	return nil
+}
+
+func (col *Time) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutInt32(int32(col.col.Data[row]))
+	return nil
+}
+
 func (col *Time) row(i int) time.Time {
 	time := col.col.Row(i)
 	if col.timezone != nil {
@@ -23,11 +23,11 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/ClickHouse/ch-go/proto"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/timezone"
 )
 
@@ -57,45 +57,25 @@ func (col *Time64) Name() string {
 // parse parses the ClickHouse type definition and sets precision and timezone if present.
 func (col *Time64) parse(t Type, tz *time.Location) (_ Interface, err error) {
 	col.chType = t
-	// Handle Time64(6) format
-	if strings.HasPrefix(string(t), "Time64(") {
-		// Check if it has timezone parameter
-		if strings.Contains(string(t), ",") {
-			// Format: Time64(6, 'UTC')
-			parts := strings.Split(string(t), ",")
-			if len(parts) != 2 {
+	parsed, err := chtype.Parse(string(t))
+	if err != nil {
+		return nil, &UnsupportedColumnTypeError{
+			t: t,
+		}
+	}
+	// Handle Time64(6) and Time64(6, 'UTC') formats
+	if precision, ok := parsed.Precision(); ok && len(parsed.Args) <= 2 {
+		col.col.WithPrecision(proto.Precision(precision))
+		col.timezone = tz
+		if len(parsed.Args) == 2 {
+			if parsed.Args[1].Kind != chtype.StringArg {
 				return nil, &UnsupportedColumnTypeError{t: t}
 			}
-
-			// Parse precision
-			precisionStr := strings.TrimSuffix(strings.TrimPrefix(parts[0], "Time64("), ")")
-			precision, err := strconv.ParseInt(precisionStr, 10, 8)
-			if err != nil {
-				return nil, err
-			}
-			p := byte(precision)
-			col.col.WithPrecision(proto.Precision(p))
-
-			// Parse timezone
-			timezoneName := strings.TrimSuffix(strings.TrimPrefix(parts[1], " '"), "')")
-			timezone, err := timezone.Load(timezoneName)
-			if err != nil {
-				return nil, err
-			}
-			col.timezone = timezone
-			return col, nil
-		} else {
-			// Format: Time64(6)
-			params := strings.TrimSuffix(strings.TrimPrefix(string(t), "Time64("), ")")
-			precision, err := strconv.ParseInt(params, 10, 8)
-			if err != nil {
+			if col.timezone, err = timezone.Load(parsed.Args[1].Value); err != nil {
 				return nil, err
 			}
-			p := byte(precision)
-			col.col.WithPrecision(proto.Precision(p))
-			col.timezone = tz
-			return col, nil
 		}
+		return col, nil
 	}
 	return nil, &UnsupportedColumnTypeError{
 		t: t,
@@ -336,6 +316,20 @@ func (col *Time64) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+func (col *Time64) DecodeRowBinary(reader *proto.Reader) error {
+	v, err := reader.Int64()
+	if err != nil {
+		return err
+	}
+	col.col.Data = append(col.col.Data, proto.Time64(v))
+	return nil
+}
+
+func (col *Time64) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	buffer.PutInt64(int64(col.col.Data[row]))
+	return nil
+}
+
 func (col *Time64) row(i int) time.Time {
 	time := col.col.Row(i)
 	if col.timezone != nil {
@@ -23,10 +23,10 @@ import (
 	"fmt"
 	"net"
 	"reflect"
-	"strings"
 	"time"
 
 	"github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
@@ -56,43 +56,19 @@ type namedCol struct {
 
 func (col *Tuple) parse(t Type, tz *time.Location) (_ Interface, err error) {
 	col.chType = t
-	var (
-		element       []rune
-		elements      []namedCol
-		brackets      int
-		appendElement = func() {
-			if len(element) != 0 {
-				cType := strings.TrimSpace(string(element))
-				name := ""
-				if parts := strings.SplitN(cType, " ", 2); len(parts) == 2 {
-					if !strings.Contains(parts[0], "(") {
-						name = parts[0]
-						cType = parts[1]
-					}
-				}
-				elements = append(elements, namedCol{
-					name:    name,
-					colType: Type(strings.TrimSpace(cType)),
-				})
-			}
-		}
-	)
-	for _, r := range t.params() {
-		switch r {
-		case '(':
-			brackets++
-		case ')':
-			brackets--
-		case ',':
-			if brackets == 0 {
-				appendElement()
-				element = element[:0]
-				continue
-			}
+	parsed, err := chtype.Parse(string(t))
+	if err != nil {
+		return nil, &UnsupportedColumnTypeError{
+			t: t,
 		}
-		element = append(element, r)
 	}
-	appendElement()
+	var elements []namedCol
+	for _, arg := range parsed.TypeArgs() {
+		elements = append(elements, namedCol{
+			name:    arg.Name,
+			colType: Type(arg.Type.String()),
+		})
+	}
 	isNamed := true
 	col.index = make(map[string]int)
 	for i, ct := range elements {
@@ -656,6 +632,24 @@ func (col *Tuple) Encode(buffer *proto.Buffer) {
 	}
 }
 
+func (col *Tuple) DecodeRowBinary(reader *proto.Reader) error {
+	for _, c := range col.columns {
+		if err := c.DecodeRowBinary(reader); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (col *Tuple) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	for _, c := range col.columns {
+		if err := c.EncodeRowBinary(buffer, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (col *Tuple) ReadStatePrefix(reader *proto.Reader) error {
 	for _, c := range col.columns {
 		if serialize, ok := c.(CustomSerialization); ok {
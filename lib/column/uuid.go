@@ -20,6 +20,7 @@ package column
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding/binary"
 	"fmt"
 	"github.com/ClickHouse/ch-go/proto"
 	"reflect"
@@ -60,6 +61,22 @@ func (col *UUID) Row(i int, ptr bool) any {
 	return value
 }
 
+// Data returns the column values without copying.
+// The slice is only valid until the column is reset or decoded into again.
+func (col *UUID) Data() []uuid.UUID {
+	return col.col
+}
+
+// AppendValue appends v, it is the typed counterpart of AppendRow.
+func (col *UUID) AppendValue(v uuid.UUID) {
+	col.col.Append(v)
+}
+
+// Value returns the value of row i, it is the typed counterpart of Row.
+func (col *UUID) Value(i int) uuid.UUID {
+	return col.row(i)
+}
+
 func (col *UUID) ScanRow(dest any, row int) error {
 	switch d := dest.(type) {
 	case *string:
@@ -216,6 +233,27 @@ func (col *UUID) Encode(buffer *proto.Buffer) {
 	col.col.EncodeColumn(buffer)
 }
 
+// DecodeRowBinary reads the UUID as two little endian UInt64, as for Native
+func (col *UUID) DecodeRowBinary(reader *proto.Reader) error {
+	var v uuid.UUID
+	for i := 0; i < len(v); i += 8 {
+		half, err := reader.UInt64()
+		if err != nil {
+			return err
+		}
+		binary.BigEndian.PutUint64(v[i:i+8], half)
+	}
+	col.col.Append(v)
+	return nil
+}
+
+func (col *UUID) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	v := col.col[row]
+	buffer.PutUInt64(binary.BigEndian.Uint64(v[0:8]))
+	buffer.PutUInt64(binary.BigEndian.Uint64(v[8:16]))
+	return nil
+}
+
 func (col *UUID) row(i int) (uuid uuid.UUID) {
 	return col.col.Row(i)
 }
@@ -22,10 +22,10 @@ import (
 	"fmt"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/chcol"
 	"reflect"
-	"strings"
 	"time"
 
 	"github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
 )
 
 const SupportedVariantSerializationVersion = 0
@@ -44,41 +44,16 @@ type Variant struct {
 
 func (c *Variant) parse(t Type, tz *time.Location) (_ *Variant, err error) {
 	c.chType = t
-	var (
-		element       []rune
-		elements      []Type
-		brackets      int
-		appendElement = func() {
-			if len(element) != 0 {
-				cType := strings.TrimSpace(string(element))
-				if parts := strings.SplitN(cType, " ", 2); len(parts) == 2 {
-					if !strings.Contains(parts[0], "(") {
-						cType = parts[1]
-					}
-				}
-
-				elements = append(elements, Type(strings.TrimSpace(cType)))
-			}
-		}
-	)
-
-	for _, r := range t.params() {
-		switch r {
-		case '(':
-			brackets++
-		case ')':
-			brackets--
-		case ',':
-			if brackets == 0 {
-				appendElement()
-				element = element[:0]
-				continue
-			}
+	parsed, err := chtype.Parse(string(t))
+	if err != nil {
+		return nil, &UnsupportedColumnTypeError{
+			t: t,
 		}
-		element = append(element, r)
 	}
-
-	appendElement()
+	var elements []Type
+	for _, arg := range parsed.TypeArgs() {
+		elements = append(elements, Type(arg.Type.String()))
+	}
 
 	c.columnTypeIndex = make(map[string]uint8, len(elements))
 	for _, columnType := range elements {
@@ -125,6 +100,7 @@ func (c *Variant) Rows() int {
 }
 
 func (c *Variant) Row(i int, ptr bool) any {
+	c.syncOffsets()
 	typeIndex := c.discriminators[i]
 	offsetIndex := c.offsets[i]
 	var value any
@@ -143,6 +119,7 @@ func (c *Variant) Row(i int, ptr bool) any {
 }
 
 func (c *Variant) ScanRow(dest any, row int) error {
+	c.syncOffsets()
 	typeIndex := c.discriminators[row]
 	offsetIndex := c.offsets[row]
 	var value any
@@ -293,12 +270,57 @@ func (c *Variant) Encode(buffer *proto.Buffer) {
 	c.encodeData(buffer)
 }
 
+func (c *Variant) DecodeRowBinary(reader *proto.Reader) error {
+	disc, err := reader.ReadByte()
+	if err != nil {
+		return fmt.Errorf("failed to read discriminator: %w", err)
+	}
+	c.syncOffsets()
+	if disc == NullVariantDiscriminator {
+		c.discriminators, c.offsets = append(c.discriminators, disc), append(c.offsets, 0)
+		return nil
+	}
+	if int(disc) >= len(c.columns) {
+		return fmt.Errorf("invalid variant discriminator %d for %d types", disc, len(c.columns))
+	}
+	col := c.columns[disc]
+	c.discriminators, c.offsets = append(c.discriminators, disc), append(c.offsets, col.Rows())
+	if err := col.DecodeRowBinary(reader); err != nil {
+		return fmt.Errorf("failed to decode variant column with %s type: %w", col.Type(), err)
+	}
+	return nil
+}
+
+func (c *Variant) EncodeRowBinary(buffer *proto.Buffer, row int) error {
+	c.syncOffsets()
+	disc := c.discriminators[row]
+	buffer.PutByte(disc)
+	if disc == NullVariantDiscriminator {
+		return nil
+	}
+	return c.columns[disc].EncodeRowBinary(buffer, c.offsets[row])
+}
+
+// syncOffsets sets the offsets of the rows appended without them
+func (c *Variant) syncOffsets() {
+	if len(c.offsets) == len(c.discriminators) {
+		return
+	}
+	c.offsets = c.offsets[:0]
+	rowCountByType := make(map[uint8]int, len(c.columns))
+	for _, disc := range c.discriminators {
+		c.offsets = append(c.offsets, rowCountByType[disc])
+		rowCountByType[disc]++
+	}
+}
+
 func (c *Variant) ScanType() reflect.Type {
 	return scanTypeVariant
 }
 
 func (c *Variant) Reset() {
 	c.discriminators = c.discriminators[:0]
+	c.offsets = c.offsets[:0]
 
 	for _, col := range c.columns {
 		col.Reset()
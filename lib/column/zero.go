@@ -0,0 +1,64 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package column
+
+import (
+	"cmp"
+	"reflect"
+
+	"github.com/ClickHouse/ch-go/proto"
+)
+
+// Zero returns a value that appends the default value of the column type to col: the zero
+// value of its scan type, NULL for Nullable columns, the lowest value for Enum columns and
+// the defaults of the elements for Tuple columns.
+func Zero(col Interface) any {
+	switch col := col.(type) {
+	case *Enum8:
+		return minEnum(col.vi)
+	case *Enum16:
+		return minEnum(col.vi)
+	case *Tuple:
+		values := make([]any, 0, len(col.columns))
+		for _, c := range col.columns {
+			values = append(values, Zero(c))
+		}
+		return values
+	case *SimpleAggregateFunction:
+		return Zero(col.base)
+	}
+	if t := col.ScanType(); t != nil {
+		return reflect.Zero(t).Interface()
+	}
+	return nil
+}
+
+// minEnum returns the name of the lowest enum value, ClickHouse's default of Enum types
+func minEnum[T proto.Enum8 | proto.Enum16](vi map[T]string) any {
+	var (
+		name  string
+		value T
+		found bool
+	)
+	for v, n := range vi {
+		if !found || cmp.Less(v, value) {
+			name, value, found = n, v, true
+		}
+	}
+	return name
+}
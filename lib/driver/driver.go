@@ -19,6 +19,7 @@ package driver
 
 import (
 	"context"
+	"io"
 	"reflect"
 	"time"
 
@@ -46,6 +47,17 @@ type (
 		Open         int
 		Idle         int
 	}
+
+	// TableColumn is a table column as described by DESCRIBE TABLE.
+	TableColumn struct {
+		Name              string
+		Type              string
+		DefaultKind       string // DEFAULT, MATERIALIZED, ALIAS, EPHEMERAL or empty
+		DefaultExpression string
+		Comment           string
+		CodecExpression   string
+		TTLExpression     string
+	}
 )
 
 type (
@@ -62,6 +74,20 @@ type (
 		Stats() Stats
 		Close() error
 	}
+	// RawConn passes data encoded in any ClickHouse format through as is. It is implemented by the
+	// Conn of clickhouse.Open, e.g. if raw, ok := conn.(driver.RawConn); ok { raw.InsertFrom(...) }
+	RawConn interface {
+		Conn
+		QueryRaw(ctx context.Context, query string, args ...any) (io.ReadCloser, error)
+		InsertFrom(ctx context.Context, query string, r io.Reader) error
+	}
+	// SchemaConn describes tables. It is implemented by the Conn of clickhouse.Open,
+	// e.g. if schema, ok := conn.(driver.SchemaConn); ok { schema.DescribeTable(...) }
+	SchemaConn interface {
+		Conn
+		DescribeTable(ctx context.Context, database, table string) ([]TableColumn, error)
+		InvalidateTableSchema(database, table string)
+	}
 	Row interface {
 		Err() error
 		Scan(dest ...any) error
@@ -77,6 +103,13 @@ type (
 		Close() error
 		Err() error
 	}
+	// BlockRows reads a result a block at a time. It is implemented by the Rows of Conn.Query,
+	// e.g. if blocks, ok := rows.(driver.BlockRows); ok { for blocks.NextBlock() { ... } }
+	BlockRows interface {
+		Rows
+		NextBlock() bool
+		Block() *proto.Block
+	}
 	Batch interface {
 		Abort() error
 		Append(v ...any) error
@@ -89,6 +122,14 @@ type (
 		Columns() []column.Interface
 		Close() error
 	}
+	// SchemaBatch describes the table a batch inserts into. It is implemented by the Batch of
+	// Conn.PrepareBatch, e.g. if schema, ok := batch.(driver.SchemaBatch); ok { schema.TableColumns() }
+	SchemaBatch interface {
+		Batch
+		// TableColumns describes the columns of the table, including their defaults,
+		// as sent by the server for the INSERT. It is nil if the server did not send them.
+		TableColumns() []TableColumn
+	}
 	BatchColumn interface {
 		Append(any) error
 		AppendRow(any) error
@@ -99,4 +140,35 @@ type (
 		ScanType() reflect.Type
 		DatabaseTypeName() string
 	}
+	// DetailedColumnType describes the parameters of a column type. It is implemented by the ColumnTypes
+	// of Conn.Query, e.g. if detailed, ok := columnType.(driver.DetailedColumnType); ok { detailed.Length() }
+	DetailedColumnType interface {
+		ColumnType
+		// DecimalSize returns the precision and scale of Decimal types and the precision of DateTime64 types.
+		DecimalSize() (precision, scale int64, ok bool)
+		// Length returns the length of FixedString types.
+		Length() (length int64, ok bool)
+		// Timezone returns the timezone of DateTime and DateTime64 types declared with one.
+		Timezone() (name string, ok bool)
+		// EnumValues returns the names and values of Enum8 and Enum16 types.
+		EnumValues() map[string]int
+		// LowCardinality reports whether the type is wrapped in LowCardinality.
+		LowCardinality() bool
+		// Children returns the element type of Array, the key and value types of Map and the
+		// element types of Tuple, Nested and Variant, named after the element where applicable.
+		// They implement DetailedColumnType as well.
+		Children() []ColumnType
+	}
+	// StructAppender is implemented by structs with generated AppendTo methods (see lib/structgen).
+	// Batch.AppendStruct calls AppendTo with the columns of the batch instead of mapping the struct
+	// with reflection, AppendTo appends one row to each of them.
+	StructAppender interface {
+		AppendTo(columns []column.Interface) error
+	}
+	// StructScanner is implemented by structs with generated ScanFrom methods (see lib/structgen).
+	// Rows.ScanStruct and Row.ScanStruct call ScanFrom with the columns of the current block and
+	// the index of the current row instead of mapping the struct with reflection.
+	StructScanner interface {
+		ScanFrom(columns []column.Interface, row int) error
+	}
 )
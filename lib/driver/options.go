@@ -1,8 +1,12 @@
 package driver
 
 type PrepareBatchOptions struct {
-	ReleaseConnection bool
-	CloseOnFlush      bool
+	ReleaseConnection  bool
+	CloseOnFlush       bool
+	ReplayBuffer       bool
+	DeduplicationToken string
+	OmitDefaults       bool
+	StructValidation   any
 }
 
 type PrepareBatchOption func(options *PrepareBatchOptions)
@@ -19,3 +23,43 @@ func WithCloseOnFlush() PrepareBatchOption {
 		options.CloseOnFlush = true
 	}
 }
+
+// WithReplayBuffer keeps the data of every flushed block until Send succeeds.
+// A failed Send or Flush can then be retried by calling it again: the batch releases the
+// connection on any error, acquires a new connection (possibly to another replica) and
+// replays all buffered blocks. Combined with WithCloseOnFlush, only the blocks not yet
+// committed by a Flush are kept.
+// Every attempt carries the same insert_deduplication_token, generated automatically
+// unless set with WithDeduplicationToken or the insert_deduplication_token setting.
+func WithReplayBuffer() PrepareBatchOption {
+	return func(options *PrepareBatchOptions) {
+		options.ReplayBuffer = true
+	}
+}
+
+// WithDeduplicationToken sets the insert_deduplication_token used by the batch INSERT query
+func WithDeduplicationToken(token string) PrepareBatchOption {
+	return func(options *PrepareBatchOptions) {
+		options.DeduplicationToken = token
+	}
+}
+
+// WithOmitDefaults lets Append and AppendStruct leave out columns with a DEFAULT or EPHEMERAL
+// expression, the server then computes their values. The first appended row decides which
+// columns are left out: Append with one value per column without a default, or AppendStruct
+// with a struct that has no fields for them. Requires the column descriptions the server sends
+// with input_format_defaults_for_omitted_fields (enabled by default), or DESCRIBE TABLE over HTTP.
+func WithOmitDefaults() PrepareBatchOption {
+	return func(options *PrepareBatchOptions) {
+		options.OmitDefaults = true
+	}
+}
+
+// WithStructValidation checks the columns of the batch against the fields of struct v, as used by
+// AppendStruct, when the batch is prepared. Missing fields and field types that cannot be converted
+// to their column types are reported together in one error, instead of on the first AppendStruct.
+func WithStructValidation(v any) PrepareBatchOption {
+	return func(options *PrepareBatchOptions) {
+		options.StructValidation = v
+	}
+}
@@ -0,0 +1,55 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package native reads and writes files in the Native format of ClickHouse, a stream of blocks as
+// written by clickhouse-local with FORMAT Native and read by INSERT ... FORMAT Native. Files can be
+// compressed with the framing of the ClickHouse compressed buffers, as for HTTP with compress=1 and
+// clickhouse-compressor.
+package native
+
+import (
+	"fmt"
+
+	"github.com/ClickHouse/ch-go/compress"
+)
+
+// Compression is the compression method of a file.
+type Compression byte
+
+const (
+	CompressionNone  = Compression(compress.None)
+	CompressionLZ4   = Compression(compress.LZ4)
+	CompressionLZ4HC = Compression(compress.LZ4HC)
+	CompressionZSTD  = Compression(compress.ZSTD)
+)
+
+// frameSize is the size of the uncompressed data of a compressed frame, as by ClickHouse
+const frameSize = 1 << 20
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionLZ4:
+		return "lz4"
+	case CompressionLZ4HC:
+		return "lz4hc"
+	case CompressionZSTD:
+		return "zstd"
+	}
+	return fmt.Sprintf("Compression(%d)", byte(c))
+}
@@ -0,0 +1,117 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package native
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBlock(t *testing.T, rows int) *proto.Block {
+	block := &proto.Block{Timezone: time.UTC}
+	for _, c := range []struct{ name, typ string }{
+		{"id", "UInt64"},
+		{"name", "LowCardinality(String)"},
+		{"ts", "DateTime"},
+		{"tags", "Array(Nullable(String))"},
+	} {
+		require.NoError(t, block.AddColumn(c.name, column.Type(c.typ)))
+	}
+	tag := "tag"
+	for i := 0; i < rows; i++ {
+		require.NoError(t, block.Append(uint64(i), strings.Repeat("x", i%7), time.Unix(int64(1700000000+i), 0), []*string{&tag, nil}))
+	}
+	return block
+}
+
+func TestWriteRead(t *testing.T) {
+	for _, compression := range []Compression{CompressionNone, CompressionLZ4, CompressionLZ4HC, CompressionZSTD} {
+		t.Run(compression.String(), func(t *testing.T) {
+			var file bytes.Buffer
+			w, err := NewWriter(&file, compression)
+			require.NoError(t, err)
+			// the second block spans several compressed frames
+			blocks := []*proto.Block{testBlock(t, 10), testBlock(t, 200_000), testBlock(t, 0)}
+			for _, block := range blocks {
+				require.NoError(t, w.WriteBlock(block))
+			}
+
+			r := NewReader(&file, compression)
+			var n int
+			for block, err := range r.Blocks() {
+				require.NoError(t, err)
+				require.Less(t, n, len(blocks))
+				assert.Equal(t, blocks[n].ColumnsNames(), block.ColumnsNames())
+				require.Equal(t, blocks[n].Rows(), block.Rows())
+				if rows := block.Rows(); rows > 0 {
+					assert.Equal(t, uint64(rows-1), block.Columns[0].Row(rows-1, false))
+					assert.Equal(t, int64(1700000000+rows-1), block.Columns[2].Row(rows-1, false).(time.Time).Unix())
+				}
+				n++
+			}
+			assert.Equal(t, len(blocks), n)
+			_, err = r.ReadBlock()
+			assert.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func TestReadClickHouseOutput(t *testing.T) {
+	// clickhouse-local --query "SELECT 1::UInt8 AS x, 'a' AS s FORMAT Native"
+	data := []byte{
+		0x02, 0x01,
+		0x01, 'x', 0x05, 'U', 'I', 'n', 't', '8', 0x01,
+		0x01, 's', 0x06, 'S', 't', 'r', 'i', 'n', 'g', 0x01, 'a',
+	}
+	block, err := NewReader(bytes.NewReader(data), CompressionNone).ReadBlock()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x", "s"}, block.ColumnsNames())
+	assert.Equal(t, uint8(1), block.Columns[0].Row(0, false))
+	assert.Equal(t, "a", block.Columns[1].Row(0, false))
+
+	var file bytes.Buffer
+	w, err := NewWriter(&file, CompressionNone)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteBlock(block))
+	assert.Equal(t, data, file.Bytes())
+}
+
+func TestReadTruncated(t *testing.T) {
+	for _, compression := range []Compression{CompressionNone, CompressionLZ4} {
+		var file bytes.Buffer
+		w, err := NewWriter(&file, compression)
+		require.NoError(t, err)
+		require.NoError(t, w.WriteBlock(testBlock(t, 10)))
+
+		_, err = NewReader(bytes.NewReader(file.Bytes()[:file.Len()-3]), compression).ReadBlock()
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF, compression.String())
+	}
+}
+
+func TestUnsupportedCompression(t *testing.T) {
+	_, err := NewWriter(io.Discard, Compression(42))
+	assert.EqualError(t, err, "native: unsupported compression 42")
+}
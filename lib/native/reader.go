@@ -0,0 +1,104 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package native
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"iter"
+	"time"
+
+	"github.com/ClickHouse/ch-go/compress"
+	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+)
+
+// Reader reads blocks from a Native file.
+type Reader struct {
+	// Timezone is the timezone of DateTime columns without one, UTC if it is nil.
+	Timezone *time.Location
+
+	source *bufio.Reader
+	reader *chproto.Reader
+	err    error
+}
+
+// NewReader returns a reader of blocks from r. The compression method of compressed files is
+// detected, any method but CompressionNone can be given for them.
+func NewReader(r io.Reader, compression Compression) *Reader {
+	if compression != CompressionNone {
+		r = compress.NewReader(r)
+	}
+	// at least the buffer size of chproto.Reader, so that it reads from source rather than
+	// buffering ahead of it, and ReadBlock can peek the end of the file
+	source := bufio.NewReaderSize(r, 128<<10)
+	return &Reader{
+		source: source,
+		reader: chproto.NewReader(source),
+	}
+}
+
+// ReadBlock returns the next block, or io.EOF at the end of the file.
+func (r *Reader) ReadBlock() (*proto.Block, error) {
+	// errors are final, the compressed reader does not keep them
+	if r.err != nil {
+		return nil, r.err
+	}
+	block, err := r.readBlock()
+	if err != nil {
+		r.err = err
+	}
+	return block, err
+}
+
+func (r *Reader) readBlock() (*proto.Block, error) {
+	if _, err := r.source.Peek(1); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	timezone := r.Timezone
+	if timezone == nil {
+		timezone = time.UTC
+	}
+	block := &proto.Block{Timezone: timezone}
+	if err := block.Decode(r.reader, 0); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return block, nil
+}
+
+// Blocks yields the blocks of the file, iteration stops after the first error.
+func (r *Reader) Blocks() iter.Seq2[*proto.Block, error] {
+	return func(yield func(*proto.Block, error) bool) {
+		for {
+			block, err := r.ReadBlock()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if !yield(block, err) || err != nil {
+				return
+			}
+		}
+	}
+}
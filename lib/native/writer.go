@@ -0,0 +1,70 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package native
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ClickHouse/ch-go/compress"
+	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+)
+
+// Writer writes blocks to a Native file.
+type Writer struct {
+	writer     io.Writer
+	buffer     chproto.Buffer
+	compressor *compress.Writer
+}
+
+// NewWriter returns a writer of blocks to w, compressed with compression.
+func NewWriter(w io.Writer, compression Compression) (*Writer, error) {
+	writer := &Writer{writer: w}
+	switch compression {
+	case CompressionNone:
+	case CompressionLZ4, CompressionLZ4HC, CompressionZSTD:
+		writer.compressor = compress.NewWriter(compress.LevelZero, compress.Method(compression))
+	default:
+		return nil, fmt.Errorf("native: unsupported compression %d", compression)
+	}
+	return writer, nil
+}
+
+// WriteBlock writes block, it is written to the underlying writer before WriteBlock returns.
+func (w *Writer) WriteBlock(block *proto.Block) error {
+	defer w.buffer.Reset()
+	if err := block.Encode(&w.buffer, 0); err != nil {
+		return err
+	}
+	if w.compressor == nil {
+		_, err := w.writer.Write(w.buffer.Buf)
+		return err
+	}
+	for data := w.buffer.Buf; len(data) > 0; {
+		n := min(len(data), frameSize)
+		if err := w.compressor.Compress(data[:n]); err != nil {
+			return fmt.Errorf("native: compress: %w", err)
+		}
+		if _, err := w.writer.Write(w.compressor.Data); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
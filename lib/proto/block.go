@@ -149,6 +149,10 @@ func (b *Block) EncodeColumn(buffer *proto.Buffer, revision uint64, i int) (err
 			buffer.PutBool(false)
 		}
 
+		// as by the server, columns of empty blocks have no data, not even the state prefix
+		if c.Rows() == 0 {
+			return nil
+		}
 		if serialize, ok := c.(column.CustomSerialization); ok {
 			if err := serialize.WriteStatePrefix(buffer); err != nil {
 				return &BlockError{
@@ -219,12 +223,22 @@ func (b *Block) Decode(reader *proto.Reader, revision uint64) (err error) {
 			return err
 		}
 
+		var kinds *serializationKinds
 		if revision >= DBMS_MIN_REVISION_WITH_CUSTOM_SERIALIZATION {
 			hasCustom, err := reader.Bool()
 			if err != nil {
 				return err
 			}
-			if hasCustom {
+			switch {
+			case hasCustom && revision >= DBMS_MIN_REVISION_WITH_SPARSE_SERIALIZATION:
+				if kinds, err = readSerializationKinds(reader, columnType); err != nil {
+					return &BlockError{
+						Op:         "Decode",
+						Err:        err,
+						ColumnName: columnName,
+					}
+				}
+			case hasCustom:
 				// Allow Time and Time64 columns with custom serialization
 				if columnType != "Time" && columnType != "Time64" {
 					return &BlockError{
@@ -235,7 +249,17 @@ func (b *Block) Decode(reader *proto.Reader, revision uint64) (err error) {
 			}
 		}
 
-		if numRows != 0 {
+		switch {
+		case numRows != 0 && kinds.sparse():
+			// sparse columns are read into a column with all the rows
+			if c, err = readSparseColumn(reader, columnName, column.Type(columnType), b.Timezone, kinds, int(numRows)); err != nil {
+				return &BlockError{
+					Op:         "Decode",
+					Err:        err,
+					ColumnName: columnName,
+				}
+			}
+		case numRows != 0:
 			if serialize, ok := c.(column.CustomSerialization); ok {
 				if err := serialize.ReadStatePrefix(reader); err != nil {
 					return &BlockError{
@@ -253,6 +277,7 @@ func (b *Block) Decode(reader *proto.Reader, revision uint64) (err error) {
 				}
 			}
 		}
+
 		b.names[i] = columnName
 		b.Columns[i] = c
 	}
@@ -307,3 +332,7 @@ func (e *BlockError) Error() string {
 	}
 	return fmt.Sprintf("clickhouse [%s]: %s %s", e.Op, e.ColumnName, e.Err)
 }
+
+func (e *BlockError) Unwrap() error {
+	return e.Err
+}
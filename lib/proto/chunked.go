@@ -0,0 +1,124 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package proto
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Framings of the packets of a connection, announced for each direction in the hello and the
+// addendum since DBMS_MIN_PROTOCOL_VERSION_WITH_CHUNKED_PACKETS. Chunked packets are sent in
+// chunks of a UInt32 size and data, a packet ends with an empty chunk. An optional framing gives
+// way to the framing of the other side.
+const (
+	FramingChunked            = "chunked"
+	FramingNotChunked         = "notchunked"
+	FramingChunkedOptional    = "chunked_optional"
+	FramingNotChunkedOptional = "notchunked_optional"
+)
+
+func framingOrDefault(framing string) string {
+	if framing == "" {
+		return FramingNotChunkedOptional
+	}
+	return framing
+}
+
+// NegotiateChunked reports whether the packets of a direction are chunked, from the framings of the
+// server and the client. The client's framing is used when both are optional.
+func NegotiateChunked(server, client string) (bool, error) {
+	server, client = framingOrDefault(server), framingOrDefault(client)
+	var (
+		serverChunked = strings.HasPrefix(server, FramingChunked)
+		clientChunked = strings.HasPrefix(client, FramingChunked)
+	)
+	switch {
+	case strings.HasSuffix(server, "_optional"):
+		return clientChunked, nil
+	case strings.HasSuffix(client, "_optional"):
+		return serverChunked, nil
+	case serverChunked != clientChunked:
+		return false, fmt.Errorf("incompatible protocol: framing %q, server requires %q", client, server)
+	}
+	return serverChunked, nil
+}
+
+// FramingOf returns the framing announced in the addendum for a negotiated direction.
+func FramingOf(chunked bool) string {
+	if chunked {
+		return FramingChunked
+	}
+	return FramingNotChunked
+}
+
+// AppendChunks appends data to dst as chunks, ends are the offsets in data at which packets end and
+// an empty chunk follows. Data after the last end starts a packet continued by the next chunks.
+func AppendChunks(dst, data []byte, ends []int) []byte {
+	start := 0
+	for _, end := range ends {
+		dst = appendChunk(dst, data[start:end])
+		dst = binary.LittleEndian.AppendUint32(dst, 0)
+		start = end
+	}
+	return appendChunk(dst, data[start:])
+}
+
+func appendChunk(dst, data []byte) []byte {
+	if len(data) == 0 {
+		return dst
+	}
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(len(data)))
+	return append(dst, data...)
+}
+
+// ChunkedReader reads the packets of a chunked connection as a single stream.
+type ChunkedReader struct {
+	reader io.Reader
+	left   uint32
+	header [4]byte
+}
+
+// NewChunkedReader returns a reader of the chunks read from r.
+func NewChunkedReader(r io.Reader) *ChunkedReader {
+	return &ChunkedReader{reader: r}
+}
+
+func (r *ChunkedReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	// empty chunks only end packets
+	for r.left == 0 {
+		if _, err := io.ReadFull(r.reader, r.header[:]); err != nil {
+			return 0, err
+		}
+		r.left = binary.LittleEndian.Uint32(r.header[:])
+	}
+	if uint32(len(p)) > r.left {
+		p = p[:r.left]
+	}
+	n, err := r.reader.Read(p)
+	r.left -= uint32(n)
+	if err == io.EOF && r.left != 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
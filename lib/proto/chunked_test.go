@@ -0,0 +1,104 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package proto
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateChunked(t *testing.T) {
+	for _, c := range []struct {
+		server, client string
+		chunked        bool
+		err            bool
+	}{
+		{server: "", client: "", chunked: false},
+		{server: FramingNotChunkedOptional, client: FramingChunkedOptional, chunked: true},
+		{server: FramingChunkedOptional, client: FramingNotChunkedOptional, chunked: false},
+		{server: FramingChunked, client: FramingNotChunkedOptional, chunked: true},
+		{server: FramingNotChunked, client: FramingChunkedOptional, chunked: false},
+		{server: FramingChunkedOptional, client: FramingNotChunked, chunked: false},
+		{server: FramingChunked, client: FramingChunked, chunked: true},
+		{server: FramingChunked, client: FramingNotChunked, err: true},
+		{server: FramingNotChunked, client: FramingChunked, err: true},
+	} {
+		chunked, err := NegotiateChunked(c.server, c.client)
+		if c.err {
+			assert.Error(t, err, "%s/%s", c.server, c.client)
+			continue
+		}
+		require.NoError(t, err, "%s/%s", c.server, c.client)
+		assert.Equal(t, c.chunked, chunked, "%s/%s", c.server, c.client)
+	}
+}
+
+func TestChunks(t *testing.T) {
+	data := []byte("firstsecondthird")
+	chunks := AppendChunks(nil, data, []int{5, 11})
+	assert.Equal(t, []byte{
+		5, 0, 0, 0, 'f', 'i', 'r', 's', 't', 0, 0, 0, 0,
+		6, 0, 0, 0, 's', 'e', 'c', 'o', 'n', 'd', 0, 0, 0, 0,
+		5, 0, 0, 0, 't', 'h', 'i', 'r', 'd',
+	}, chunks)
+	// the third packet ends in the next write
+	chunks = AppendChunks(chunks, []byte("!"), []int{1})
+
+	read, err := io.ReadAll(NewChunkedReader(bytes.NewReader(chunks)))
+	require.NoError(t, err)
+	assert.Equal(t, "firstsecondthird!", string(read))
+
+	_, err = io.ReadAll(NewChunkedReader(bytes.NewReader(chunks[:7])))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestServerHandshakeRevisions(t *testing.T) {
+	server := ServerHandshake{
+		Name:                            "ClickHouse",
+		DisplayName:                     "host",
+		Revision:                        DBMS_TCP_PROTOCOL_VERSION,
+		Version:                         Version{Major: 25, Minor: 3, Patch: 2},
+		Timezone:                        time.UTC,
+		ParallelReplicasProtocolVersion: DBMS_PARALLEL_REPLICAS_PROTOCOL_VERSION,
+		ChunkedSend:                     FramingChunkedOptional,
+		ChunkedRecv:                     FramingChunked,
+		PasswordComplexityRules:         []PasswordComplexityRule{{Pattern: ".{12}", Message: "be at least 12 characters long"}},
+		Nonce:                           42,
+	}
+	for _, revision := range []uint64{DBMS_TCP_PROTOCOL_VERSION, DBMS_MIN_PROTOCOL_VERSION_WITH_CHUNKED_PACKETS, DBMS_MIN_PROTOCOL_VERSION_WITH_ADDENDUM} {
+		var buffer proto.Buffer
+		server.Encode(&buffer, revision)
+		var decoded ServerHandshake
+		require.NoError(t, decoded.Decode(proto.NewReader(bytes.NewReader(buffer.Buf)), revision))
+		expected := server
+		if revision < DBMS_MIN_REVISION_WITH_VERSIONED_PARALLEL_REPLICAS_PROTOCOL {
+			expected.ParallelReplicasProtocolVersion = 0
+		}
+		if revision < DBMS_MIN_PROTOCOL_VERSION_WITH_CHUNKED_PACKETS {
+			expected.ChunkedSend, expected.ChunkedRecv = "", ""
+			expected.PasswordComplexityRules, expected.Nonce = nil, 0
+		}
+		assert.Equal(t, expected, decoded, "revision %d", revision)
+	}
+}
@@ -37,15 +37,37 @@ const (
 	DBMS_MIN_PROTOCOL_VERSION_WITH_QUOTA_KEY                    = 54458
 	DBMS_MIN_PROTOCOL_VERSION_WITH_PARAMETERS                   = 54459
 	DBMS_MIN_PROTOCOL_VERSION_WITH_SERVER_QUERY_TIME_IN_PROGRES = 54460
-	DBMS_TCP_PROTOCOL_VERSION                                   = DBMS_MIN_PROTOCOL_VERSION_WITH_SERVER_QUERY_TIME_IN_PROGRES
+	DBMS_MIN_PROTOCOL_VERSION_WITH_PASSWORD_COMPLEXITY_RULES    = 54461
+	DBMS_MIN_REVISION_WITH_INTERSERVER_SECRET_V2                = 54462
+	DBMS_MIN_PROTOCOL_VERSION_WITH_TOTAL_BYTES_IN_PROGRESS      = 54463
+	DBMS_MIN_PROTOCOL_VERSION_WITH_TIMEZONE_UPDATES             = 54464
+	DBMS_MIN_REVISION_WITH_SPARSE_SERIALIZATION                 = 54465
+	DBMS_MIN_REVISION_WITH_SSH_AUTHENTICATION                   = 54466
+	DBMS_MIN_REVISION_WITH_TABLE_READ_ONLY_CHECK                = 54467
+	DBMS_MIN_REVISION_WITH_SYSTEM_KEYWORDS_TABLE                = 54468
+	DBMS_MIN_REVISION_WITH_ROWS_BEFORE_AGGREGATION              = 54469
+	DBMS_MIN_PROTOCOL_VERSION_WITH_CHUNKED_PACKETS              = 54470
+	DBMS_MIN_REVISION_WITH_VERSIONED_PARALLEL_REPLICAS_PROTOCOL = 54471
+	DBMS_TCP_PROTOCOL_VERSION                                   = DBMS_MIN_REVISION_WITH_VERSIONED_PARALLEL_REPLICAS_PROTOCOL
 )
 
 const (
-	ClientHello  = 0
-	ClientQuery  = 1
-	ClientData   = 2
-	ClientCancel = 3
-	ClientPing   = 4
+	// DBMS_PARALLEL_REPLICAS_PROTOCOL_VERSION is exchanged in the hello and the addendum
+	DBMS_PARALLEL_REPLICAS_PROTOCOL_VERSION = 4
+	// DBMS_CLUSTER_PROCESSING_PROTOCOL_VERSION is the version of ClientReadTaskResponse
+	DBMS_CLUSTER_PROCESSING_PROTOCOL_VERSION = 1
+)
+
+const (
+	ClientHello            = 0
+	ClientQuery            = 1
+	ClientData             = 2
+	ClientCancel           = 3
+	ClientPing             = 4
+	ClientReadTaskResponse = 9
+
+	ClientSSHChallengeRequest  = 11
+	ClientSSHChallengeResponse = 12
 )
 
 const (
@@ -64,20 +86,26 @@ const (
 )
 
 const (
-	ServerHello               = 0
-	ServerData                = 1
-	ServerException           = 2
-	ServerProgress            = 3
-	ServerPong                = 4
-	ServerEndOfStream         = 5
-	ServerProfileInfo         = 6
-	ServerTotals              = 7
-	ServerExtremes            = 8
-	ServerTablesStatus        = 9
-	ServerLog                 = 10
-	ServerTableColumns        = 11
-	ServerPartUUIDs           = 12
-	ServerReadTaskRequest     = 13
-	ServerProfileEvents       = 14
-	ServerTreeReadTaskRequest = 15
+	ServerHello           = 0
+	ServerData            = 1
+	ServerException       = 2
+	ServerProgress        = 3
+	ServerPong            = 4
+	ServerEndOfStream     = 5
+	ServerProfileInfo     = 6
+	ServerTotals          = 7
+	ServerExtremes        = 8
+	ServerTablesStatus    = 9
+	ServerLog             = 10
+	ServerTableColumns    = 11
+	ServerPartUUIDs       = 12
+	ServerReadTaskRequest = 13
+	ServerProfileEvents   = 14
+	// Deprecated: 15 is ServerMergeTreeAllRangesAnnouncement in current servers, read task
+	// requests are ServerMergeTreeReadTaskRequest.
+	ServerTreeReadTaskRequest            = 15
+	ServerMergeTreeAllRangesAnnouncement = 15
+	ServerMergeTreeReadTaskRequest       = 16
+	ServerTimezoneUpdate                 = 17
+	ServerSSHChallenge                   = 18
 )
@@ -60,6 +60,18 @@ func (e *Exception) Decode(reader *proto.Reader) (err error) {
 	return nil
 }
 
+// Encode writes the exception and its nested exceptions as read by Decode.
+func (e *Exception) Encode(buffer *proto.Buffer) {
+	exceptions := append([]Exception{*e}, e.Nested...)
+	for i, ex := range exceptions {
+		buffer.PutInt32(ex.Code)
+		buffer.PutString(ex.Name)
+		buffer.PutString(ex.Message)
+		buffer.PutString(ex.StackTrace)
+		buffer.PutBool(i < len(exceptions)-1)
+	}
+}
+
 func (e *Exception) decode(reader *proto.Reader) (err error) {
 	if e.Code, err = reader.Int32(); err != nil {
 		return err
@@ -42,6 +42,32 @@ func (h ClientHandshake) Encode(buffer *chproto.Buffer) {
 	buffer.PutUVarInt(h.ProtocolVersion)
 }
 
+// Decode reads the handshake written by Encode, the server side of a ClientHello packet.
+func (h *ClientHandshake) Decode(reader *chproto.Reader) (err error) {
+	if h.ClientName, err = reader.Str(); err != nil {
+		return fmt.Errorf("could not read client name: %v", err)
+	}
+	if h.ClientVersion.Major, err = reader.UVarInt(); err != nil {
+		return fmt.Errorf("could not read client major version: %v", err)
+	}
+	if h.ClientVersion.Minor, err = reader.UVarInt(); err != nil {
+		return fmt.Errorf("could not read client minor version: %v", err)
+	}
+	if h.ProtocolVersion, err = reader.UVarInt(); err != nil {
+		return fmt.Errorf("could not read client protocol version: %v", err)
+	}
+	return nil
+}
+
+// SSHKeyAuthMarker prefixes the username of clients authenticated with an SSH key, they send an
+// empty password and answer the ServerSSHChallenge of the server before its hello.
+const SSHKeyAuthMarker = " SSH KEY AUTHENTICATION "
+
+// SSHAuthMessage returns the message signed by a client to answer the SSH challenge of the server.
+func SSHAuthMessage(revision uint64, database, username, challenge string) []byte {
+	return []byte(strconv.FormatUint(revision, 10) + database + username + challenge)
+}
+
 func (h ClientHandshake) String() string {
 	return fmt.Sprintf("%s %d.%d.%d", h.ClientName, h.ClientVersion.Major, h.ClientVersion.Minor, h.ClientVersion.Patch)
 }
@@ -52,6 +78,22 @@ type ServerHandshake struct {
 	Revision    uint64
 	Version     Version
 	Timezone    *time.Location
+	// ParallelReplicasProtocolVersion is the version of the parallel replicas protocol of the server.
+	ParallelReplicasProtocolVersion uint64
+	// ChunkedSend and ChunkedRecv are the framings of the packets the server sends and receives,
+	// see the Framing constants.
+	ChunkedSend string
+	ChunkedRecv string
+	// PasswordComplexityRules are the rules passwords of new users must follow.
+	PasswordComplexityRules []PasswordComplexityRule
+	// Nonce is a random value of the server used to sign interserver queries.
+	Nonce uint64
+}
+
+// PasswordComplexityRule is a rule of the password_complexity server setting.
+type PasswordComplexityRule struct {
+	Pattern string
+	Message string
 }
 
 type Version struct {
@@ -85,7 +127,9 @@ func CheckMinVersion(constraint Version, version Version) bool {
 	return true
 }
 
-func (srv *ServerHandshake) Decode(reader *chproto.Reader) (err error) {
+// Decode reads a ServerHello packet sent to a client of revision, the fields sent depend on the
+// lower of the revisions of the client and the server.
+func (srv *ServerHandshake) Decode(reader *chproto.Reader, revision uint64) (err error) {
 	if srv.Name, err = reader.Str(); err != nil {
 		return fmt.Errorf("could not read server name: %v", err)
 	}
@@ -98,7 +142,13 @@ func (srv *ServerHandshake) Decode(reader *chproto.Reader) (err error) {
 	if srv.Revision, err = reader.UVarInt(); err != nil {
 		return fmt.Errorf("could not read server revision: %v", err)
 	}
-	if srv.Revision >= DBMS_MIN_REVISION_WITH_SERVER_TIMEZONE {
+	revision = min(revision, srv.Revision)
+	if revision >= DBMS_MIN_REVISION_WITH_VERSIONED_PARALLEL_REPLICAS_PROTOCOL {
+		if srv.ParallelReplicasProtocolVersion, err = reader.UVarInt(); err != nil {
+			return fmt.Errorf("could not read server parallel replicas protocol version: %v", err)
+		}
+	}
+	if revision >= DBMS_MIN_REVISION_WITH_SERVER_TIMEZONE {
 		name, err := reader.Str()
 		if err != nil {
 			return fmt.Errorf("could not read server timezone: %v", err)
@@ -107,21 +157,89 @@ func (srv *ServerHandshake) Decode(reader *chproto.Reader) (err error) {
 			return fmt.Errorf("could not load time location: %v", err)
 		}
 	}
-	if srv.Revision >= DBMS_MIN_REVISION_WITH_SERVER_DISPLAY_NAME {
+	if revision >= DBMS_MIN_REVISION_WITH_SERVER_DISPLAY_NAME {
 		if srv.DisplayName, err = reader.Str(); err != nil {
 			return fmt.Errorf("could not read server display name: %v", err)
 		}
 	}
-	if srv.Revision >= DBMS_MIN_REVISION_WITH_VERSION_PATCH {
+	if revision >= DBMS_MIN_REVISION_WITH_VERSION_PATCH {
 		if srv.Version.Patch, err = reader.UVarInt(); err != nil {
 			return fmt.Errorf("could not read server patch: %v", err)
 		}
 	} else {
 		srv.Version.Patch = srv.Revision
 	}
+	if revision >= DBMS_MIN_PROTOCOL_VERSION_WITH_CHUNKED_PACKETS {
+		if srv.ChunkedSend, err = reader.Str(); err != nil {
+			return fmt.Errorf("could not read server chunked send: %v", err)
+		}
+		if srv.ChunkedRecv, err = reader.Str(); err != nil {
+			return fmt.Errorf("could not read server chunked recv: %v", err)
+		}
+	}
+	if revision >= DBMS_MIN_PROTOCOL_VERSION_WITH_PASSWORD_COMPLEXITY_RULES {
+		n, err := reader.UVarInt()
+		if err != nil {
+			return fmt.Errorf("could not read server password complexity rules: %v", err)
+		}
+		srv.PasswordComplexityRules = make([]PasswordComplexityRule, n)
+		for i := range srv.PasswordComplexityRules {
+			rule := &srv.PasswordComplexityRules[i]
+			if rule.Pattern, err = reader.Str(); err != nil {
+				return fmt.Errorf("could not read server password complexity rules: %v", err)
+			}
+			if rule.Message, err = reader.Str(); err != nil {
+				return fmt.Errorf("could not read server password complexity rules: %v", err)
+			}
+		}
+	}
+	if revision >= DBMS_MIN_REVISION_WITH_INTERSERVER_SECRET_V2 {
+		if srv.Nonce, err = reader.UInt64(); err != nil {
+			return fmt.Errorf("could not read server nonce: %v", err)
+		}
+	}
 	return nil
 }
 
+// Encode writes the handshake read by Decode, the server side of a ServerHello packet to a client of revision.
+func (srv *ServerHandshake) Encode(buffer *chproto.Buffer, revision uint64) {
+	buffer.PutString(srv.Name)
+	buffer.PutUVarInt(srv.Version.Major)
+	buffer.PutUVarInt(srv.Version.Minor)
+	buffer.PutUVarInt(srv.Revision)
+	revision = min(revision, srv.Revision)
+	if revision >= DBMS_MIN_REVISION_WITH_VERSIONED_PARALLEL_REPLICAS_PROTOCOL {
+		buffer.PutUVarInt(srv.ParallelReplicasProtocolVersion)
+	}
+	if revision >= DBMS_MIN_REVISION_WITH_SERVER_TIMEZONE {
+		tz := "UTC"
+		if srv.Timezone != nil {
+			tz = srv.Timezone.String()
+		}
+		buffer.PutString(tz)
+	}
+	if revision >= DBMS_MIN_REVISION_WITH_SERVER_DISPLAY_NAME {
+		buffer.PutString(srv.DisplayName)
+	}
+	if revision >= DBMS_MIN_REVISION_WITH_VERSION_PATCH {
+		buffer.PutUVarInt(srv.Version.Patch)
+	}
+	if revision >= DBMS_MIN_PROTOCOL_VERSION_WITH_CHUNKED_PACKETS {
+		buffer.PutString(framingOrDefault(srv.ChunkedSend))
+		buffer.PutString(framingOrDefault(srv.ChunkedRecv))
+	}
+	if revision >= DBMS_MIN_PROTOCOL_VERSION_WITH_PASSWORD_COMPLEXITY_RULES {
+		buffer.PutUVarInt(uint64(len(srv.PasswordComplexityRules)))
+		for _, rule := range srv.PasswordComplexityRules {
+			buffer.PutString(rule.Pattern)
+			buffer.PutString(rule.Message)
+		}
+	}
+	if revision >= DBMS_MIN_REVISION_WITH_INTERSERVER_SECRET_V2 {
+		buffer.PutUInt64(srv.Nonce)
+	}
+}
+
 func (srv ServerHandshake) String() string {
 	return fmt.Sprintf("%s (%s) server version %d.%d.%d revision %d (timezone %s)", srv.Name, srv.DisplayName,
 		srv.Version.Major,
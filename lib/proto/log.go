@@ -0,0 +1,64 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package proto
+
+import (
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+)
+
+// Log is a server log entry, sent in ServerLog packets when send_logs_level is set.
+type Log struct {
+	Time      time.Time
+	TimeMicro uint32
+	Hostname  string
+	QueryID   string
+	ThreadID  uint64
+	Priority  int8
+	Source    string
+	Text      string
+}
+
+// NewLogBlock returns the block of a ServerLog packet with logs.
+func NewLogBlock(logs []Log) (*Block, error) {
+	block := &Block{}
+	for _, c := range []struct {
+		name string
+		t    column.Type
+	}{
+		{"event_time", "DateTime"},
+		{"event_time_microseconds", "UInt32"},
+		{"host_name", "String"},
+		{"query_id", "String"},
+		{"thread_id", "UInt64"},
+		{"priority", "Int8"},
+		{"source", "String"},
+		{"text", "String"},
+	} {
+		if err := block.AddColumn(c.name, c.t); err != nil {
+			return nil, err
+		}
+	}
+	for _, l := range logs {
+		if err := block.Append(l.Time, l.TimeMicro, l.Hostname, l.QueryID, l.ThreadID, l.Priority, l.Source, l.Text); err != nil {
+			return nil, err
+		}
+	}
+	return block, nil
+}
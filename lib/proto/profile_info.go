@@ -29,6 +29,8 @@ type ProfileInfo struct {
 	AppliedLimit              bool
 	RowsBeforeLimit           uint64
 	CalculatedRowsBeforeLimit bool
+	AppliedAggregation        bool
+	RowsBeforeAggregation     uint64
 }
 
 func (p *ProfileInfo) Decode(reader *chproto.Reader, revision uint64) (err error) {
@@ -50,9 +52,31 @@ func (p *ProfileInfo) Decode(reader *chproto.Reader, revision uint64) (err error
 	if p.CalculatedRowsBeforeLimit, err = reader.Bool(); err != nil {
 		return err
 	}
+	if revision >= DBMS_MIN_REVISION_WITH_ROWS_BEFORE_AGGREGATION {
+		if p.AppliedAggregation, err = reader.Bool(); err != nil {
+			return err
+		}
+		if p.RowsBeforeAggregation, err = reader.UVarInt(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// Encode writes the profile info read by Decode, the server side of a ServerProfileInfo packet.
+func (p *ProfileInfo) Encode(buffer *chproto.Buffer, revision uint64) {
+	buffer.PutUVarInt(p.Rows)
+	buffer.PutUVarInt(p.Blocks)
+	buffer.PutUVarInt(p.Bytes)
+	buffer.PutBool(p.AppliedLimit)
+	buffer.PutUVarInt(p.RowsBeforeLimit)
+	buffer.PutBool(p.CalculatedRowsBeforeLimit)
+	if revision >= DBMS_MIN_REVISION_WITH_ROWS_BEFORE_AGGREGATION {
+		buffer.PutBool(p.AppliedAggregation)
+		buffer.PutUVarInt(p.RowsBeforeAggregation)
+	}
+}
+
 func (p *ProfileInfo) String() string {
 	return fmt.Sprintf("rows=%d, bytes=%d, blocks=%d, rows before limit=%d, applied limit=%t, calculated rows before limit=%t",
 		p.Rows,
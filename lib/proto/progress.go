@@ -28,6 +28,7 @@ type Progress struct {
 	Rows       uint64
 	Bytes      uint64
 	TotalRows  uint64
+	TotalBytes uint64
 	WroteRows  uint64
 	WroteBytes uint64
 	Elapsed    time.Duration
@@ -44,6 +45,11 @@ func (p *Progress) Decode(reader *chproto.Reader, revision uint64) (err error) {
 	if p.TotalRows, err = reader.UVarInt(); err != nil {
 		return err
 	}
+	if revision >= DBMS_MIN_PROTOCOL_VERSION_WITH_TOTAL_BYTES_IN_PROGRESS {
+		if p.TotalBytes, err = reader.UVarInt(); err != nil {
+			return err
+		}
+	}
 	if revision >= DBMS_MIN_REVISION_WITH_CLIENT_WRITE_INFO {
 		p.withClient = true
 		if p.WroteRows, err = reader.UVarInt(); err != nil {
@@ -65,6 +71,23 @@ func (p *Progress) Decode(reader *chproto.Reader, revision uint64) (err error) {
 	return nil
 }
 
+// Encode writes the progress read by Decode, the server side of a ServerProgress packet.
+func (p *Progress) Encode(buffer *chproto.Buffer, revision uint64) {
+	buffer.PutUVarInt(p.Rows)
+	buffer.PutUVarInt(p.Bytes)
+	buffer.PutUVarInt(p.TotalRows)
+	if revision >= DBMS_MIN_PROTOCOL_VERSION_WITH_TOTAL_BYTES_IN_PROGRESS {
+		buffer.PutUVarInt(p.TotalBytes)
+	}
+	if revision >= DBMS_MIN_REVISION_WITH_CLIENT_WRITE_INFO {
+		buffer.PutUVarInt(p.WroteRows)
+		buffer.PutUVarInt(p.WroteBytes)
+	}
+	if revision >= DBMS_MIN_PROTOCOL_VERSION_WITH_SERVER_QUERY_TIME_IN_PROGRES {
+		buffer.PutUVarInt(uint64(p.Elapsed.Nanoseconds()))
+	}
+}
+
 func (p *Progress) String() string {
 	if !p.withClient {
 		return fmt.Sprintf("rows=%d, bytes=%d, total rows=%d, elapsed=%s", p.Rows, p.Bytes, p.TotalRows, p.Elapsed.String())
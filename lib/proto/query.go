@@ -77,6 +77,142 @@ func (q *Query) Encode(buffer *chproto.Buffer, revision uint64) error {
 	return nil
 }
 
+// Decode reads a query written by Encode, the server side of a ClientQuery packet.
+func (q *Query) Decode(reader *chproto.Reader, revision uint64) (err error) {
+	if q.ID, err = reader.Str(); err != nil {
+		return err
+	}
+	if err := q.decodeClientInfo(reader, revision); err != nil {
+		return fmt.Errorf("could not read client info: %w", err)
+	}
+	if q.Settings, err = decodeSettings(reader, revision); err != nil {
+		return fmt.Errorf("could not read settings: %w", err)
+	}
+	if revision >= DBMS_MIN_REVISION_WITH_INTERSERVER_SECRET {
+		if _, err := reader.Str(); err != nil {
+			return err
+		}
+	}
+	if _, err := reader.UVarInt(); err != nil { // stage
+		return err
+	}
+	if q.Compression, err = reader.Bool(); err != nil {
+		return err
+	}
+	if q.Body, err = reader.Str(); err != nil {
+		return err
+	}
+	if revision >= DBMS_MIN_PROTOCOL_VERSION_WITH_PARAMETERS {
+		settings, err := decodeSettings(reader, revision)
+		if err != nil {
+			return fmt.Errorf("could not read parameters: %w", err)
+		}
+		for _, s := range settings {
+			value, _ := s.Value.(string)
+			q.Parameters = append(q.Parameters, Parameter{
+				Key:   s.Key,
+				Value: decodeFieldDump(value),
+			})
+		}
+	}
+	return nil
+}
+
+func (q *Query) decodeClientInfo(reader *chproto.Reader, revision uint64) (err error) {
+	kind, err := reader.ReadByte()
+	if err != nil || kind == ClientQueryNone {
+		return err
+	}
+	if q.InitialUser, err = reader.Str(); err != nil {
+		return err
+	}
+	if _, err = reader.Str(); err != nil { // initial_query_id
+		return err
+	}
+	if q.InitialAddress, err = reader.Str(); err != nil {
+		return err
+	}
+	if revision >= DBMS_MIN_PROTOCOL_VERSION_WITH_INITIAL_QUERY_START_TIME {
+		if _, err = reader.Int64(); err != nil {
+			return err
+		}
+	}
+	if _, err = reader.ReadByte(); err != nil { // interface
+		return err
+	}
+	if _, err = reader.Str(); err != nil { // os_user
+		return err
+	}
+	if _, err = reader.Str(); err != nil { // client_hostname
+		return err
+	}
+	if q.ClientName, err = reader.Str(); err != nil {
+		return err
+	}
+	if q.ClientVersion.Major, err = reader.UVarInt(); err != nil {
+		return err
+	}
+	if q.ClientVersion.Minor, err = reader.UVarInt(); err != nil {
+		return err
+	}
+	if q.ClientTCPProtocolVersion, err = reader.UVarInt(); err != nil {
+		return err
+	}
+	if revision >= DBMS_MIN_REVISION_WITH_QUOTA_KEY_IN_CLIENT_INFO {
+		if q.QuotaKey, err = reader.Str(); err != nil {
+			return err
+		}
+	}
+	if revision >= DBMS_MIN_PROTOCOL_VERSION_WITH_DISTRIBUTED_DEPTH {
+		if _, err = reader.UVarInt(); err != nil {
+			return err
+		}
+	}
+	if revision >= DBMS_MIN_REVISION_WITH_VERSION_PATCH {
+		if q.ClientVersion.Patch, err = reader.UVarInt(); err != nil {
+			return err
+		}
+	}
+	if revision >= DBMS_MIN_REVISION_WITH_OPENTELEMETRY {
+		hasTrace, err := reader.Bool()
+		if err != nil {
+			return err
+		}
+		if hasTrace {
+			var config trace.SpanContextConfig
+			if err := reader.ReadFull(config.TraceID[:]); err != nil {
+				return err
+			}
+			swap64(config.TraceID[:])
+			if err := reader.ReadFull(config.SpanID[:]); err != nil {
+				return err
+			}
+			swap64(config.SpanID[:])
+			state, err := reader.Str()
+			if err != nil {
+				return err
+			}
+			if config.TraceState, err = trace.ParseTraceState(state); err != nil {
+				return err
+			}
+			flags, err := reader.ReadByte()
+			if err != nil {
+				return err
+			}
+			config.TraceFlags = trace.TraceFlags(flags)
+			q.Span = trace.NewSpanContext(config)
+		}
+	}
+	if revision >= DBMS_MIN_REVISION_WITH_PARALLEL_REPLICAS {
+		for i := 0; i < 3; i++ { // collaborate_with_initiator, count_participating_replicas, number_of_current_replica
+			if _, err = reader.UVarInt(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func swap64(b []byte) {
 	for i := 0; i < len(b); i += 8 {
 		u := stdbin.BigEndian.Uint64(b[i:])
@@ -205,6 +341,39 @@ func (s *Setting) encode(buffer *chproto.Buffer, revision uint64) error {
 	return nil
 }
 
+// decodeSettings reads settings up to the empty name marking their end. Values are read as strings,
+// custom settings keep their field dump.
+func decodeSettings(reader *chproto.Reader, revision uint64) (settings Settings, err error) {
+	for {
+		key, err := reader.Str()
+		if err != nil {
+			return nil, err
+		}
+		if key == "" {
+			return settings, nil
+		}
+		setting := Setting{Key: key}
+		if revision <= DBMS_MIN_REVISION_WITH_SETTINGS_SERIALIZED_AS_STRINGS {
+			value, err := reader.UVarInt()
+			if err != nil {
+				return nil, err
+			}
+			setting.Value = fmt.Sprint(value)
+		} else {
+			flags, err := reader.UVarInt()
+			if err != nil {
+				return nil, err
+			}
+			setting.Important = flags&settingFlagImportant != 0
+			setting.Custom = flags&settingFlagCustom != 0
+			if setting.Value, err = reader.Str(); err != nil {
+				return nil, err
+			}
+		}
+		settings = append(settings, setting)
+	}
+}
+
 type Parameters []Parameter
 
 type Parameter struct {
@@ -246,3 +415,11 @@ func encodeFieldDump(value any) (string, error) {
 
 	return "", fmt.Errorf("unsupported field type %T", value)
 }
+
+// decodeFieldDump reverses encodeFieldDump for string fields, other dumps are returned as is
+func decodeFieldDump(dump string) string {
+	if len(dump) < 2 || dump[0] != '\'' || dump[len(dump)-1] != '\'' {
+		return dump
+	}
+	return strings.ReplaceAll(dump[1:len(dump)-1], "\\'", "'")
+}
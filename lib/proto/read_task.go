@@ -0,0 +1,72 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package proto
+
+import (
+	"fmt"
+
+	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/google/uuid"
+)
+
+// PartUUIDs is a ServerPartUUIDs packet, the UUIDs of the data parts read by a query that
+// replicas must not read again.
+type PartUUIDs []uuid.UUID
+
+func (p *PartUUIDs) Decode(reader *chproto.Reader) error {
+	n, err := reader.UVarInt()
+	if err != nil {
+		return err
+	}
+	var uuids chproto.ColUUID
+	if err := uuids.DecodeColumn(reader, int(n)); err != nil {
+		return fmt.Errorf("part uuids: %w", err)
+	}
+	*p = PartUUIDs(uuids)
+	return nil
+}
+
+// Encode writes the UUIDs read by Decode, the server side of a ServerPartUUIDs packet.
+func (p PartUUIDs) Encode(buffer *chproto.Buffer) {
+	buffer.PutUVarInt(uint64(len(p)))
+	chproto.ColUUID(p).EncodeColumn(buffer)
+}
+
+// ReadTaskResponse is a ClientReadTaskResponse packet, the answer to a ServerReadTaskRequest of
+// table functions such as s3Cluster. An empty Task tells the server that there are no more tasks.
+type ReadTaskResponse struct {
+	Task string
+}
+
+func (r *ReadTaskResponse) Encode(buffer *chproto.Buffer) {
+	buffer.PutUVarInt(DBMS_CLUSTER_PROCESSING_PROTOCOL_VERSION)
+	buffer.PutString(r.Task)
+}
+
+// Decode reads the response written by Encode, the server side of a ClientReadTaskResponse packet.
+func (r *ReadTaskResponse) Decode(reader *chproto.Reader) (err error) {
+	version, err := reader.UVarInt()
+	if err != nil {
+		return err
+	}
+	if version != DBMS_CLUSTER_PROCESSING_PROTOCOL_VERSION {
+		return fmt.Errorf("read task response: unsupported protocol version %d", version)
+	}
+	r.Task, err = reader.Str()
+	return err
+}
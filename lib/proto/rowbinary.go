@@ -0,0 +1,170 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package proto
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+)
+
+// RowBinary blocks are decoded and encoded a row at a time by the column types of the block.
+// RowBinary has each value of a row after the other, Native each column after the other.
+// https://clickhouse.com/docs/en/interfaces/formats#rowbinary
+
+// RowBinaryReader reads blocks of rows in RowBinary or RowBinaryWithNamesAndTypes.
+type RowBinaryReader struct {
+	source  *bufio.Reader
+	reader  *proto.Reader
+	tz      *time.Location
+	names   []string
+	types   []column.Type
+	started bool
+	err     error
+}
+
+// NewRowBinaryReader returns a reader of the rows of r, with DateTime columns without a timezone in tz.
+// With a header, the rows are in RowBinary and have the columns of header. Without one they are in
+// RowBinaryWithNamesAndTypes.
+func NewRowBinaryReader(r io.Reader, tz *time.Location, header *Block) *RowBinaryReader {
+	// at least the buffer size of proto.Reader, so that it reads from source rather than
+	// buffering ahead of it, and the end of the rows can be peeked
+	source := bufio.NewReaderSize(r, 128<<10)
+	reader := &RowBinaryReader{
+		source: source,
+		reader: proto.NewReader(source),
+		tz:     tz,
+	}
+	if header != nil {
+		reader.started = true
+		reader.names = header.ColumnsNames()
+		for _, c := range header.Columns {
+			reader.types = append(reader.types, c.Type())
+		}
+	}
+	return reader
+}
+
+// ReadBlock returns a block of at most maxRows rows, or io.EOF after the last row. The first block
+// of RowBinaryWithNamesAndTypes is returned even without rows.
+func (r *RowBinaryReader) ReadBlock(maxRows int) (*Block, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	block, err := r.readBlock(maxRows)
+	if err != nil {
+		// errors are final, a compressed source does not keep them
+		r.err = err
+	}
+	return block, err
+}
+
+func (r *RowBinaryReader) readBlock(maxRows int) (*Block, error) {
+	first := !r.started
+	if first {
+		r.started = true
+		if err := r.readHeader(); err != nil {
+			return nil, err
+		}
+	}
+	block := &Block{Timezone: r.tz}
+	for i, t := range r.types {
+		if err := block.AddColumn(r.names[i], t); err != nil {
+			return nil, err
+		}
+	}
+	rows := 0
+	for ; rows < maxRows; rows++ {
+		if _, err := r.source.Peek(1); err != nil {
+			if errors.Is(err, io.EOF) {
+				// bufio does not keep the error, and a compressed source would read again
+				r.err = io.EOF
+				break
+			}
+			return nil, err
+		}
+		for _, c := range block.Columns {
+			if err := c.DecodeRowBinary(r.reader); err != nil {
+				if errors.Is(err, io.EOF) {
+					err = io.ErrUnexpectedEOF
+				}
+				return nil, &BlockError{Op: "DecodeRowBinary", Err: err, ColumnName: c.Name()}
+			}
+		}
+	}
+	if rows == 0 && !first {
+		return nil, io.EOF
+	}
+	return block, nil
+}
+
+func (r *RowBinaryReader) readHeader() error {
+	n, err := r.reader.UVarInt()
+	if err != nil {
+		return err
+	}
+	r.names, r.types = make([]string, n), make([]column.Type, n)
+	for i := range r.names {
+		if r.names[i], err = r.reader.Str(); err != nil {
+			return err
+		}
+	}
+	for i := range r.types {
+		t, err := r.reader.Str()
+		if err != nil {
+			return err
+		}
+		r.types[i] = column.Type(t)
+	}
+	return nil
+}
+
+// EncodeRowBinary writes the rows of the block in RowBinary, or RowBinaryWithNamesAndTypes if withNamesAndTypes.
+func (b *Block) EncodeRowBinary(buffer *proto.Buffer, withNamesAndTypes bool) error {
+	rows := b.Rows()
+	for _, c := range b.Columns {
+		if c.Rows() != rows {
+			return &BlockError{
+				Op:  "EncodeRowBinary",
+				Err: fmt.Errorf("mismatched len of columns - expected %d, received %d for col %s", rows, c.Rows(), c.Name()),
+			}
+		}
+	}
+	if withNamesAndTypes {
+		buffer.PutUVarInt(uint64(len(b.Columns)))
+		for _, c := range b.Columns {
+			buffer.PutString(c.Name())
+		}
+		for _, c := range b.Columns {
+			buffer.PutString(string(c.Type()))
+		}
+	}
+	for row := 0; row < rows; row++ {
+		for _, c := range b.Columns {
+			if err := c.EncodeRowBinary(buffer, row); err != nil {
+				return &BlockError{Op: "EncodeRowBinary", Err: err, ColumnName: c.Name()}
+			}
+		}
+	}
+	return nil
+}
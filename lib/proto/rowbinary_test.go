@@ -0,0 +1,217 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package proto
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/ch-go/compress"
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chcol"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowBinaryRoundTrip(t *testing.T) {
+	types := []string{
+		"UInt64",
+		"String",
+		"Nullable(String)",
+		"LowCardinality(String)",
+		"LowCardinality(Nullable(String))",
+		"Array(Nullable(Int32))",
+		"Array(LowCardinality(String))",
+		"Map(String, Array(UInt8))",
+		"Tuple(a String, b Nullable(DateTime('UTC')))",
+		"Decimal(18, 4)",
+		"FixedString(3)",
+		"Enum8('a' = 1, 'b, c' = 2)",
+		"DateTime64(3, 'UTC')",
+		"UUID",
+		"Bool",
+		"Int256",
+		"IPv6",
+		"Variant(String, UInt64)",
+		"Dynamic",
+		"JSON(a UInt32)",
+	}
+	block := &Block{Timezone: time.UTC}
+	for i, typ := range types {
+		require.NoError(t, block.AddColumn(string(rune('a'+i)), column.Type(typ)))
+	}
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 0; i < 100; i++ {
+		name := "name"
+		var nullable *string
+		if i%3 == 0 {
+			nullable = &name
+		}
+		v := int32(i)
+		require.NoError(t, block.Append(
+			uint64(i),
+			"row",
+			nullable,
+			[]string{"x", "y", "z"}[i%3],
+			nullable,
+			[]*int32{&v, nil},
+			[]string{"p", "q"},
+			map[string][]uint8{"k": {uint8(i)}},
+			map[string]any{"a": "t", "b": &ts},
+			"12.5",
+			"abc",
+			[]string{"a", "b, c"}[i%2],
+			ts.Add(time.Duration(i)*time.Millisecond),
+			"6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+			i%2 == 0,
+			big.NewInt(int64(-i)),
+			net.ParseIP("2001:db8::1"),
+			[]chcol.Variant{chcol.NewVariantWithType("v", "String"), chcol.NewVariantWithType(uint64(i), "UInt64"), chcol.NewVariant(nil)}[i%3],
+			[]any{nil, int64(i), "d", []int64{1, 2}}[i%4],
+			[]map[string]any{{"a": uint32(i)}, {"a": uint32(i), "b": "x"}, {"c": int64(i)}}[i%3],
+		))
+	}
+
+	for _, withNamesAndTypes := range []bool{true, false} {
+		var buffer proto.Buffer
+		require.NoError(t, block.EncodeRowBinary(&buffer, withNamesAndTypes))
+
+		var header *Block
+		if !withNamesAndTypes {
+			header = block
+		}
+		reader := NewRowBinaryReader(bytes.NewReader(buffer.Buf), time.UTC, header)
+		var rows int
+		for {
+			decoded, err := reader.ReadBlock(30)
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			assert.Equal(t, block.ColumnsNames(), decoded.ColumnsNames())
+			for i := range decoded.Columns {
+				assert.Equal(t, types[i], string(decoded.Columns[i].Type()))
+				for row := 0; row < decoded.Rows(); row++ {
+					assert.Equal(t, block.Columns[i].Row(rows+row, false), decoded.Columns[i].Row(row, false), "%s row %d", types[i], rows+row)
+				}
+			}
+			rows += decoded.Rows()
+		}
+		assert.Equal(t, 100, rows)
+	}
+}
+
+func TestRowBinaryValues(t *testing.T) {
+	block := &Block{}
+	for _, typ := range []string{"Nullable(String)", "Array(UInt8)", "LowCardinality(String)", "Map(String, UInt8)"} {
+		require.NoError(t, block.AddColumn(typ, column.Type(typ)))
+	}
+	require.NoError(t, block.Append(nil, []uint8{1, 2}, "a", map[string]uint8{"a": 1}))
+	var buffer proto.Buffer
+	require.NoError(t, block.EncodeRowBinary(&buffer, false))
+	assert.Equal(t, []byte{
+		0x01,             // null
+		0x02, 0x01, 0x02, // [1, 2]
+		0x01, 'a', // 'a'
+		0x01, 0x01, 'a', 0x01, // {'a': 1}
+	}, buffer.Buf)
+}
+
+func TestRowBinaryEmpty(t *testing.T) {
+	block := &Block{}
+	require.NoError(t, block.AddColumn("id", "UInt64"))
+	var buffer proto.Buffer
+	require.NoError(t, block.EncodeRowBinary(&buffer, true))
+
+	reader := NewRowBinaryReader(bytes.NewReader(buffer.Buf), nil, nil)
+	header, err := reader.ReadBlock(10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id"}, header.ColumnsNames())
+	assert.Equal(t, 0, header.Rows())
+	_, err = reader.ReadBlock(10)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestRowBinaryCompressed(t *testing.T) {
+	block := &Block{}
+	require.NoError(t, block.AddColumn("id", "UInt64"))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, block.Append(uint64(i)))
+	}
+	var buffer proto.Buffer
+	require.NoError(t, block.EncodeRowBinary(&buffer, true))
+	compressor := compress.NewWriter(compress.LevelZero, compress.LZ4)
+	require.NoError(t, compressor.Compress(buffer.Buf))
+
+	// the compressed reader serves its last frame again when read after the end
+	reader := NewRowBinaryReader(compress.NewReader(bytes.NewReader(compressor.Data)), nil, nil)
+	read, err := reader.ReadBlock(10)
+	require.NoError(t, err)
+	assert.Equal(t, 3, read.Rows())
+	_, err = reader.ReadBlock(10)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestRowBinaryTruncated(t *testing.T) {
+	block := &Block{}
+	require.NoError(t, block.AddColumn("s", "String"))
+	require.NoError(t, block.Append("value"))
+	var buffer proto.Buffer
+	require.NoError(t, block.EncodeRowBinary(&buffer, false))
+
+	_, err := NewRowBinaryReader(bytes.NewReader(buffer.Buf[:3]), nil, block).ReadBlock(10)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestRowBinaryDynamic(t *testing.T) {
+	block := &Block{}
+	require.NoError(t, block.AddColumn("d", "Dynamic"))
+	require.NoError(t, block.Append(nil))
+	require.NoError(t, block.Append(int64(1)))
+	require.NoError(t, block.Append(chcol.NewDynamicWithType([]string{"a"}, "Array(String)")))
+	var buffer proto.Buffer
+	require.NoError(t, block.EncodeRowBinary(&buffer, false))
+	assert.Equal(t, []byte{
+		0x00,                                                 // Nothing, null
+		0x0a, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Int64 1
+		0x1e, 0x15, 0x01, 0x01, 'a', // Array(String) ['a']
+	}, buffer.Buf)
+
+	decoded, err := NewRowBinaryReader(bytes.NewReader(buffer.Buf), nil, block).ReadBlock(10)
+	require.NoError(t, err)
+	for row := 0; row < 3; row++ {
+		assert.Equal(t, block.Columns[0].Row(row, false), decoded.Columns[0].Row(row, false))
+	}
+}
+
+func TestRowBinaryObjectJSON(t *testing.T) {
+	block := &Block{}
+	require.NoError(t, block.AddColumn("o", "Object('json')"))
+	require.NoError(t, block.Append(map[string]any{"a": int64(1)}))
+	var buffer proto.Buffer
+	require.NoError(t, block.EncodeRowBinary(&buffer, false))
+	assert.Equal(t, append([]byte{7}, `{"a":1}`...), buffer.Buf)
+
+	_, err := NewRowBinaryReader(bytes.NewReader(buffer.Buf), nil, block).ReadBlock(10)
+	assert.ErrorContains(t, err, "reading Object('json') columns is not supported")
+}
@@ -0,0 +1,240 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package proto
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+)
+
+// Kinds of the serialization of a column, sent after the type when it has a custom serialization.
+// https://github.com/ClickHouse/ClickHouse/blob/master/src/DataTypes/Serializations/ISerialization.h
+const (
+	serializationDefault = 0
+	serializationSparse  = 1
+)
+
+// sparseEndOfGranule flags the last group of the offsets of a sparse column
+const sparseEndOfGranule = 1 << 62
+
+// serializationKinds is the kind of a column and of the elements of a tuple
+type serializationKinds struct {
+	kind     byte
+	elements []*serializationKinds
+}
+
+func readSerializationKinds(reader *proto.Reader, t string) (*serializationKinds, error) {
+	kind, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if kind != serializationDefault && kind != serializationSparse {
+		return nil, fmt.Errorf("unsupported serialization kind %d", kind)
+	}
+	kinds := &serializationKinds{kind: kind}
+	for _, element := range tupleElements(t) {
+		elementKinds, err := readSerializationKinds(reader, element.String())
+		if err != nil {
+			return nil, err
+		}
+		kinds.elements = append(kinds.elements, elementKinds)
+	}
+	return kinds, nil
+}
+
+func (k *serializationKinds) sparse() bool {
+	if k == nil {
+		return false
+	}
+	if k.kind == serializationSparse {
+		return true
+	}
+	for _, element := range k.elements {
+		if element.sparse() {
+			return true
+		}
+	}
+	return false
+}
+
+// tupleElements returns the types of the elements of t if it is a tuple
+func tupleElements(t string) []*chtype.Type {
+	parsed, err := chtype.Parse(t)
+	if err != nil || parsed.Name != "Tuple" {
+		return nil
+	}
+	var elements []*chtype.Type
+	for _, arg := range parsed.TypeArgs() {
+		elements = append(elements, arg.Type)
+	}
+	return elements
+}
+
+// sparseColumn is a column of a block with sparse parts, read with the columns of the elements of
+// tuples as each element has its own serialization kind
+type sparseColumn struct {
+	column   column.Interface
+	kinds    *serializationKinds
+	elements []*sparseColumn
+	tz       *time.Location
+}
+
+func newSparseColumn(name string, t column.Type, tz *time.Location, kinds *serializationKinds) (*sparseColumn, error) {
+	c, err := t.Column(name, tz)
+	if err != nil {
+		return nil, err
+	}
+	sparse := &sparseColumn{column: c, kinds: kinds, tz: tz}
+	if len(kinds.elements) == 0 {
+		return sparse, nil
+	}
+	elements := tupleElements(string(t))
+	if len(elements) != len(kinds.elements) {
+		return nil, fmt.Errorf("%d serialization kinds for %d elements of %s", len(kinds.elements), len(elements), t)
+	}
+	for i, element := range elements {
+		elementColumn, err := newSparseColumn("", column.Type(element.String()), tz, kinds.elements[i])
+		if err != nil {
+			return nil, err
+		}
+		sparse.elements = append(sparse.elements, elementColumn)
+	}
+	return sparse, nil
+}
+
+// readStatePrefix reads the state prefixes of all the streams, which come before the data
+func (s *sparseColumn) readStatePrefix(reader *proto.Reader) error {
+	if len(s.elements) == 0 {
+		if serialize, ok := s.column.(column.CustomSerialization); ok {
+			return serialize.ReadStatePrefix(reader)
+		}
+		return nil
+	}
+	for _, element := range s.elements {
+		if err := element.readStatePrefix(reader); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read reads the rows of the column. The values of sparse columns and the elements of tuples are
+// then added to a column with all the rows a row at a time, through RowBinary.
+func (s *sparseColumn) read(reader *proto.Reader, rows int) error {
+	var buffer proto.Buffer
+	switch {
+	case len(s.elements) != 0:
+		for _, element := range s.elements {
+			if err := element.read(reader, rows); err != nil {
+				return err
+			}
+		}
+		for row := 0; row < rows; row++ {
+			for _, element := range s.elements {
+				if err := element.column.EncodeRowBinary(&buffer, row); err != nil {
+					return err
+				}
+			}
+		}
+	case s.kinds.kind != serializationSparse:
+		return s.column.Decode(reader, rows)
+	default:
+		// the offsets are the number of defaults before each value, and after the last one
+		var offsets []int
+		for row := 0; ; row++ {
+			group, err := reader.UVarInt()
+			if err != nil {
+				return err
+			}
+			row += int(group &^ sparseEndOfGranule)
+			if group&sparseEndOfGranule != 0 {
+				break
+			}
+			if row >= rows {
+				return fmt.Errorf("sparse offset %d out of %d rows", row, rows)
+			}
+			offsets = append(offsets, row)
+		}
+		if err := s.column.Decode(reader, len(offsets)); err != nil {
+			return err
+		}
+		defaultValue, err := s.defaultRowBinary()
+		if err != nil {
+			return err
+		}
+		for row, next := 0, 0; row < rows; row++ {
+			if next < len(offsets) && offsets[next] == row {
+				if err := s.column.EncodeRowBinary(&buffer, next); err != nil {
+					return err
+				}
+				next++
+				continue
+			}
+			buffer.PutRaw(defaultValue)
+		}
+	}
+	full, err := s.column.Type().Column(s.column.Name(), s.tz)
+	if err != nil {
+		return err
+	}
+	rowReader := proto.NewReader(bytes.NewReader(buffer.Buf))
+	for row := 0; row < rows; row++ {
+		if err := full.DecodeRowBinary(rowReader); err != nil {
+			return err
+		}
+	}
+	s.column = full
+	return nil
+}
+
+// defaultRowBinary returns the RowBinary of the default value of the column, the value of the rows
+// left out of sparse columns
+func (s *sparseColumn) defaultRowBinary() ([]byte, error) {
+	value, err := s.column.Type().Column(s.column.Name(), s.tz)
+	if err != nil {
+		return nil, err
+	}
+	if err := value.AppendRow(nil); err != nil {
+		return nil, err
+	}
+	var buffer proto.Buffer
+	if err := value.EncodeRowBinary(&buffer, 0); err != nil {
+		return nil, err
+	}
+	return buffer.Buf, nil
+}
+
+// readSparseColumn reads the rows of a column of type t with sparse parts
+func readSparseColumn(reader *proto.Reader, name string, t column.Type, tz *time.Location, kinds *serializationKinds, rows int) (column.Interface, error) {
+	sparse, err := newSparseColumn(name, t, tz, kinds)
+	if err != nil {
+		return nil, err
+	}
+	if err := sparse.readStatePrefix(reader); err != nil {
+		return nil, err
+	}
+	if err := sparse.read(reader, rows); err != nil {
+		return nil, err
+	}
+	return sparse.column, nil
+}
@@ -0,0 +1,115 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package proto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/ch-go/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockSparseColumns(t *testing.T) {
+	var buffer proto.Buffer
+	encodeBlockInfo(&buffer)
+	buffer.PutUVarInt(4) // columns
+	buffer.PutUVarInt(6) // rows
+	putSparseOffsets := func(groups ...uint64) {
+		for i, group := range groups {
+			if i == len(groups)-1 {
+				group |= sparseEndOfGranule
+			}
+			buffer.PutUVarInt(group)
+		}
+	}
+
+	buffer.PutString("a")
+	buffer.PutString("UInt32")
+	buffer.PutBool(true)
+	buffer.PutByte(serializationSparse)
+	putSparseOffsets(1, 2, 1) // rows 1 and 4
+	buffer.Buf = binary.LittleEndian.AppendUint32(buffer.Buf, 7)
+	buffer.Buf = binary.LittleEndian.AppendUint32(buffer.Buf, 9)
+
+	buffer.PutString("b")
+	buffer.PutString("Nullable(String)")
+	buffer.PutBool(true)
+	buffer.PutByte(serializationSparse)
+	putSparseOffsets(0, 5) // row 0
+	buffer.PutByte(0)
+	buffer.PutString("x")
+
+	buffer.PutString("c")
+	buffer.PutString("Tuple(s String, n UInt8)")
+	buffer.PutBool(true)
+	buffer.PutByte(serializationDefault)
+	buffer.PutByte(serializationDefault)
+	buffer.PutByte(serializationSparse)
+	for _, s := range []string{"a", "b", "c", "d", "e", "f"} {
+		buffer.PutString(s)
+	}
+	putSparseOffsets(5, 0) // row 5
+	buffer.PutByte(3)
+
+	buffer.PutString("d")
+	buffer.PutString("String")
+	buffer.PutBool(false)
+	for _, s := range []string{"u", "v", "w", "x", "y", "z"} {
+		buffer.PutString(s)
+	}
+
+	block := Block{Timezone: time.UTC}
+	require.NoError(t, block.Decode(proto.NewReader(bytes.NewReader(buffer.Buf)), DBMS_TCP_PROTOCOL_VERSION))
+	require.Equal(t, 6, block.Rows())
+	x := "x"
+	for row, expected := range []struct {
+		a uint32
+		b any
+		c []any
+		d string
+	}{
+		{a: 0, b: &x, c: []any{"a", uint8(0)}, d: "u"},
+		{a: 7, c: []any{"b", uint8(0)}, d: "v"},
+		{a: 0, c: []any{"c", uint8(0)}, d: "w"},
+		{a: 0, c: []any{"d", uint8(0)}, d: "x"},
+		{a: 9, c: []any{"e", uint8(0)}, d: "y"},
+		{a: 0, c: []any{"f", uint8(3)}, d: "z"},
+	} {
+		assert.Equal(t, expected.a, block.Columns[0].Row(row, false), "row %d", row)
+		assert.Equal(t, expected.b, block.Columns[1].Row(row, true), "row %d", row)
+		assert.Equal(t, map[string]any{"s": expected.c[0], "n": expected.c[1]}, block.Columns[2].Row(row, false), "row %d", row)
+		assert.Equal(t, expected.d, block.Columns[3].Row(row, false), "row %d", row)
+	}
+}
+
+func TestBlockUnsupportedSerializationKind(t *testing.T) {
+	var buffer proto.Buffer
+	encodeBlockInfo(&buffer)
+	buffer.PutUVarInt(1)
+	buffer.PutUVarInt(1)
+	buffer.PutString("a")
+	buffer.PutString("UInt32")
+	buffer.PutBool(true)
+	buffer.PutByte(7)
+	block := Block{Timezone: time.UTC}
+	assert.ErrorContains(t, block.Decode(proto.NewReader(bytes.NewReader(buffer.Buf)), DBMS_TCP_PROTOCOL_VERSION), "serialization kind 7")
+}
@@ -37,6 +37,12 @@ func (t *TableColumns) Decode(reader *chproto.Reader, revision uint64) (err erro
 	return nil
 }
 
+// Encode writes the table columns read by Decode, the server side of a ServerTableColumns packet.
+func (t *TableColumns) Encode(buffer *chproto.Buffer, revision uint64) {
+	buffer.PutString(t.First)
+	buffer.PutString(t.Second)
+}
+
 func (t *TableColumns) String() string {
 	return fmt.Sprintf("first=%s, second=%s", t.First, t.Second)
 }
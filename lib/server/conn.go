@@ -0,0 +1,465 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/ClickHouse/ch-go/compress"
+	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+)
+
+type packet struct {
+	code byte
+	err  error
+}
+
+// Conn is the server side of a client connection, used by handlers to answer a query.
+// Its methods must not be used concurrently.
+type Conn struct {
+	server     *Server
+	conn       net.Conn
+	source     *bufio.Reader
+	reader     *chproto.Reader
+	buffer     *chproto.Buffer
+	compressor *compress.Writer
+	hello      Hello
+	revision   uint64
+	chunked    bool // the packets sent to the client are chunked
+	// state of the current query
+	compression bool
+	dataDone    bool
+	watching    bool
+	next        chan packet
+	cancel      context.CancelFunc
+}
+
+func newConn(s *Server, conn net.Conn) *Conn {
+	source := bufio.NewReaderSize(conn, 128<<10)
+	return &Conn{
+		server: s,
+		conn:   conn,
+		source: source,
+		reader: chproto.NewReader(source),
+		buffer: new(chproto.Buffer),
+		next:   make(chan packet, 1),
+	}
+}
+
+// Hello returns the handshake of the client.
+func (c *Conn) Hello() *Hello {
+	return &c.hello
+}
+
+// Revision returns the protocol revision agreed with the client.
+func (c *Conn) Revision() uint64 {
+	return c.revision
+}
+
+// WriteData sends a ServerData packet with block.
+func (c *Conn) WriteData(block *proto.Block) error {
+	return c.writeBlock(proto.ServerData, block, true)
+}
+
+// WriteTotals sends a ServerTotals packet with block.
+func (c *Conn) WriteTotals(block *proto.Block) error {
+	return c.writeBlock(proto.ServerTotals, block, true)
+}
+
+// WriteExtremes sends a ServerExtremes packet with block.
+func (c *Conn) WriteExtremes(block *proto.Block) error {
+	return c.writeBlock(proto.ServerExtremes, block, true)
+}
+
+// WriteProgress sends a ServerProgress packet.
+func (c *Conn) WriteProgress(progress *proto.Progress) error {
+	c.buffer.PutByte(proto.ServerProgress)
+	progress.Encode(c.buffer, c.revision)
+	return c.flush()
+}
+
+// WriteProfileInfo sends a ServerProfileInfo packet.
+func (c *Conn) WriteProfileInfo(info *proto.ProfileInfo) error {
+	c.buffer.PutByte(proto.ServerProfileInfo)
+	info.Encode(c.buffer, c.revision)
+	return c.flush()
+}
+
+// WriteTimezoneUpdate sends a ServerTimezoneUpdate packet, the timezone of the query results,
+// e.g. after the session_timezone setting. An empty name is the timezone of the server.
+func (c *Conn) WriteTimezoneUpdate(name string) error {
+	if c.revision < proto.DBMS_MIN_PROTOCOL_VERSION_WITH_TIMEZONE_UPDATES {
+		return nil
+	}
+	c.buffer.PutByte(proto.ServerTimezoneUpdate)
+	c.buffer.PutString(name)
+	return c.flush()
+}
+
+// WritePartUUIDs sends a ServerPartUUIDs packet with the UUIDs of the data parts read by the query.
+func (c *Conn) WritePartUUIDs(uuids proto.PartUUIDs) error {
+	c.buffer.PutByte(proto.ServerPartUUIDs)
+	uuids.Encode(c.buffer)
+	return c.flush()
+}
+
+// WriteTableColumns sends a ServerTableColumns packet with the description of the columns of table.
+func (c *Conn) WriteTableColumns(table, description string) error {
+	c.buffer.PutByte(proto.ServerTableColumns)
+	(&proto.TableColumns{First: table, Second: description}).Encode(c.buffer, c.revision)
+	return c.flush()
+}
+
+// WriteLogs sends a ServerLog packet with logs.
+func (c *Conn) WriteLogs(logs []proto.Log) error {
+	block, err := proto.NewLogBlock(logs)
+	if err != nil {
+		return err
+	}
+	return c.writeBlock(proto.ServerLog, block, false)
+}
+
+// WriteBlock sends block in a packet with code, one of the block packets such as proto.ServerData
+// or proto.ServerProfileEvents. Only data, totals and extremes are compressed.
+func (c *Conn) WriteBlock(code byte, block *proto.Block) error {
+	switch code {
+	case proto.ServerData, proto.ServerTotals, proto.ServerExtremes:
+		return c.writeBlock(code, block, true)
+	case proto.ServerLog, proto.ServerProfileEvents:
+		return c.writeBlock(code, block, false)
+	}
+	return fmt.Errorf("clickhouse server: packet %d is not a block", code)
+}
+
+// ReadData returns the next block of data sent by the client for a query with Query.HasData.
+// It returns io.EOF after the last block and ErrQueryCanceled if the client cancels the query.
+func (c *Conn) ReadData() (*proto.Block, error) {
+	if c.dataDone {
+		return nil, io.EOF
+	}
+	for {
+		code, err := c.reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		switch code {
+		case proto.ClientData:
+			_, block, err := c.readData()
+			if err != nil {
+				return nil, err
+			}
+			if len(block.Columns) == 0 {
+				c.dataDone = true
+				c.watch()
+				return nil, io.EOF
+			}
+			return block, nil
+		case proto.ClientCancel:
+			c.dataDone = true
+			c.cancel()
+			return nil, ErrQueryCanceled
+		default:
+			return nil, fmt.Errorf("clickhouse server: unexpected packet %d, expected data", code)
+		}
+	}
+}
+
+func (c *Conn) serve(ctx context.Context) error {
+	if err := c.handshake(ctx); err != nil {
+		return err
+	}
+	for {
+		var code byte
+		switch next := c.readPacket(); {
+		case next.err == io.EOF:
+			return nil
+		case next.err != nil:
+			return next.err
+		default:
+			code = next.code
+		}
+		switch code {
+		case proto.ClientPing:
+			c.buffer.PutByte(proto.ServerPong)
+			if err := c.flush(); err != nil {
+				return err
+			}
+		case proto.ClientQuery:
+			if err := c.query(ctx); err != nil {
+				return err
+			}
+		case proto.ClientData:
+			// data left over from a failed INSERT
+			if _, _, err := c.readData(); err != nil {
+				return err
+			}
+		case proto.ClientCancel:
+		default:
+			return fmt.Errorf("clickhouse server: unexpected packet %d", code)
+		}
+	}
+}
+
+// readPacket reads the next packet code, which may have been read by the cancel watcher
+func (c *Conn) readPacket() packet {
+	if c.watching {
+		c.watching = false
+		return <-c.next
+	}
+	code, err := c.reader.ReadByte()
+	return packet{code: code, err: err}
+}
+
+// watch reads the packets of the client while a handler answers a query without reading data,
+// and cancels the query on ClientCancel. The next packet is passed on to readPacket.
+func (c *Conn) watch() {
+	if c.watching {
+		return
+	}
+	c.watching = true
+	go func() {
+		for {
+			code, err := c.reader.ReadByte()
+			if err == nil && code == proto.ClientCancel {
+				c.cancel()
+				continue
+			}
+			if err != nil {
+				c.cancel()
+			}
+			c.next <- packet{code: code, err: err}
+			return
+		}
+	}()
+}
+
+func (c *Conn) handshake(ctx context.Context) error {
+	code, err := c.reader.ReadByte()
+	if err != nil {
+		return err
+	}
+	if code != proto.ClientHello {
+		return fmt.Errorf("clickhouse server: unexpected packet %d during handshake", code)
+	}
+	if err := c.hello.Decode(c.reader); err != nil {
+		return err
+	}
+	for _, v := range []*string{&c.hello.Database, &c.hello.Username, &c.hello.Password} {
+		if *v, err = c.reader.Str(); err != nil {
+			return err
+		}
+	}
+	if username, ok := strings.CutPrefix(c.hello.Username, proto.SSHKeyAuthMarker); ok {
+		c.hello.Username = username
+		if err := c.sshChallenge(); err != nil {
+			return err
+		}
+	}
+	c.hello.RemoteAddr = c.conn.RemoteAddr()
+	server := c.server.handshake()
+	c.revision = min(c.hello.ProtocolVersion, server.Revision)
+	if err := c.server.Handler.Handshake(ctx, &c.hello); err != nil {
+		_ = c.exception(err)
+		return err
+	}
+	c.buffer.PutByte(proto.ServerHello)
+	server.Encode(c.buffer, c.hello.ProtocolVersion)
+	if err := c.flush(); err != nil {
+		return err
+	}
+	if c.revision >= proto.DBMS_MIN_PROTOCOL_VERSION_WITH_ADDENDUM {
+		if err := c.addendum(&server); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sshChallenge sends a challenge to a client authenticated with an SSH key and reads its signature.
+func (c *Conn) sshChallenge() error {
+	if c.hello.ProtocolVersion < proto.DBMS_MIN_REVISION_WITH_SSH_AUTHENTICATION {
+		return fmt.Errorf("clickhouse server: SSH key authentication with protocol revision %d", c.hello.ProtocolVersion)
+	}
+	if code, err := c.reader.ReadByte(); err != nil {
+		return err
+	} else if code != proto.ClientSSHChallengeRequest {
+		return fmt.Errorf("clickhouse server: unexpected packet %d, expected SSH challenge request", code)
+	}
+	var nonce [32]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return err
+	}
+	challenge := hex.EncodeToString(nonce[:])
+	c.buffer.PutByte(proto.ServerSSHChallenge)
+	c.buffer.PutString(challenge)
+	if err := c.flush(); err != nil {
+		return err
+	}
+	if code, err := c.reader.ReadByte(); err != nil {
+		return err
+	} else if code != proto.ClientSSHChallengeResponse {
+		return fmt.Errorf("clickhouse server: unexpected packet %d, expected SSH challenge response", code)
+	}
+	signature, err := c.reader.StrBytes()
+	if err != nil {
+		return err
+	}
+	c.hello.SSHSignature = signature
+	c.hello.SSHMessage = proto.SSHAuthMessage(c.hello.ProtocolVersion, c.hello.Database, c.hello.Username, challenge)
+	return nil
+}
+
+// addendum reads what the client sends after the handshake: the quota key, the framing of the
+// packets and the parallel replicas protocol.
+func (c *Conn) addendum(server *proto.ServerHandshake) (err error) {
+	if c.hello.QuotaKey, err = c.reader.Str(); err != nil {
+		return err
+	}
+	if c.revision >= proto.DBMS_MIN_PROTOCOL_VERSION_WITH_CHUNKED_PACKETS {
+		for _, framing := range []struct {
+			server  string
+			chunked *bool
+		}{
+			{server: server.ChunkedRecv, chunked: &c.hello.ChunkedSend},
+			{server: server.ChunkedSend, chunked: &c.hello.ChunkedRecv},
+		} {
+			client, err := c.reader.Str()
+			if err != nil {
+				return err
+			}
+			if *framing.chunked, err = proto.NegotiateChunked(framing.server, client); err != nil {
+				return fmt.Errorf("clickhouse server: %w", err)
+			}
+		}
+	}
+	if c.revision >= proto.DBMS_MIN_REVISION_WITH_VERSIONED_PARALLEL_REPLICAS_PROTOCOL {
+		if c.hello.ParallelReplicasProtocolVersion, err = c.reader.UVarInt(); err != nil {
+			return err
+		}
+	}
+	if c.hello.ChunkedSend {
+		c.reader = chproto.NewReader(proto.NewChunkedReader(c.source))
+	}
+	c.chunked = c.hello.ChunkedRecv
+	return nil
+}
+
+func (c *Conn) query(ctx context.Context) error {
+	query := Query{}
+	if err := query.Decode(c.reader, c.revision); err != nil {
+		return err
+	}
+	c.compression, c.dataDone = query.Compression, false
+	c.compressor = compress.NewWriter(compress.LevelZero, compress.LZ4)
+	for _, s := range query.Settings {
+		if s.Key == "network_compression_method" && strings.EqualFold(fmt.Sprint(s.Value), "ZSTD") {
+			c.compressor = compress.NewWriter(compress.LevelZero, compress.ZSTD)
+		}
+	}
+	for {
+		code, err := c.reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		if code != proto.ClientData {
+			return fmt.Errorf("clickhouse server: unexpected packet %d, expected external tables", code)
+		}
+		name, block, err := c.readData()
+		if err != nil {
+			return err
+		}
+		if len(block.Columns) == 0 {
+			break
+		}
+		query.External = append(query.External, ExternalTable{Name: name, Block: block})
+	}
+	if query.HasData = hasData(query.Body); !query.HasData {
+		c.dataDone = true
+	}
+
+	ctx, c.cancel = context.WithCancel(ctx)
+	defer c.cancel()
+	if c.dataDone {
+		c.watch()
+	}
+	if err := c.server.Handler.Query(ctx, c, &query); err != nil {
+		return c.exception(err)
+	}
+	c.buffer.PutByte(proto.ServerEndOfStream)
+	return c.flush()
+}
+
+func (c *Conn) readData() (string, *proto.Block, error) {
+	name, err := c.reader.Str()
+	if err != nil {
+		return "", nil, err
+	}
+	if c.compression {
+		c.reader.EnableCompression()
+		defer c.reader.DisableCompression()
+	}
+	block := &proto.Block{Timezone: c.server.handshake().Timezone}
+	if err := block.Decode(c.reader, c.revision); err != nil {
+		return "", nil, err
+	}
+	return name, block, nil
+}
+
+func (c *Conn) writeBlock(code byte, block *proto.Block, compressible bool) error {
+	c.buffer.PutByte(code)
+	c.buffer.PutString("")
+	start := len(c.buffer.Buf)
+	if err := block.Encode(c.buffer, c.revision); err != nil {
+		c.buffer.Reset()
+		return err
+	}
+	if compressible && c.compression {
+		if err := c.compressor.Compress(c.buffer.Buf[start:]); err != nil {
+			c.buffer.Reset()
+			return err
+		}
+		c.buffer.Buf = append(c.buffer.Buf[:start], c.compressor.Data...)
+	}
+	return c.flush()
+}
+
+func (c *Conn) exception(err error) error {
+	c.buffer.Reset()
+	c.buffer.PutByte(proto.ServerException)
+	exception(err).Encode(c.buffer)
+	return c.flush()
+}
+
+// flush sends the packet in the buffer
+func (c *Conn) flush() error {
+	defer c.buffer.Reset()
+	data := c.buffer.Buf
+	if c.chunked {
+		data = proto.AppendChunks(nil, data, []int{len(data)})
+	}
+	_, err := c.conn.Write(data)
+	return err
+}
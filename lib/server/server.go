@@ -0,0 +1,247 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package server implements the server side of the ClickHouse native protocol. A Server accepts
+// native protocol clients, performs the handshake and passes their queries to a Handler, which
+// answers them through the Conn. It is meant for proxies, gateways and test servers.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrServerClosed is returned by Serve after Close.
+var ErrServerClosed = errors.New("clickhouse: server closed")
+
+// ErrQueryCanceled is returned by Conn.ReadData when the client cancels the query.
+var ErrQueryCanceled = errors.New("clickhouse: query canceled by client")
+
+// CodeStdException is the exception code sent for handler errors that are not a *proto.Exception.
+const CodeStdException int32 = 1001
+
+// Hello is the handshake of a client.
+type Hello struct {
+	proto.ClientHandshake
+	Database   string
+	Username   string
+	Password   string
+	QuotaKey   string
+	RemoteAddr net.Addr
+	// ChunkedSend and ChunkedRecv report whether the packets sent and received by the client are
+	// chunked, as agreed in the addendum.
+	ChunkedSend bool
+	ChunkedRecv bool
+	// ParallelReplicasProtocolVersion is the parallel replicas protocol of the client.
+	ParallelReplicasProtocolVersion uint64
+	// SSHSignature is the signature of SSHMessage by a client authenticated with an SSH key, whose
+	// Password is empty. Handshake checks it with VerifySSHKey.
+	SSHSignature []byte
+	SSHMessage   []byte
+}
+
+// VerifySSHKey returns an error unless the client is authenticated with the private key of key.
+func (h *Hello) VerifySSHKey(key ssh.PublicKey) error {
+	if h.SSHSignature == nil {
+		return errors.New("clickhouse server: the client is not authenticated with an SSH key")
+	}
+	var signature ssh.Signature
+	if err := ssh.Unmarshal(h.SSHSignature, &signature); err != nil {
+		return fmt.Errorf("clickhouse server: SSH signature: %w", err)
+	}
+	return key.Verify(h.SSHMessage, &signature)
+}
+
+// ExternalTable is a temporary table sent by the client with its query.
+type ExternalTable struct {
+	Name  string
+	Block *proto.Block
+}
+
+// Query is a query sent by a client.
+type Query struct {
+	proto.Query
+	External []ExternalTable
+	// HasData reports whether the client sends the data of the query in blocks, as for
+	// INSERT queries without inline data. Handlers of such queries must send the header
+	// block of the table with Conn.WriteData and then read the data with Conn.ReadData.
+	HasData bool
+}
+
+// Handler handles the clients of a Server.
+type Handler interface {
+	// Handshake is called with the hello of each client. An error rejects the client.
+	Handshake(ctx context.Context, hello *Hello) error
+	// Query answers a query with the Write methods of conn. The end of the query is sent
+	// when Query returns nil, an error is sent as an exception. The context is canceled
+	// when the client cancels the query or disconnects, and when the server is closed.
+	Query(ctx context.Context, conn *Conn, query *Query) error
+}
+
+// Server serves the native protocol to the clients of Handler.
+type Server struct {
+	Handler     Handler
+	Name        string // ClickHouse by default
+	DisplayName string
+	Version     proto.Version
+	Revision    uint64         // proto.DBMS_TCP_PROTOCOL_VERSION by default
+	Timezone    *time.Location // UTC by default
+	// Framing is the framing of the packets in both directions, proto.FramingNotChunkedOptional by
+	// default. The optional framings use the one asked for by the client.
+	Framing string
+	// ErrorLog logs connections closed with an error, they are discarded if it is nil.
+	ErrorLog func(format string, v ...any)
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+func (s *Server) init() {
+	if s.ctx == nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+		s.listeners = make(map[net.Listener]struct{})
+		s.conns = make(map[net.Conn]struct{})
+	}
+}
+
+// Serve accepts clients on listener until it fails or the server is closed.
+func (s *Server) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	if s.init(); s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.listeners[listener] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, listener)
+		s.mu.Unlock()
+	}()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		go func() {
+			if err := s.ServeConn(conn); err != nil && s.ErrorLog != nil {
+				s.ErrorLog("clickhouse server: %s: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// ServeConn serves a single client connection and closes it when the client disconnects.
+func (s *Server) ServeConn(conn net.Conn) error {
+	s.mu.Lock()
+	if s.init(); s.closed {
+		s.mu.Unlock()
+		_ = conn.Close()
+		return ErrServerClosed
+	}
+	s.conns[conn] = struct{}{}
+	s.wg.Add(1)
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+		s.wg.Done()
+	}()
+	return newConn(s, conn).serve(s.ctx)
+}
+
+// Close stops the listeners, closes the client connections and waits for their handlers to return.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.init()
+	s.closed = true
+	s.cancel()
+	for listener := range s.listeners {
+		_ = listener.Close()
+	}
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+func (s *Server) handshake() proto.ServerHandshake {
+	handshake := proto.ServerHandshake{
+		Name:        s.Name,
+		DisplayName: s.DisplayName,
+		Revision:    s.Revision,
+		Version:     s.Version,
+		Timezone:    s.Timezone,
+		ChunkedSend: s.Framing,
+		ChunkedRecv: s.Framing,
+
+		ParallelReplicasProtocolVersion: proto.DBMS_PARALLEL_REPLICAS_PROTOCOL_VERSION,
+	}
+	if handshake.Name == "" {
+		handshake.Name = "ClickHouse"
+	}
+	if handshake.Revision == 0 {
+		handshake.Revision = proto.DBMS_TCP_PROTOCOL_VERSION
+	}
+	if handshake.Timezone == nil {
+		handshake.Timezone = time.UTC
+	}
+	return handshake
+}
+
+var insertDataMatch = regexp.MustCompile(`(?is)^\s*INSERT\s+INTO\s+(?:TABLE\s+)?[^\s(]+\s*(?:\([^()]*(?:\([^()]*\)[^()]*)*\))?\s*(?:VALUES|FORMAT\s+\w+)?\s*;?\s*$`)
+
+// hasData reports whether the client sends the data of query in blocks
+func hasData(query string) bool {
+	return insertDataMatch.MatchString(query)
+}
+
+// exception returns err as an exception sent to clients
+func exception(err error) *proto.Exception {
+	var e *proto.Exception
+	if errors.As(err, &e) {
+		return e
+	}
+	return &proto.Exception{
+		Code:    CodeStdException,
+		Name:    "std::exception",
+		Message: fmt.Sprint(err),
+	}
+}
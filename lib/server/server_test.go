@@ -0,0 +1,443 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package server
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/ext"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/crypto/ssh"
+)
+
+type testHandler struct {
+	hellos  chan *Hello
+	queries chan *Query
+	query   func(ctx context.Context, conn *Conn, query *Query) error
+}
+
+func (h *testHandler) Handshake(_ context.Context, hello *Hello) error {
+	if hello.Password != "secret" {
+		return &proto.Exception{Code: 516, Name: "DB::Exception", Message: "Authentication failed"}
+	}
+	h.hellos <- hello
+	return nil
+}
+
+func (h *testHandler) Query(ctx context.Context, conn *Conn, query *Query) error {
+	h.queries <- query
+	return h.query(ctx, conn, query)
+}
+
+func startServer(t *testing.T, query func(ctx context.Context, conn *Conn, query *Query) error, configure ...func(*Server)) (*testHandler, *clickhouse.Options) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	handler := &testHandler{
+		hellos:  make(chan *Hello, 10),
+		queries: make(chan *Query, 10),
+		query:   query,
+	}
+	srv := &Server{Handler: handler, Version: proto.Version{Major: 24, Minor: 8, Patch: 1}}
+	for _, configure := range configure {
+		configure(srv)
+	}
+	go func() {
+		assert.ErrorIs(t, srv.Serve(listener), ErrServerClosed)
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, srv.Close())
+	})
+	return handler, &clickhouse.Options{
+		Addr:        []string{listener.Addr().String()},
+		Auth:        clickhouse.Auth{Database: "db", Username: "user", Password: "secret"},
+		Compression: &clickhouse.Compression{Method: clickhouse.CompressionLZ4},
+	}
+}
+
+func TestServerQuery(t *testing.T) {
+	handler, opt := startServer(t, func(ctx context.Context, conn *Conn, query *Query) error {
+		block := &proto.Block{}
+		assert.NoError(t, block.AddColumn("n", "UInt64"))
+		if err := conn.WriteData(block); err != nil {
+			return err
+		}
+		assert.NoError(t, block.Append(uint64(1)))
+		assert.NoError(t, block.Append(uint64(2)))
+		if err := conn.WriteLogs([]proto.Log{{Time: time.Unix(1700000000, 0), Hostname: "test", Priority: 6, Text: "reading"}}); err != nil {
+			return err
+		}
+		if err := conn.WriteProgress(&proto.Progress{Rows: 2, Bytes: 16, Elapsed: time.Millisecond}); err != nil {
+			return err
+		}
+		if err := conn.WriteData(block); err != nil {
+			return err
+		}
+		if err := conn.WriteProfileInfo(&proto.ProfileInfo{Rows: 2, Blocks: 1}); err != nil {
+			return err
+		}
+		return conn.WriteTotals(block)
+	})
+	opt.QuotaKey = "tenant-1"
+	conn, err := clickhouse.Open(opt)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	table, err := ext.NewTable("ids", ext.Column("id", "UInt8"))
+	require.NoError(t, err)
+	require.NoError(t, table.Append(uint8(7)))
+	var (
+		logs     []*clickhouse.Log
+		progress []*clickhouse.Progress
+		info     []*clickhouse.ProfileInfo
+		span     = trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			SpanID:     trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+			TraceFlags: trace.FlagsSampled,
+		})
+		ctx = clickhouse.Context(context.Background(),
+			clickhouse.WithQueryID("query-1"),
+			clickhouse.WithSettings(clickhouse.Settings{"max_threads": 4}),
+			clickhouse.WithParameters(clickhouse.Parameters{"name": "it's"}),
+			clickhouse.WithSpan(span),
+			clickhouse.WithExternalTable(table),
+			clickhouse.WithLogs(func(l *clickhouse.Log) { logs = append(logs, l) }),
+			clickhouse.WithProgress(func(p *clickhouse.Progress) { progress = append(progress, p) }),
+			clickhouse.WithProfileInfo(func(p *clickhouse.ProfileInfo) { info = append(info, p) }),
+		)
+	)
+	rows, err := conn.Query(ctx, "SELECT n FROM numbers WHERE name = {name:String}")
+	require.NoError(t, err)
+	var values []uint64
+	for rows.Next() {
+		var n uint64
+		require.NoError(t, rows.Scan(&n))
+		values = append(values, n)
+	}
+	require.NoError(t, rows.Err())
+	var total uint64
+	require.NoError(t, rows.Totals(&total))
+	require.NoError(t, rows.Close())
+	assert.Equal(t, []uint64{1, 2}, values)
+	assert.Equal(t, uint64(1), total)
+
+	hello := <-handler.hellos
+	assert.Equal(t, "db", hello.Database)
+	assert.Equal(t, "user", hello.Username)
+	assert.Equal(t, uint64(proto.DBMS_TCP_PROTOCOL_VERSION), hello.ProtocolVersion)
+
+	query := <-handler.queries
+	// the addendum is read before the first query
+	assert.Equal(t, "tenant-1", hello.QuotaKey)
+	assert.Equal(t, "query-1", query.ID)
+	assert.Equal(t, "SELECT n FROM numbers WHERE name = {name:String}", query.Body)
+	assert.Equal(t, proto.Parameters{{Key: "name", Value: "it's"}}, query.Parameters)
+	assert.Contains(t, query.Settings, proto.Setting{Key: "max_threads", Value: "4", Important: true})
+	assert.True(t, query.Compression)
+	assert.False(t, query.HasData)
+	assert.Equal(t, span.TraceID(), query.Span.TraceID())
+	assert.Equal(t, span.SpanID(), query.Span.SpanID())
+	assert.True(t, query.Span.IsSampled())
+	require.Len(t, query.External, 1)
+	assert.Equal(t, "ids", query.External[0].Name)
+	assert.Equal(t, 1, query.External[0].Block.Rows())
+
+	require.Len(t, logs, 1)
+	assert.Equal(t, "reading", logs[0].Text)
+	require.Len(t, progress, 1)
+	assert.Equal(t, uint64(2), progress[0].Rows)
+	assert.Equal(t, time.Millisecond, progress[0].Elapsed)
+	require.Len(t, info, 1)
+	assert.Equal(t, uint64(2), info[0].Rows)
+}
+
+func TestServerInsert(t *testing.T) {
+	inserted := make(chan int, 1)
+	_, opt := startServer(t, func(ctx context.Context, conn *Conn, query *Query) error {
+		if !query.HasData {
+			return errors.New("expected an INSERT")
+		}
+		header := &proto.Block{}
+		assert.NoError(t, header.AddColumn("id", "UInt64"))
+		assert.NoError(t, header.AddColumn("name", "String"))
+		if err := conn.WriteTableColumns("db.t", "columns format version: 1\n2 columns:\n`id` UInt64\n`name` String\tDEFAULT\t\\'x\\'\n"); err != nil {
+			return err
+		}
+		if err := conn.WriteData(header); err != nil {
+			return err
+		}
+		var rows int
+		for {
+			block, err := conn.ReadData()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			rows += block.Rows()
+		}
+		inserted <- rows
+		return nil
+	})
+	conn, err := clickhouse.Open(opt)
+	require.NoError(t, err)
+	defer conn.Close()
+	ctx := context.Background()
+
+	batch, err := conn.PrepareBatch(ctx, "INSERT INTO t")
+	require.NoError(t, err)
+	assert.Equal(t, "DEFAULT", batch.(driver.SchemaBatch).TableColumns()[1].DefaultKind)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, batch.Append(uint64(i), "name"))
+	}
+	require.NoError(t, batch.Send())
+	assert.Equal(t, 5, <-inserted)
+
+	err = conn.Exec(ctx, "OPTIMIZE TABLE t")
+	var exception *proto.Exception
+	require.ErrorAs(t, err, &exception)
+	assert.Equal(t, CodeStdException, exception.Code)
+	assert.Equal(t, "expected an INSERT", exception.Message)
+	require.NoError(t, conn.Ping(ctx))
+}
+
+func TestServerChunkedPackets(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	for name, c := range map[string]struct {
+		framing string
+		client  bool
+	}{
+		"client":     {framing: "", client: true},
+		"server":     {framing: proto.FramingChunked},
+		"notchunked": {framing: proto.FramingNotChunked},
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler, opt := startServer(t, func(ctx context.Context, conn *Conn, query *Query) error {
+				if query.HasData {
+					header := &proto.Block{}
+					assert.NoError(t, header.AddColumn("n", "UInt64"))
+					if err := conn.WriteData(header); err != nil {
+						return err
+					}
+					for {
+						if _, err := conn.ReadData(); errors.Is(err, io.EOF) {
+							return nil
+						} else if err != nil {
+							return err
+						}
+					}
+				}
+				if err := conn.WriteTimezoneUpdate("Asia/Tokyo"); err != nil {
+					return err
+				}
+				if err := conn.WritePartUUIDs(proto.PartUUIDs{{1, 2, 3}}); err != nil {
+					return err
+				}
+				block := &proto.Block{}
+				assert.NoError(t, block.AddColumn("t", "DateTime"))
+				assert.NoError(t, block.Append(time.Unix(1700000000, 0)))
+				return conn.WriteData(block)
+			}, func(s *Server) {
+				s.Framing = c.framing
+			})
+			opt.ChunkedPackets = c.client
+			conn, err := clickhouse.Open(opt)
+			require.NoError(t, err)
+			defer conn.Close()
+			ctx := context.Background()
+
+			var ts time.Time
+			require.NoError(t, conn.QueryRow(ctx, "SELECT now()").Scan(&ts))
+			assert.Equal(t, time.Unix(1700000000, 0).In(tokyo), ts)
+			assert.Equal(t, tokyo, ts.Location())
+
+			batch, err := conn.PrepareBatch(ctx, "INSERT INTO t")
+			require.NoError(t, err)
+			for i := 0; i < 3; i++ {
+				require.NoError(t, batch.Append(uint64(i)))
+			}
+			require.NoError(t, batch.Send())
+			require.NoError(t, conn.Ping(ctx))
+
+			hello := <-handler.hellos
+			<-handler.queries // the addendum is read before the first query
+			chunked := c.framing != proto.FramingNotChunked
+			assert.Equal(t, chunked, hello.ChunkedSend)
+			assert.Equal(t, chunked, hello.ChunkedRecv)
+			assert.Equal(t, uint64(proto.DBMS_PARALLEL_REPLICAS_PROTOCOL_VERSION), hello.ParallelReplicasProtocolVersion)
+		})
+	}
+}
+
+func TestServerCancel(t *testing.T) {
+	canceled := make(chan error, 1)
+	_, opt := startServer(t, func(ctx context.Context, conn *Conn, query *Query) error {
+		<-ctx.Done()
+		canceled <- ctx.Err()
+		return ctx.Err()
+	})
+	conn, err := clickhouse.Open(opt)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.Error(t, conn.Exec(ctx, "SELECT sleep(3)"))
+	select {
+	case err := <-canceled:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler context not canceled")
+	}
+}
+
+func TestServerHandshakeError(t *testing.T) {
+	_, opt := startServer(t, nil)
+	opt.Auth.Password = "wrong"
+	conn, err := clickhouse.Open(opt)
+	if err == nil {
+		err = conn.Ping(context.Background())
+	}
+	var exception *proto.Exception
+	require.ErrorAs(t, err, &exception)
+	assert.Equal(t, int32(516), exception.Code)
+}
+
+func TestServerCredentialsProvider(t *testing.T) {
+	handler, opt := startServer(t, func(context.Context, *Conn, *Query) error {
+		return nil
+	})
+	var calls int
+	opt.CredentialsProvider = clickhouse.CredentialsProviderFunc(func(context.Context) (*clickhouse.Credentials, error) {
+		calls++
+		return &clickhouse.Credentials{
+			Username: fmt.Sprintf("user-%d", calls),
+			Password: "secret",
+			Expiry:   time.Now().Add(200 * time.Millisecond),
+		}, nil
+	})
+	opt.MaxOpenConns = 1
+	conn, err := clickhouse.Open(opt)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.Exec(context.Background(), "SELECT 1"))
+	require.NoError(t, conn.Exec(context.Background(), "SELECT 2"))
+	assert.Equal(t, "user-1", (<-handler.hellos).Username)
+	time.Sleep(300 * time.Millisecond)
+	require.NoError(t, conn.Exec(context.Background(), "SELECT 3"))
+	assert.Equal(t, "user-2", (<-handler.hellos).Username)
+	assert.Equal(t, 2, calls)
+}
+
+func TestServerCredentialsProviderError(t *testing.T) {
+	_, opt := startServer(t, nil)
+	opt.CredentialsProvider = clickhouse.CredentialsProviderFunc(func(context.Context) (*clickhouse.Credentials, error) {
+		return nil, errors.New("vault unavailable")
+	})
+	conn, err := clickhouse.Open(opt)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.ErrorContains(t, conn.Ping(context.Background()), "failed to get credentials: vault unavailable")
+}
+
+type sshHandler struct {
+	key ssh.PublicKey
+}
+
+func (h *sshHandler) Handshake(_ context.Context, hello *Hello) error {
+	if hello.Username != "user" || hello.Password != "" || hello.VerifySSHKey(h.key) != nil {
+		return &proto.Exception{Code: 516, Name: "DB::Exception", Message: "Authentication failed"}
+	}
+	return nil
+}
+
+func (h *sshHandler) Query(context.Context, *Conn, *Query) error {
+	return nil
+}
+
+func TestServerSSHKeyAuth(t *testing.T) {
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	var signers []ssh.Signer
+	for _, key := range []any{ed25519Key, rsaKey} {
+		signer, err := ssh.NewSignerFromKey(key)
+		require.NoError(t, err)
+		signers = append(signers, signer)
+	}
+
+	for _, signer := range signers {
+		t.Run(signer.PublicKey().Type(), func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			srv := &Server{Handler: &sshHandler{key: signer.PublicKey()}}
+			go func() {
+				assert.ErrorIs(t, srv.Serve(listener), ErrServerClosed)
+			}()
+			defer srv.Close()
+
+			for _, client := range signers {
+				conn, err := clickhouse.Open(&clickhouse.Options{
+					Addr: []string{listener.Addr().String()},
+					Auth: clickhouse.Auth{Database: "db", Username: "user", SSHSigner: client},
+				})
+				require.NoError(t, err)
+				err = conn.Ping(context.Background())
+				if client == signer {
+					assert.NoError(t, err)
+				} else {
+					var exception *proto.Exception
+					require.ErrorAs(t, err, &exception)
+					assert.Equal(t, int32(516), exception.Code)
+				}
+				require.NoError(t, conn.Close())
+			}
+		})
+	}
+}
+
+func TestHasData(t *testing.T) {
+	for query, expected := range map[string]bool{
+		"INSERT INTO t FORMAT Native":                true,
+		"insert into db.t (a, b) VALUES":             true,
+		"INSERT INTO t (a, b) FORMAT Native":         true,
+		"INSERT INTO t (a, b) VALUES (1, 2)":         false,
+		"INSERT INTO t SELECT * FROM s":              false,
+		"INSERT INTO t (a, `b(1)`) FORMAT RowBinary": true,
+		"SELECT 1": false,
+	} {
+		assert.Equal(t, expected, hasData(query), query)
+	}
+}
@@ -0,0 +1,401 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Command structgen generates reflection-free AppendTo and ScanFrom methods for structs used with
+// Batch.AppendStruct, Rows.ScanStruct and Row.ScanStruct.
+//
+//	//go:generate go run github.com/ClickHouse/clickhouse-go/v2/lib/structgen -type Event -schema event.schema
+//
+// Columns are mapped to fields the same way the driver does: the ch tag name, ch:",prefix=..." for
+// nested named structs, embedded structs and optionally snake_case field names. Unlike the driver,
+// the generated methods do not support pointers to embedded or prefixed structs.
+// The schema file holds one "name Type" column per line, e.g. the output of DESCRIBE TABLE in
+// TabSeparated format, and every column of the schema must be mapped to a field. The generated
+// methods expect the columns in schema order. Fields whose Go type is the one of their column,
+// e.g. uint64 for UInt64 or time.Time for DateTime, are appended and scanned with the typed
+// AppendValue and Value methods of the column, others go through AppendRow and ScanRow.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/ClickHouse/clickhouse-go/v2/internal/structtag"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/chtype"
+)
+
+//go:embed struct.tpl
+var structSrc string
+
+var structTpl = template.Must(template.New("struct").Funcs(template.FuncMap{
+	"withOp": func(op string, st structType) any {
+		return struct {
+			structType
+			Op string
+		}{st, op}
+	},
+}).Parse(structSrc))
+
+type options struct {
+	dir       string
+	types     []string
+	schema    string
+	snakeCase bool
+	args      string // command line recorded in the generated file
+}
+
+func main() {
+	var (
+		opt    options
+		types  = flag.String("type", "", "comma-separated list of struct type names; required")
+		output = flag.String("output", "", "output file name; default <type>_ch.go")
+	)
+	flag.StringVar(&opt.dir, "dir", ".", "directory of the Go package declaring the types")
+	flag.StringVar(&opt.schema, "schema", "", "table schema file, one \"name Type\" column per line; required")
+	flag.BoolVar(&opt.snakeCase, "snake", false, "map fields without a ch tag to snake_case column names")
+	flag.Parse()
+	if len(*types) == 0 || len(opt.schema) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	opt.types = strings.Split(*types, ",")
+	opt.args = strings.Join(os.Args[1:], " ")
+	data, err := generate(opt)
+	if err != nil {
+		log.Fatal(err)
+	}
+	name := *output
+	if len(name) == 0 {
+		name = strings.ToLower(opt.types[0]) + "_ch.go"
+	}
+	if err := os.WriteFile(filepath.Join(opt.dir, name), data, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// typedColumns are the column types with AppendValue and Value methods, by ClickHouse type name
+var typedColumns = map[string]struct {
+	column string // type in lib/column
+	goType string // Go type of the values, qualified with the import path
+}{
+	"Int8":       {"Int8", "int8"},
+	"Int16":      {"Int16", "int16"},
+	"Int32":      {"Int32", "int32"},
+	"Int64":      {"Int64", "int64"},
+	"UInt8":      {"UInt8", "uint8"},
+	"UInt16":     {"UInt16", "uint16"},
+	"UInt32":     {"UInt32", "uint32"},
+	"UInt64":     {"UInt64", "uint64"},
+	"Float32":    {"Float32", "float32"},
+	"Float64":    {"Float64", "float64"},
+	"String":     {"String", "string"},
+	"Bool":       {"Bool", "bool"},
+	"Boolean":    {"Bool", "bool"},
+	"UUID":       {"UUID", "github.com/google/uuid.UUID"},
+	"Date":       {"Date", "time.Time"},
+	"Date32":     {"Date32", "time.Time"},
+	"DateTime":   {"DateTime", "time.Time"},
+	"DateTime64": {"DateTime64", "time.Time"},
+}
+
+type schemaColumn struct {
+	Name string
+	Type string
+}
+
+// structDecl is a struct type declaration and the imports of its file, by package name
+type structDecl struct {
+	st      *ast.StructType
+	imports map[string]string
+}
+
+type field struct {
+	Column string
+	Expr   string // selector relative to the struct, e.g. Address.City
+	GoType string // type of the field, qualified with the import path, e.g. time.Time
+
+	Type        string // ClickHouse type of the column
+	TypedColumn string // lib/column type with AppendValue and Value for the field, if any
+}
+
+type structType struct {
+	Name   string
+	Fields []field // in schema column order
+}
+
+func generate(opt options) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, opt.dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		pkgName string
+		decls   = make(map[string]structDecl)
+	)
+	for name, pkg := range pkgs {
+		pkgName = name
+		for _, file := range pkg.Files {
+			imports := fileImports(file)
+			ast.Inspect(file, func(n ast.Node) bool {
+				if spec, ok := n.(*ast.TypeSpec); ok {
+					if st, ok := spec.Type.(*ast.StructType); ok {
+						decls[spec.Name.Name] = structDecl{st: st, imports: imports}
+					}
+				}
+				return true
+			})
+		}
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one Go package in %s, found %d", opt.dir, len(pkgs))
+	}
+
+	if len(opt.schema) == 0 {
+		return nil, errors.New("a table schema is required")
+	}
+	schema, err := readSchema(opt.schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var structs []structType
+	for _, name := range opt.types {
+		decl, found := decls[name]
+		if !found {
+			return nil, fmt.Errorf("struct type %s not found in %s", name, opt.dir)
+		}
+		fields, err := structFields(decls, decl, "", "", opt.snakeCase)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if fields, err = orderBySchema(fields, schema); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		structs = append(structs, structType{
+			Name:   name,
+			Fields: fields,
+		})
+	}
+
+	var out bytes.Buffer
+	if err := structTpl.Execute(&out, map[string]any{
+		"Package": pkgName,
+		"Args":    opt.args,
+		"Structs": structs,
+	}); err != nil {
+		return nil, err
+	}
+	return format.Source(out.Bytes())
+}
+
+// structFields resolves the columns of st, later fields shadow earlier ones as in the driver's structMap
+func structFields(decls map[string]structDecl, decl structDecl, expr, prefix string, snakeCase bool) ([]field, error) {
+	var fields []field
+	add := func(f field) {
+		for i := range fields {
+			if fields[i].Column == f.Column {
+				fields[i] = f
+				return
+			}
+		}
+		fields = append(fields, f)
+	}
+	for _, f := range decl.st.Fields.List {
+		var tag string
+		if f.Tag != nil {
+			unquoted, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(unquoted).Get("ch")
+		}
+		chTag := structtag.Parse(tag)
+		if chTag.Name == "-" {
+			continue
+		}
+		if len(f.Names) == 0 {
+			// embedded struct, the driver also flattens pointers which the generated methods do not support
+			if star, ok := f.Type.(*ast.StarExpr); ok {
+				if ident, ok := star.X.(*ast.Ident); ok && decls[ident.Name].st != nil {
+					return nil, fmt.Errorf("embedded *%s: pointers to structs are not supported, embed %s by value", ident.Name, ident.Name)
+				}
+				continue
+			}
+			ident, ok := f.Type.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			nested, found := decls[ident.Name]
+			if !found {
+				continue
+			}
+			sub, err := structFields(decls, nested, expr+ident.Name+".", prefix, snakeCase)
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range sub {
+				add(s)
+			}
+			continue
+		}
+		for _, n := range f.Names {
+			if !n.IsExported() {
+				continue
+			}
+			if chTag.HasPrefix {
+				ident, ok := f.Type.(*ast.Ident)
+				if !ok || decls[ident.Name].st == nil {
+					return nil, fmt.Errorf("field %s: prefix requires a struct type declared in the same package, not a pointer", n.Name)
+				}
+				sub, err := structFields(decls, decls[ident.Name], expr+n.Name+".", prefix+chTag.Prefix, snakeCase)
+				if err != nil {
+					return nil, err
+				}
+				for _, s := range sub {
+					add(s)
+				}
+				continue
+			}
+			column := chTag.Name
+			switch {
+			case len(column) != 0:
+			case snakeCase:
+				column = structtag.SnakeCase(n.Name)
+			default:
+				column = n.Name
+			}
+			add(field{Column: prefix + column, Expr: expr + n.Name, GoType: goType(f.Type, decl.imports)})
+		}
+	}
+	return fields, nil
+}
+
+// orderBySchema sorts fields in schema column order, checks that every column is mapped and
+// resolves the column types
+func orderBySchema(fields []field, schema []schemaColumn) ([]field, error) {
+	var (
+		ordered = make([]field, 0, len(schema))
+		missing []string
+	)
+	for _, column := range schema {
+		idx := -1
+		for i, f := range fields {
+			if f.Column == column.Name {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			missing = append(missing, column.Name)
+			continue
+		}
+		t, err := chtype.Parse(column.Type)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", column.Name, err)
+		}
+		f := fields[idx]
+		f.Type = t.String()
+		if typed, ok := typedColumns[t.Name]; ok && typed.goType == f.GoType {
+			f.TypedColumn = typed.column
+		}
+		ordered = append(ordered, f)
+	}
+	if len(missing) != 0 {
+		return nil, fmt.Errorf("no field for columns %s", strings.Join(missing, ", "))
+	}
+	return ordered, nil
+}
+
+func readSchema(name string) ([]schemaColumn, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var (
+		columns []schemaColumn
+		scanner = bufio.NewScanner(f)
+	)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		column, typ, _ := strings.Cut(strings.Replace(line, "\t", " ", 1), " ")
+		// DESCRIBE TABLE output has further tab separated fields after the type
+		typ, _, _ = strings.Cut(strings.TrimSpace(typ), "\t")
+		if len(typ) == 0 {
+			return nil, fmt.Errorf("%s: missing type of column %s", name, column)
+		}
+		columns = append(columns, schemaColumn{Name: strings.Trim(column, "`"), Type: typ})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("empty schema " + name)
+	}
+	return columns, nil
+}
+
+// fileImports returns the import paths of file by package name
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string, len(file.Imports))
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imports[name] = path
+	}
+	return imports
+}
+
+// goType formats the type of a field with package selectors replaced by their import path
+func goType(expr ast.Expr, imports map[string]string) string {
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if pkg, ok := sel.X.(*ast.Ident); ok {
+			if path, ok := imports[pkg.Name]; ok {
+				return path + "." + sel.Sel.Name
+			}
+		}
+	}
+	return types.ExprString(expr)
+}
@@ -0,0 +1,131 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSource = `package events
+
+type Address struct {
+	City string
+	Zip  string ` + "`ch:\"zip\"`" + `
+}
+
+type Base struct {
+	ID uint64
+}
+
+type Event struct {
+	Base
+	UserName string
+	Home     Address ` + "`ch:\",prefix=home_\"`" + `
+	Ignored  string  ` + "`ch:\"-\"`" + `
+	hidden   string
+}
+`
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "events.go"), []byte(testSource), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "events.schema"), []byte("home_city\tString\nid\tUInt64\tDEFAULT\t42\nuser_name\tLowCardinality(String)\n"), 0o644))
+
+	_, err := generate(options{dir: dir, types: []string{"Event"}})
+	assert.ErrorContains(t, err, "a table schema is required")
+
+	_, err = generate(options{dir: dir, types: []string{"Missing"}, schema: filepath.Join(dir, "events.schema")})
+	assert.Error(t, err)
+
+	_, err = generate(options{dir: dir, types: []string{"Event"}, schema: filepath.Join(dir, "events.schema")})
+	assert.ErrorContains(t, err, "no field for columns home_city")
+
+	out, err := generate(options{dir: dir, types: []string{"Event"}, snakeCase: true, schema: filepath.Join(dir, "events.schema")})
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "// Event columns: home_city String, id UInt64, user_name LowCardinality(String)")
+	assert.Contains(t, string(out), "func (v *Event) AppendTo(columns []column.Interface) error {")
+	assert.Contains(t, string(out), "func (v *Event) ScanFrom(columns []column.Interface, row int) error {")
+	// typed columns for matching Go types, AppendRow and ScanRow otherwise
+	assert.Contains(t, string(out), "c0, ok := columns[0].(*column.String)")
+	assert.Contains(t, string(out), "c0.AppendValue(v.Home.City)")
+	assert.Contains(t, string(out), "v.Base.ID = c1.Value(row)")
+	assert.Contains(t, string(out), "c2.AppendRow(v.UserName)")
+	assert.Contains(t, string(out), "c2.ScanRow(&v.UserName, row)")
+	assert.NotContains(t, string(out), "Ignored")
+	assert.NotContains(t, string(out), "hidden")
+}
+
+func TestStructFields(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "events.go"), []byte(testSource), 0o644))
+	for _, snakeCase := range []bool{false, true} {
+		columns := []string{"ID", "UserName", "home_City", "home_zip"}
+		if snakeCase {
+			columns = []string{"id", "user_name", "home_city", "home_zip"}
+		}
+		schema := filepath.Join(dir, "events.schema")
+		require.NoError(t, os.WriteFile(schema, []byte(strings.Join(columns, " String\n")+" String\n"), 0o644))
+
+		out, err := generate(options{dir: dir, types: []string{"Event"}, snakeCase: snakeCase, schema: schema})
+		require.NoError(t, err, "snake case %t", snakeCase)
+		for i, column := range columns {
+			assert.Contains(t, string(out), fmt.Sprintf("c%d.Name() != %q", i, column))
+		}
+		assert.Contains(t, string(out), "c2.AppendValue(v.Home.City)")
+		assert.Contains(t, string(out), "c0.ScanRow(&v.Base.ID, row)")
+	}
+}
+
+func TestPointerStructs(t *testing.T) {
+	for source, expected := range map[string]string{
+		"type Event struct {\n\t*Base\n\tUserName string\n}\n":                                "embedded *Base: pointers to structs are not supported",
+		"type Event struct {\n\tHome *Address `ch:\",prefix=home_\"`\n\tUserName string\n}\n": "field Home: prefix requires a struct type declared in the same package, not a pointer",
+	} {
+		dir := t.TempDir()
+		source = "package events\n\ntype Base struct {\n\tID uint64\n}\n\ntype Address struct {\n\tCity string\n}\n\n" + source
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "events.go"), []byte(source), 0o644))
+		schema := filepath.Join(dir, "events.schema")
+		require.NoError(t, os.WriteFile(schema, []byte("UserName String\n"), 0o644))
+		_, err := generate(options{dir: dir, types: []string{"Event"}, schema: schema})
+		assert.ErrorContains(t, err, expected)
+	}
+}
+
+// TestGenerated checks that the generated code in internal/structgentest, which is compiled and
+// tested there, is up to date
+func TestGenerated(t *testing.T) {
+	dir := filepath.Join("..", "..", "internal", "structgentest")
+	out, err := generate(options{
+		dir:       dir,
+		types:     []string{"Event"},
+		schema:    filepath.Join(dir, "event.schema"),
+		snakeCase: true,
+		args:      "-type Event -schema event.schema -snake",
+	})
+	require.NoError(t, err)
+	generated, err := os.ReadFile(filepath.Join(dir, "event_ch.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(generated), string(out), "run go generate ./internal/structgentest")
+}
@@ -0,0 +1,165 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"iter"
+	"reflect"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// typedStructMap caches struct field mappings per type for QueryAsDB and SelectAsDB, and for the
+// rows of driver.Conn implementations other than this package
+var typedStructMap = &structMap{}
+
+// DBQueryer is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type DBQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// typedRows is the subset of driver.Rows and *sql.Rows used to decode rows into T.
+type typedRows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+	Close() error
+}
+
+// QueryAs runs query and yields each row decoded into T.
+// A struct T is mapped by column name as with ScanStruct, unless the result has a single
+// column that does not map to any field, in which case the column is scanned into T directly
+// (for example a Tuple into a struct, or a DateTime into time.Time). Any other T receives
+// the single column of the result. Fields are mapped with the NameMapper and TolerantStructMapping
+// options of the connection.
+// Iteration stops after the first error, the rows are closed when the loop exits.
+func QueryAs[T any](ctx context.Context, conn driver.Conn, query string, args ...any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		r, err := conn.Query(ctx, query, args...)
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		m := typedStructMap
+		if r, ok := r.(*rows); ok && r.structMap != nil {
+			m = r.structMap
+		}
+		columns := r.Columns()
+		var tuple bool
+		if types := r.ColumnTypes(); len(types) == 1 {
+			tuple = strings.HasPrefix(types[0].DatabaseTypeName(), "Tuple(")
+		}
+		yieldTypedRows(r, m, "QueryAs", columns, tuple, yield)
+	}
+}
+
+// SelectAs runs query and returns all rows decoded into T, see QueryAs.
+func SelectAs[T any](ctx context.Context, conn driver.Conn, query string, args ...any) ([]T, error) {
+	return collectTyped(QueryAs[T](ctx, conn, query, args...))
+}
+
+// QueryAsDB is QueryAs for database/sql. Fields are mapped by their ch tag or name, as
+// database/sql does not expose the options of the connection.
+func QueryAsDB[T any](ctx context.Context, db DBQueryer, query string, args ...any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		columns, err := rows.Columns()
+		if err != nil {
+			rows.Close()
+			var zero T
+			yield(zero, err)
+			return
+		}
+		var tuple bool
+		if types, err := rows.ColumnTypes(); err == nil && len(types) == 1 {
+			tuple = strings.HasPrefix(types[0].DatabaseTypeName(), "Tuple(")
+		}
+		yieldTypedRows(rows, typedStructMap, "QueryAsDB", columns, tuple, yield)
+	}
+}
+
+// SelectAsDB is SelectAs for database/sql.
+func SelectAsDB[T any](ctx context.Context, db DBQueryer, query string, args ...any) ([]T, error) {
+	return collectTyped(QueryAsDB[T](ctx, db, query, args...))
+}
+
+func yieldTypedRows[T any](rows typedRows, m *structMap, op string, columns []string, tuple bool, yield func(T, error) bool) {
+	defer rows.Close()
+
+	var direct bool
+	switch t := reflect.TypeFor[T](); {
+	case t.Kind() != reflect.Struct:
+		direct = true
+	case len(columns) == 1 && tuple:
+		direct = true
+	case len(columns) == 1:
+		// struct types such as time.Time or decimal.Decimal are scanned directly when the column is not one of their fields
+		_, direct = m.index(t)[columns[0]]
+		direct = !direct
+	}
+
+	for rows.Next() {
+		var (
+			v    T
+			dest = []any{&v}
+		)
+		if !direct {
+			var err error
+			if dest, err = m.Map(op, columns, &v, true); err != nil {
+				yield(v, err)
+				return
+			}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			yield(v, err)
+			return
+		}
+		if !yield(v, nil) {
+			return
+		}
+	}
+	if err := rows.Close(); err != nil {
+		var zero T
+		yield(zero, err)
+		return
+	}
+	if err := rows.Err(); err != nil {
+		var zero T
+		yield(zero, err)
+	}
+}
+
+func collectTyped[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var result []T
+	for v, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
@@ -0,0 +1,130 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type queryAsTestConn struct {
+	driver.Conn
+	block     *proto.Block
+	structMap *structMap
+	err       error
+}
+
+func (c *queryAsTestConn) Query(context.Context, string, ...any) (driver.Rows, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	m := c.structMap
+	if m == nil {
+		m = &structMap{}
+	}
+	return &rows{
+		block:     c.block,
+		columns:   c.block.ColumnsNames(),
+		structMap: m,
+	}, nil
+}
+
+func TestSelectAs(t *testing.T) {
+	block := &proto.Block{}
+	require.NoError(t, block.AddColumn("id", "UInt64"))
+	require.NoError(t, block.AddColumn("name", "String"))
+	require.NoError(t, block.Append(uint64(1), "a"))
+	require.NoError(t, block.Append(uint64(2), "b"))
+	conn := &queryAsTestConn{block: block}
+
+	type row struct {
+		ID   uint64 `ch:"id"`
+		Name string `ch:"name"`
+	}
+	result, err := SelectAs[row](context.Background(), conn, "SELECT id, name FROM t")
+	require.NoError(t, err)
+	assert.Equal(t, []row{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}, result)
+
+	type missing struct {
+		ID uint64 `ch:"id"`
+	}
+	_, err = SelectAs[missing](context.Background(), conn, "SELECT id, name FROM t")
+	assert.Error(t, err)
+
+	conn.err = errors.New("query failed")
+	_, err = SelectAs[row](context.Background(), conn, "SELECT id, name FROM t")
+	assert.ErrorIs(t, err, conn.err)
+}
+
+func TestSelectAsConnStructMapping(t *testing.T) {
+	block := &proto.Block{}
+	require.NoError(t, block.AddColumn("user_id", "UInt64"))
+	require.NoError(t, block.AddColumn("extra", "String"))
+	require.NoError(t, block.Append(uint64(1), "a"))
+	conn := &queryAsTestConn{
+		block:     block,
+		structMap: newStructMap(&Options{NameMapper: SnakeCaseNameMapper, TolerantStructMapping: true}),
+	}
+
+	type row struct {
+		UserID uint64
+	}
+	result, err := SelectAs[row](context.Background(), conn, "SELECT user_id, extra FROM t")
+	require.NoError(t, err)
+	assert.Equal(t, []row{{UserID: 1}}, result)
+}
+
+func TestQueryAsScalar(t *testing.T) {
+	block := &proto.Block{}
+	require.NoError(t, block.AddColumn("ts", "DateTime('UTC')"))
+	now := time.Unix(time.Now().Unix(), 0).UTC()
+	require.NoError(t, block.Append(now))
+	require.NoError(t, block.Append(now.Add(time.Second)))
+	conn := &queryAsTestConn{block: block}
+
+	var times []time.Time
+	for v, err := range QueryAs[time.Time](context.Background(), conn, "SELECT ts FROM t") {
+		require.NoError(t, err)
+		times = append(times, v)
+		break
+	}
+	require.Len(t, times, 1)
+	assert.True(t, now.Equal(times[0]))
+}
+
+func TestQueryAsTuple(t *testing.T) {
+	block := &proto.Block{}
+	require.NoError(t, block.AddColumn("t", "Tuple(a String, b Int64)"))
+	require.NoError(t, block.Append(map[string]any{"a": "x", "b": int64(1)}))
+	conn := &queryAsTestConn{block: block}
+
+	type tuple struct {
+		A string `ch:"a"`
+		B int64  `ch:"b"`
+	}
+	result, err := SelectAs[tuple](context.Background(), conn, "SELECT t FROM t")
+	require.NoError(t, err)
+	assert.Equal(t, []tuple{{A: "x", B: 1}}, result)
+}
@@ -87,6 +87,9 @@ func scan(block *proto.Block, row int, dest ...any) error {
 		}
 	}
 	for i, d := range dest {
+		if d == discard {
+			continue
+		}
 		if err := columns[i].ScanRow(d, row-1); err != nil {
 			return &OpError{
 				Err:        err,
@@ -20,14 +20,62 @@ package clickhouse
 import (
 	"fmt"
 	"reflect"
+	"slices"
 	"sync"
+
+	"github.com/ClickHouse/clickhouse-go/v2/internal/structtag"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
 )
 
+// NameMapper maps the name of a Go struct field without a ch tag to a column name.
+type NameMapper func(field string) string
+
+// SnakeCaseNameMapper maps field names such as UserID to user_id.
+func SnakeCaseNameMapper(field string) string {
+	return structtag.SnakeCase(field)
+}
+
 type structMap struct {
-	cache sync.Map
+	cache      sync.Map
+	nameMapper NameMapper
+	// tolerant skips result columns without a matching field when scanning,
+	// and appends the zero value of the column for INSERT columns without a matching field
+	tolerant bool
+}
+
+func newStructMap(opt *Options) *structMap {
+	return &structMap{
+		nameMapper: opt.NameMapper,
+		tolerant:   opt.TolerantStructMapping,
+	}
 }
 
+// discardDest is the scan destination of result columns ignored by a tolerant structMap
+type discardDest struct{}
+
+var discard = &discardDest{}
+
 func (m *structMap) Map(op string, columns []string, s any, ptr bool) ([]any, error) {
+	return m.mapStruct(op, columns, s, ptr, func(int) any {
+		if ptr {
+			return discard
+		}
+		return nil
+	})
+}
+
+// appendValues maps s to a row of block. Columns without a matching field, allowed by a
+// tolerant structMap, get the default value of their type, e.g. an empty Array or Map.
+func (m *structMap) appendValues(block *proto.Block, s any) ([]any, error) {
+	return m.mapStruct("AppendStruct", block.ColumnsNames(), s, false, func(i int) any {
+		return column.Zero(block.Columns[i])
+	})
+}
+
+// mapStruct maps s to the values of columns, missing returns the value of the i-th
+// column if it has no matching field and the mapping is tolerant
+func (m *structMap) mapStruct(op string, columns []string, s any, ptr bool, missing func(i int) any) ([]any, error) {
 	v := reflect.ValueOf(s)
 	if v.Kind() != reflect.Ptr {
 		return nil, &OpError{
@@ -53,26 +101,29 @@ func (m *structMap) Map(op string, columns []string, s any, ptr bool) ([]any, er
 	}
 
 	var (
-		index  map[string][]int
+		index  = m.index(t)
 		values = make([]any, 0, len(columns))
 	)
-
-	switch idx, found := m.cache.Load(t); {
-	case found:
-		index = idx.(map[string][]int)
-	default:
-		index = structIdx(t)
-		m.cache.Store(t, index)
-	}
-	for _, name := range columns {
+	for i, name := range columns {
 		idx, found := index[name]
+		if !found && m.tolerant {
+			values = append(values, missing(i))
+			continue
+		}
 		if !found {
 			return nil, &OpError{
 				Op:  op,
 				Err: fmt.Errorf("missing destination name %q in %T", name, s),
 			}
 		}
-		switch field := v.FieldByIndex(idx); {
+		field, err := fieldByIndex(v, idx, ptr)
+		if err != nil {
+			return nil, &OpError{
+				Op:  op,
+				Err: fmt.Errorf("field of column %q in %T: %w", name, s, err),
+			}
+		}
+		switch {
 		case ptr:
 			values = append(values, field.Addr().Interface())
 		default:
@@ -82,30 +133,121 @@ func (m *structMap) Map(op string, columns []string, s any, ptr bool) ([]any, er
 	return values, nil
 }
 
+// fieldByIndex returns the nested field of v at index. Nil struct pointers on the way are
+// allocated if alloc is set, otherwise the zero value of the field is returned.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Zero(v.Type().Elem().FieldByIndex(index[i:]).Type), nil
+				}
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// index returns the cached field index of struct type t
+func (m *structMap) index(t reflect.Type) map[string][]int {
+	if idx, found := m.cache.Load(t); found {
+		return idx.(map[string][]int)
+	}
+	index := structIdxMapped(t, m.nameMapper)
+	m.cache.Store(t, index)
+	return index
+}
+
+// indexOf returns the field index of s if it is a pointer to a struct
+func (m *structMap) indexOf(s any) (map[string][]int, bool) {
+	t := reflect.TypeOf(s)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, false
+	}
+	return m.index(t.Elem()), true
+}
+
 func structIdx(t reflect.Type) map[string][]int {
+	return structIdxMapped(t, nil)
+}
+
+func structIdxMapped(t reflect.Type, nameMapper NameMapper) map[string][]int {
 	fields := make(map[string][]int)
+	for _, f := range structColumns(t, nameMapper) {
+		fields[f.name] = f.index
+	}
+	return fields
+}
+
+// structColumn is a struct field mapped to a column
+type structColumn struct {
+	name  string
+	index []int
+	field reflect.StructField
+	tag   structtag.Tag
+}
+
+// structColumns returns the columns of struct type t in field order. A column name may
+// appear more than once, the last occurrence takes precedence. Embedded structs and fields
+// tagged with a prefix are flattened, also through pointers.
+func structColumns(t reflect.Type, nameMapper NameMapper) []structColumn {
+	return structColumnsOf(t, nameMapper, nil)
+}
+
+// structColumnsOf returns the columns of t, parents are the struct types t is nested in
+func structColumnsOf(t reflect.Type, nameMapper NameMapper, parents []reflect.Type) []structColumn {
+	parents = append(parents, t)
+	var columns []structColumn
 	for i := 0; i < t.NumField(); i++ {
 		var (
 			f    = t.Field(i)
 			name = f.Name
+			tag  = structtag.Parse(f.Tag.Get("ch"))
 		)
-		if tn := f.Tag.Get("ch"); len(tn) != 0 {
-			name = tn
+		switch {
+		case len(tag.Name) != 0:
+			name = tag.Name
+		case nameMapper != nil:
+			name = nameMapper(name)
 		}
 		switch {
 		case name == "-", len(f.PkgPath) != 0 && !f.Anonymous:
 			continue
 		}
+		nested := f.Type
+		if nested.Kind() == reflect.Ptr {
+			nested = nested.Elem()
+		}
 		switch {
-		case f.Anonymous:
-			if f.Type.Kind() != reflect.Ptr {
-				for k, idx := range structIdx(f.Type) {
-					fields[k] = append(f.Index, idx...)
-				}
+		case (f.Anonymous || tag.HasPrefix) && nested.Kind() == reflect.Struct && slices.Contains(parents, nested):
+			// a struct embedding a pointer to itself would be flattened endlessly
+			continue
+		case f.Anonymous && nested.Kind() == reflect.Struct:
+			for _, c := range structColumnsOf(nested, nameMapper, parents) {
+				c.index = append(f.Index[:len(f.Index):len(f.Index)], c.index...)
+				columns = append(columns, c)
+			}
+		case f.Anonymous && f.Type.Kind() == reflect.Ptr:
+		case tag.HasPrefix && nested.Kind() == reflect.Struct:
+			// flatten a named nested struct, its columns are prefixed
+			for _, c := range structColumnsOf(nested, nameMapper, parents) {
+				c.name, c.index = tag.Prefix+c.name, append(f.Index[:len(f.Index):len(f.Index)], c.index...)
+				columns = append(columns, c)
 			}
 		default:
-			fields[name] = f.Index
+			columns = append(columns, structColumn{
+				name:  name,
+				index: f.Index,
+				field: f,
+				tag:   tag,
+			})
 		}
 	}
-	return fields
+	return columns
 }
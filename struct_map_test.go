@@ -22,7 +22,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStructIdx(t *testing.T) {
@@ -48,7 +51,7 @@ func TestStructIdx(t *testing.T) {
 		"Col2":   {1},
 		"ColPtr": {2},
 		"named":  {3, 0},
-		"Col6":   {3, 1, 0},
+		"Col6":   {4, 0}, // *Embed2 comes after Embed and takes precedence
 	}, index)
 }
 
@@ -110,3 +113,146 @@ func BenchmarkStructMap(b *testing.B) {
 		}
 	}
 }
+
+func TestStructIdxPrefix(t *testing.T) {
+	type Address struct {
+		City string
+		Zip  string `ch:"zip_code"`
+	}
+	type Example struct {
+		ID      uint64  `ch:"id"`
+		Home    Address `ch:",prefix=home_"`
+		Work    Address `ch:"ignored,prefix=work_"`
+		Ignored Address
+	}
+	assert.Equal(t, map[string][]int{
+		"id":            {0},
+		"home_City":     {1, 0},
+		"home_zip_code": {1, 1},
+		"work_City":     {2, 0},
+		"work_zip_code": {2, 1},
+		"Ignored":       {3},
+	}, structIdx(reflect.TypeOf(Example{})))
+}
+
+func TestSnakeCaseNameMapper(t *testing.T) {
+	for in, expected := range map[string]string{
+		"Col1":       "col1",
+		"UserID":     "user_id",
+		"HTTPServer": "http_server",
+		"createdAt":  "created_at",
+		"A":          "a",
+	} {
+		assert.Equal(t, expected, SnakeCaseNameMapper(in), in)
+	}
+}
+
+func TestMapperNameMapper(t *testing.T) {
+	type Example struct {
+		UserID    uint64
+		CreatedAt time.Time
+		Other     string `ch:"Other"`
+	}
+	mapper := newStructMap(&Options{NameMapper: SnakeCaseNameMapper})
+	v := Example{UserID: 42, Other: "x"}
+	values, err := mapper.Map("AppendStruct", []string{"user_id", "Other"}, &v, false)
+	require.NoError(t, err)
+	assert.Equal(t, []any{uint64(42), "x"}, values)
+}
+
+func TestMapperTolerant(t *testing.T) {
+	type Example struct {
+		Col1 string
+		Col2 string
+	}
+	var v Example
+	_, err := (&structMap{}).Map("ScanStruct", []string{"Col1", "Extra"}, &v, true)
+	assert.Error(t, err)
+
+	mapper := newStructMap(&Options{TolerantStructMapping: true})
+	values, err := mapper.Map("ScanStruct", []string{"Col1", "Extra"}, &v, true)
+	require.NoError(t, err)
+	assert.Equal(t, []any{&v.Col1, discard}, values)
+
+	values, err = mapper.Map("AppendStruct", []string{"Col2", "Extra"}, &Example{Col2: "y"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"y", nil}, values)
+}
+
+func TestMapperTolerantZeroValues(t *testing.T) {
+	type Example struct {
+		Col1 string
+	}
+	block := &proto.Block{}
+	for _, c := range [][2]string{
+		{"Col1", "String"},
+		{"arr", "Array(String)"},
+		{"map", "Map(String, UInt64)"},
+		{"nullable", "Nullable(String)"},
+		{"tuple", "Tuple(a String, b UInt8)"},
+		{"enum", "Enum8('b' = 2, 'a' = 1)"},
+	} {
+		require.NoError(t, block.AddColumn(c[0], column.Type(c[1])))
+	}
+	mapper := newStructMap(&Options{TolerantStructMapping: true})
+	values, err := mapper.appendValues(block, &Example{Col1: "x"})
+	require.NoError(t, err)
+	require.NoError(t, block.Append(values...))
+	assert.Equal(t, 1, block.Rows())
+	assert.Equal(t, "x", block.Columns[0].Row(0, false))
+	assert.Empty(t, block.Columns[1].Row(0, false))
+	assert.Empty(t, block.Columns[2].Row(0, false))
+	assert.Nil(t, block.Columns[3].Row(0, true))
+	assert.Equal(t, map[string]any{"a": "", "b": uint8(0)}, block.Columns[4].Row(0, false))
+	assert.Equal(t, "a", block.Columns[5].Row(0, false))
+}
+
+func TestStructColumnsPointers(t *testing.T) {
+	type Meta struct {
+		Source string
+	}
+	type Address struct {
+		City string
+	}
+	type Event struct {
+		ID uint64
+		*Meta
+		Home *Address `ch:",prefix=home_"`
+	}
+	assert.Equal(t, map[string][]int{
+		"ID":        {0},
+		"Source":    {1, 0},
+		"home_City": {2, 0},
+	}, structIdx(reflect.TypeOf(Event{})))
+
+	mapper := newStructMap(&Options{})
+	columns := []string{"ID", "Source", "home_City"}
+	values, err := mapper.Map("AppendStruct", columns, &Event{ID: 1}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []any{uint64(1), "", ""}, values)
+
+	var v Event
+	values, err = mapper.Map("ScanStruct", columns, &v, true)
+	require.NoError(t, err)
+	*values[1].(*string), *values[2].(*string) = "web", "Berlin"
+	assert.Equal(t, "web", v.Meta.Source)
+	assert.Equal(t, "Berlin", v.Home.City)
+
+	type meta struct {
+		Source string
+	}
+	type Unexported struct {
+		*meta
+	}
+	_, err = mapper.Map("ScanStruct", []string{"Source"}, &Unexported{}, true)
+	assert.ErrorContains(t, err, "cannot set embedded pointer to unexported struct")
+	values, err = mapper.Map("AppendStruct", []string{"Source"}, &Unexported{}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []any{""}, values)
+
+	type Node struct {
+		Name string
+		*Node
+	}
+	assert.Equal(t, map[string][]int{"Name": {0}}, structIdx(reflect.TypeOf(Node{})))
+}
@@ -0,0 +1,136 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// StructValidationError lists every mismatch found between a struct and the columns of a batch,
+// see driver.WithStructValidation.
+type StructValidationError struct {
+	Struct   string
+	Problems []string
+}
+
+func (e *StructValidationError) Error() string {
+	return fmt.Sprintf("clickhouse: struct %s does not match the batch columns:\n\t%s", e.Struct, strings.Join(e.Problems, "\n\t"))
+}
+
+// validateBatchStruct checks that AppendStruct can append v to the columns of batch: every column
+// needs a field, unless the mapping is tolerant or the column is left to its default, and every
+// field type must be convertible to its column type.
+func validateBatchStruct(m *structMap, batch driver.Batch, v any, opts driver.PrepareBatchOptions) error {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return &OpError{
+			Op:  "PrepareBatch",
+			Err: fmt.Errorf("struct validation expects a struct, got %T", v),
+		}
+	}
+	var (
+		fields       = make(map[string]structColumn)
+		problems     []string
+		tableColumns []TableColumn
+	)
+	if schema, ok := batch.(driver.SchemaBatch); ok {
+		tableColumns = schema.TableColumns()
+	}
+	for _, c := range structColumns(t, m.nameMapper) {
+		fields[c.name] = c
+	}
+	for _, col := range batch.Columns() {
+		field, found := fields[col.Name()]
+		if !found {
+			if m.tolerant || (opts.OmitDefaults && omitsDefault(tableColumns, col.Name())) {
+				continue
+			}
+			problems = append(problems, fmt.Sprintf("column %q (%s): no matching struct field", col.Name(), col.Type()))
+			continue
+		}
+		if err := probeColumnType(col, field.field.Type); err != nil {
+			problems = append(problems, fmt.Sprintf("column %q (%s): field %s of type %s: %s", col.Name(), col.Type(), field.field.Name, field.field.Type, err))
+		}
+	}
+	if len(problems) != 0 {
+		return &StructValidationError{
+			Struct:   t.String(),
+			Problems: problems,
+		}
+	}
+	return nil
+}
+
+func omitsDefault(tableColumns []TableColumn, name string) bool {
+	return slices.ContainsFunc(tableColumns, func(c TableColumn) bool {
+		return c.Name == name && hasDefault(c)
+	})
+}
+
+// probeColumnType appends the zero value of t to an empty column of the type of col. Only
+// conversion errors are reported, the zero value itself may not be a valid value of the column.
+func probeColumnType(col column.Interface, t reflect.Type) (err error) {
+	probe, cerr := col.Type().Column(col.Name(), time.UTC)
+	if cerr != nil {
+		// the column type is known to the batch, there is nothing to probe otherwise
+		return nil
+	}
+	value := probeValue(t)
+	defer func() {
+		if recover() != nil {
+			err = nil
+		}
+	}()
+	var converter *column.ColumnConverterError
+	if err := probe.AppendRow(value.Interface()); errors.As(err, &converter) {
+		return err
+	}
+	return nil
+}
+
+// probeValue returns the zero value of t, with non-nil pointers and one element slices and maps
+// so that the element types get converted too
+func probeValue(t reflect.Type) reflect.Value {
+	switch t.Kind() {
+	case reflect.Ptr:
+		v := reflect.New(t.Elem())
+		v.Elem().Set(probeValue(t.Elem()))
+		return v
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		return reflect.Append(reflect.MakeSlice(t, 0, 1), probeValue(t.Elem()))
+	case reflect.Map:
+		v := reflect.MakeMapWithSize(t, 1)
+		v.SetMapIndex(probeValue(t.Key()), probeValue(t.Elem()))
+		return v
+	}
+	return reflect.Zero(t)
+}
@@ -0,0 +1,91 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBatchStruct(t *testing.T) {
+	var block proto.Block
+	for _, c := range [][2]string{
+		{"id", "UInt64"},
+		{"name", "String"},
+		{"ts", "DateTime"},
+		{"tags", "Array(String)"},
+		{"score", "Nullable(Float64)"},
+		{"kind", "Enum8('a' = 1, 'b' = 2)"},
+		{"created", "DateTime"},
+	} {
+		require.NoError(t, block.AddColumn(c[0], column.Type(c[1])))
+	}
+	batch := &httpBatch{
+		block: &block,
+		tableColumns: []TableColumn{
+			{Name: "created", Type: "DateTime", DefaultKind: "DEFAULT", DefaultExpression: "now()"},
+		},
+	}
+
+	type valid struct {
+		ID      uint64    `ch:"id"`
+		Name    string    `ch:"name"`
+		TS      time.Time `ch:"ts"`
+		Tags    []string  `ch:"tags"`
+		Score   *float64  `ch:"score"`
+		Kind    string    `ch:"kind"`
+		Created time.Time `ch:"created"`
+	}
+	m := &structMap{}
+	assert.NoError(t, validateBatchStruct(m, batch, valid{}, driver.PrepareBatchOptions{}))
+	assert.NoError(t, validateBatchStruct(m, batch, &valid{}, driver.PrepareBatchOptions{}))
+
+	type drifted struct {
+		ID    string     `ch:"id"`
+		Name  string     `ch:"name"`
+		TS    time.Time  `ch:"ts"`
+		Tags  []int      `ch:"tags"`
+		Score *float64   `ch:"score"`
+		Extra complex128 `ch:"extra"`
+	}
+	err := validateBatchStruct(m, batch, drifted{}, driver.PrepareBatchOptions{})
+	var verr *StructValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "clickhouse.drifted", verr.Struct)
+	require.Len(t, verr.Problems, 4)
+	assert.Contains(t, verr.Problems[0], `column "id" (UInt64): field ID of type string`)
+	assert.Contains(t, verr.Problems[1], `column "tags" (Array(String)): field Tags of type []int`)
+	assert.Equal(t, `column "kind" (Enum8('a' = 1, 'b' = 2)): no matching struct field`, verr.Problems[2])
+	assert.Equal(t, `column "created" (DateTime): no matching struct field`, verr.Problems[3])
+
+	err = validateBatchStruct(m, batch, drifted{}, driver.PrepareBatchOptions{OmitDefaults: true})
+	require.ErrorAs(t, err, &verr)
+	assert.Len(t, verr.Problems, 3, "created is left to its default")
+
+	err = validateBatchStruct(&structMap{tolerant: true}, batch, drifted{}, driver.PrepareBatchOptions{})
+	require.ErrorAs(t, err, &verr)
+	assert.Len(t, verr.Problems, 2, "tolerant mapping allows missing fields")
+
+	assert.Error(t, validateBatchStruct(m, batch, 1, driver.PrepareBatchOptions{}))
+}
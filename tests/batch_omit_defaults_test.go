@@ -0,0 +1,74 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchOmitDefaults(t *testing.T) {
+	TestProtocols(t, func(t *testing.T, protocol clickhouse.Protocol) {
+		conn, err := GetNativeConnection(t, protocol, nil, nil, nil)
+		require.NoError(t, err)
+		ctx := context.Background()
+
+		require.NoError(t, conn.Exec(ctx, `
+			CREATE TABLE test_batch_omit_defaults (
+				id UInt64,
+				label String DEFAULT concat('id-', toString(id)),
+				note String
+			) ENGINE = MergeTree ORDER BY id
+		`))
+		defer func() {
+			_ = conn.Exec(ctx, "DROP TABLE IF EXISTS test_batch_omit_defaults")
+		}()
+
+		batch, err := conn.PrepareBatch(ctx, "INSERT INTO test_batch_omit_defaults", driver.WithOmitDefaults())
+		require.NoError(t, err)
+		var defaults []string
+		for _, c := range batch.(driver.SchemaBatch).TableColumns() {
+			if c.DefaultKind == "DEFAULT" {
+				defaults = append(defaults, c.Name)
+			}
+		}
+		assert.Equal(t, []string{"label"}, defaults)
+		require.NoError(t, batch.Append(uint64(1), "first"))
+		require.NoError(t, batch.Append(uint64(2), "second"))
+		require.Len(t, batch.Columns(), 2)
+		require.NoError(t, batch.Send())
+
+		type row struct {
+			ID   uint64 `ch:"id"`
+			Note string `ch:"note"`
+		}
+		batch, err = conn.PrepareBatch(ctx, "INSERT INTO test_batch_omit_defaults", driver.WithOmitDefaults())
+		require.NoError(t, err)
+		require.NoError(t, batch.AppendStruct(&row{ID: 3, Note: "third"}))
+		require.NoError(t, batch.Send())
+
+		var labels []string
+		require.NoError(t, conn.Select(ctx, &labels, "SELECT label FROM test_batch_omit_defaults ORDER BY id"))
+		assert.Equal(t, []string{"id-1", "id-2", "id-3"}, labels)
+	})
+}
@@ -0,0 +1,69 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchReplayBuffer(t *testing.T) {
+	TestProtocols(t, func(t *testing.T, protocol clickhouse.Protocol) {
+		conn, err := GetNativeConnection(t, protocol, nil, nil, nil)
+		require.NoError(t, err)
+		ctx := context.Background()
+
+		tableName := fmt.Sprintf("test_batch_replay_buffer_%s", protocol)
+		ddl := fmt.Sprintf(`
+			CREATE TABLE %s (
+				  Col1 UInt64
+				, Col2 String
+			) Engine MergeTree() ORDER BY tuple()
+			SETTINGS non_replicated_deduplication_window = 100
+			`, tableName)
+		defer func() {
+			dropTable(conn, tableName)
+		}()
+		require.NoError(t, conn.Exec(ctx, ddl))
+
+		insert := func() {
+			batch, err := conn.PrepareBatch(ctx, fmt.Sprintf("INSERT INTO %s", tableName),
+				driver.WithReplayBuffer(),
+				driver.WithDeduplicationToken(tableName),
+			)
+			require.NoError(t, err)
+			require.NoError(t, batch.Append(uint64(1), "first"))
+			require.NoError(t, batch.Flush())
+			require.NoError(t, batch.Append(uint64(2), "second"))
+			require.NoError(t, batch.Send())
+			require.ErrorIs(t, batch.Send(), clickhouse.ErrBatchAlreadySent)
+		}
+
+		insert()
+		require.Equal(t, uint64(2), getRowsCount(t, conn, tableName))
+
+		// same deduplication token, the server must drop the replayed rows
+		insert()
+		require.Equal(t, uint64(2), getRowsCount(t, conn, tableName))
+	})
+}
@@ -0,0 +1,66 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchStructValidation(t *testing.T) {
+	TestProtocols(t, func(t *testing.T, protocol clickhouse.Protocol) {
+		conn, err := GetNativeConnection(t, protocol, nil, nil, nil)
+		require.NoError(t, err)
+		ctx := context.Background()
+
+		require.NoError(t, conn.Exec(ctx, `
+			CREATE TABLE test_batch_struct_validation (
+				id UInt64,
+				name String,
+				tags Array(String)
+			) ENGINE = MergeTree ORDER BY id
+		`))
+		defer func() {
+			_ = conn.Exec(ctx, "DROP TABLE IF EXISTS test_batch_struct_validation")
+		}()
+
+		type valid struct {
+			ID   uint64   `ch:"id"`
+			Name string   `ch:"name"`
+			Tags []string `ch:"tags"`
+		}
+		batch, err := conn.PrepareBatch(ctx, "INSERT INTO test_batch_struct_validation", driver.WithStructValidation(valid{}))
+		require.NoError(t, err)
+		require.NoError(t, batch.AppendStruct(&valid{ID: 1, Name: "a", Tags: []string{"x"}}))
+		require.NoError(t, batch.Send())
+
+		type drifted struct {
+			ID   string `ch:"id"`
+			Tags []int  `ch:"tags"`
+		}
+		_, err = conn.PrepareBatch(ctx, "INSERT INTO test_batch_struct_validation", driver.WithStructValidation(drifted{}))
+		var verr *clickhouse.StructValidationError
+		require.ErrorAs(t, err, &verr)
+		assert.Len(t, verr.Problems, 3)
+	})
+}
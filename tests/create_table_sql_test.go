@@ -0,0 +1,65 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTableSQL(t *testing.T) {
+	TestProtocols(t, func(t *testing.T, protocol clickhouse.Protocol) {
+		conn, err := GetNativeConnection(t, protocol, nil, nil, nil)
+		require.NoError(t, err)
+		ctx := context.Background()
+
+		type event struct {
+			ID   uint64    `ch:"id"`
+			Ts   time.Time `ch:"ts,type=DateTime64(3, 'UTC')"`
+			Name *string   `ch:"name"`
+			Tags []string  `ch:"tags"`
+		}
+		ddl, err := clickhouse.CreateTableSQL[event](clickhouse.CreateTableOptions{
+			Table:   "test_create_table_sql",
+			OrderBy: []string{"id"},
+			Codecs:  map[string]string{"ts": "Delta, ZSTD"},
+		})
+		require.NoError(t, err)
+		require.NoError(t, conn.Exec(ctx, ddl))
+		defer func() {
+			_ = conn.Exec(ctx, "DROP TABLE IF EXISTS test_create_table_sql")
+		}()
+
+		name := "a"
+		expected := event{ID: 1, Ts: time.UnixMilli(1700000000123).UTC(), Name: &name, Tags: []string{"x", "y"}}
+		batch, err := conn.PrepareBatch(ctx, "INSERT INTO test_create_table_sql")
+		require.NoError(t, err)
+		require.NoError(t, batch.AppendStruct(&expected))
+		require.NoError(t, batch.Send())
+
+		var actual []event
+		require.NoError(t, conn.Select(ctx, &actual, "SELECT * FROM test_create_table_sql"))
+		require.Len(t, actual, 1)
+		assert.Equal(t, expected, actual[0])
+	})
+}
@@ -0,0 +1,69 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeTable(t *testing.T) {
+	TestProtocols(t, func(t *testing.T, protocol clickhouse.Protocol) {
+		conn, err := GetNativeConnection(t, protocol, nil, nil, nil)
+		require.NoError(t, err)
+		schema, ok := conn.(driver.SchemaConn)
+		require.True(t, ok)
+		ctx := context.Background()
+
+		require.NoError(t, conn.Exec(ctx, `
+			CREATE TABLE test_describe_table (
+				id UInt64 COMMENT 'identifier',
+				ts DateTime DEFAULT now() CODEC(Delta, ZSTD(1)) TTL ts + INTERVAL 1 DAY,
+				day Date MATERIALIZED toDate(ts),
+				alias String ALIAS toString(id)
+			) ENGINE = MergeTree ORDER BY id
+		`))
+		defer func() {
+			_ = conn.Exec(ctx, "DROP TABLE IF EXISTS test_describe_table")
+		}()
+
+		columns, err := schema.DescribeTable(ctx, "", "test_describe_table")
+		require.NoError(t, err)
+		require.Len(t, columns, 4)
+		assert.Equal(t, clickhouse.TableColumn{Name: "id", Type: "UInt64", Comment: "identifier"}, columns[0])
+		assert.Equal(t, "DEFAULT", columns[1].DefaultKind)
+		assert.Equal(t, "now()", columns[1].DefaultExpression)
+		assert.Contains(t, columns[1].CodecExpression, "ZSTD(1)")
+		assert.NotEmpty(t, columns[1].TTLExpression)
+		assert.Equal(t, "MATERIALIZED", columns[2].DefaultKind)
+		assert.Equal(t, "ALIAS", columns[3].DefaultKind)
+
+		require.NoError(t, conn.Exec(ctx, "ALTER TABLE test_describe_table ADD COLUMN extra String"))
+		columns, err = schema.DescribeTable(ctx, "", "test_describe_table")
+		require.NoError(t, err)
+		assert.Len(t, columns, 5)
+
+		_, err = schema.DescribeTable(ctx, "", "test_describe_table_missing")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,81 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPFormats(t *testing.T) {
+	for _, compression := range []clickhouse.CompressionMethod{clickhouse.CompressionNone, clickhouse.CompressionLZ4, clickhouse.CompressionZSTD} {
+		for _, format := range []clickhouse.HTTPFormat{clickhouse.HTTPFormatRowBinary, clickhouse.HTTPFormatRowBinaryWithNamesAndTypes} {
+			t.Run(compression.String()+"/"+string(format), func(t *testing.T) {
+				conn, err := GetNativeConnection(t, clickhouse.HTTP, nil, nil, &clickhouse.Compression{Method: compression})
+				require.NoError(t, err)
+				ctx := clickhouse.Context(context.Background(), clickhouse.WithHTTPFormat(format))
+
+				require.NoError(t, conn.Exec(ctx, `
+					CREATE TABLE test_http_format (
+						id UInt64,
+						name Nullable(String),
+						tags Array(LowCardinality(String)),
+						attributes Map(String, UInt32),
+						ts DateTime64(3, 'UTC')
+					) ENGINE = MergeTree ORDER BY id
+				`))
+				defer func() {
+					_ = conn.Exec(ctx, "DROP TABLE IF EXISTS test_http_format")
+				}()
+
+				ts := time.UnixMilli(1700000000123).UTC()
+				batch, err := conn.PrepareBatch(ctx, "INSERT INTO test_http_format")
+				require.NoError(t, err)
+				for i := 0; i < 1000; i++ {
+					var name *string
+					if i%2 == 0 {
+						value := "name"
+						name = &value
+					}
+					require.NoError(t, batch.Append(uint64(i), name, []string{"a", "b"}, map[string]uint32{"key": uint32(i)}, ts))
+				}
+				require.NoError(t, batch.Send())
+
+				var rows []struct {
+					ID         uint64            `ch:"id"`
+					Name       *string           `ch:"name"`
+					Tags       []string          `ch:"tags"`
+					Attributes map[string]uint32 `ch:"attributes"`
+					TS         time.Time         `ch:"ts"`
+				}
+				require.NoError(t, conn.Select(ctx, &rows, "SELECT * FROM test_http_format ORDER BY id"))
+				require.Len(t, rows, 1000)
+				assert.Equal(t, "name", *rows[998].Name)
+				assert.Nil(t, rows[999].Name)
+				assert.Equal(t, []string{"a", "b"}, rows[999].Tags)
+				assert.Equal(t, map[string]uint32{"key": 999}, rows[999].Attributes)
+				assert.Equal(t, ts, rows[999].TS)
+			})
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	chproto "github.com/ClickHouse/ch-go/proto"
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertFrom(t *testing.T) {
+	TestProtocols(t, func(t *testing.T, protocol clickhouse.Protocol) {
+		conn, err := GetNativeConnection(t, protocol, nil, nil, &clickhouse.Compression{
+			Method: clickhouse.CompressionLZ4,
+		})
+		require.NoError(t, err)
+		raw, ok := conn.(driver.RawConn)
+		require.True(t, ok)
+		ctx := context.Background()
+
+		tableName := fmt.Sprintf("test_insert_from_%s", protocol)
+		ddl := fmt.Sprintf(`
+			CREATE TABLE %s (
+				  Col1 UInt64
+				, Col2 String
+			) Engine MergeTree() ORDER BY tuple()
+			`, tableName)
+		defer func() {
+			dropTable(conn, tableName)
+		}()
+		require.NoError(t, conn.Exec(ctx, ddl))
+
+		csv := "Col1,Col2\n1,a\n2,b\n3,c\n"
+		if protocol == clickhouse.Native {
+			err = raw.InsertFrom(ctx, fmt.Sprintf("INSERT INTO %s FORMAT CSVWithNames", tableName), strings.NewReader(csv))
+			require.ErrorContains(t, err, "format CSVWithNames is not supported over the native protocol")
+
+			block := &proto.Block{}
+			require.NoError(t, block.AddColumn("Col1", "UInt64"))
+			require.NoError(t, block.AddColumn("Col2", "String"))
+			for i, s := range []string{"a", "b", "c"} {
+				require.NoError(t, block.Append(uint64(i+1), s))
+			}
+			var buffer chproto.Buffer
+			require.NoError(t, block.Encode(&buffer, 0))
+			require.NoError(t, raw.InsertFrom(ctx, fmt.Sprintf("INSERT INTO %s FORMAT Native", tableName), bytes.NewReader(buffer.Buf)))
+			require.Equal(t, uint64(3), getRowsCount(t, conn, tableName))
+			return
+		}
+		require.NoError(t, raw.InsertFrom(ctx, fmt.Sprintf("INSERT INTO %s FORMAT CSVWithNames", tableName), strings.NewReader(csv)))
+		require.Equal(t, uint64(3), getRowsCount(t, conn, tableName))
+
+		json := `{"Col1":4,"Col2":"d"}` + "\n" + `{"Col1":5,"Col2":"e"}` + "\n"
+		require.NoError(t, raw.InsertFrom(ctx, fmt.Sprintf("INSERT INTO %s FORMAT JSONEachRow", tableName), strings.NewReader(json)))
+		require.Equal(t, uint64(5), getRowsCount(t, conn, tableName))
+
+		err = raw.InsertFrom(ctx, fmt.Sprintf("INSERT INTO %s VALUES", tableName), strings.NewReader(csv))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,58 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryAs(t *testing.T) {
+	TestProtocols(t, func(t *testing.T, protocol clickhouse.Protocol) {
+		conn, err := GetNativeConnection(t, protocol, nil, nil, nil)
+		require.NoError(t, err)
+		ctx := context.Background()
+
+		type row struct {
+			Number uint64 `ch:"number"`
+			Str    string `ch:"str"`
+		}
+		rows, err := clickhouse.SelectAs[row](ctx, conn, "SELECT number, toString(number) AS str FROM system.numbers LIMIT 3")
+		require.NoError(t, err)
+		assert.Equal(t, []row{{0, "0"}, {1, "1"}, {2, "2"}}, rows)
+
+		var sum uint64
+		for v, err := range clickhouse.QueryAs[uint64](ctx, conn, "SELECT number FROM system.numbers LIMIT 100") {
+			require.NoError(t, err)
+			sum += v
+		}
+		assert.Equal(t, uint64(4950), sum)
+
+		type tuple struct {
+			A string `ch:"a"`
+			B int64  `ch:"b"`
+		}
+		tuples, err := clickhouse.SelectAs[tuple](ctx, conn, "SELECT CAST(('x', 1), 'Tuple(a String, b Int64)')")
+		require.NoError(t, err)
+		assert.Equal(t, []tuple{{"x", 1}}, tuples)
+	})
+}
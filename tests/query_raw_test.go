@@ -0,0 +1,75 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryRaw(t *testing.T) {
+	TestProtocols(t, func(t *testing.T, protocol clickhouse.Protocol) {
+		conn, err := GetNativeConnection(t, protocol, nil, nil, nil)
+		require.NoError(t, err)
+		raw, ok := conn.(driver.RawConn)
+		require.True(t, ok)
+		ctx := context.Background()
+
+		r, err := raw.QueryRaw(ctx, "SELECT number, toString(number) FROM system.numbers LIMIT 3 FORMAT CSV")
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		assert.Equal(t, "0,\"0\"\n1,\"1\"\n2,\"2\"\n", string(data))
+
+		r, err = raw.QueryRaw(ctx, "SELECT toUInt8(number) AS n FROM system.numbers LIMIT 2 FORMAT JSONEachRow")
+		require.NoError(t, err)
+		data, err = io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		assert.Equal(t, "{\"n\":0}\n{\"n\":1}\n", string(data))
+
+		// closing early must release the connection
+		r, err = raw.QueryRaw(ctx, "SELECT number FROM system.numbers LIMIT 10000000 FORMAT TabSeparated")
+		require.NoError(t, err)
+		_, err = r.Read(make([]byte, 16))
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		require.NoError(t, conn.Ping(ctx))
+
+		_, err = raw.QueryRaw(ctx, "SELECT 1")
+		require.Error(t, err)
+
+		r, err = raw.QueryRaw(ctx, "SELECT toUInt8(number) AS n FROM system.numbers LIMIT 2 FORMAT CSVWithNames")
+		if protocol == clickhouse.Native {
+			require.ErrorContains(t, err, "format CSVWithNames is not supported over the native protocol")
+			return
+		}
+		require.NoError(t, err)
+		data, err = io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		assert.Equal(t, "\"n\"\n0\n1\n", string(data))
+	})
+}
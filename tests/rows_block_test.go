@@ -0,0 +1,68 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowsNextBlock(t *testing.T) {
+	TestProtocols(t, func(t *testing.T, protocol clickhouse.Protocol) {
+		conn, err := GetNativeConnection(t, protocol, nil, nil, nil)
+		require.NoError(t, err)
+		ctx := clickhouse.Context(context.Background(), clickhouse.WithSettings(clickhouse.Settings{
+			"max_block_size": 1000,
+		}))
+
+		result, err := conn.Query(ctx, "SELECT toInt64(number), toString(number) FROM system.numbers LIMIT 10000")
+		require.NoError(t, err)
+		rows, ok := result.(driver.BlockRows)
+		require.True(t, ok)
+
+		var (
+			total  int
+			sum    int64
+			blocks int
+		)
+		for rows.NextBlock() {
+			block := rows.Block()
+			ints, err := column.Values[int64](block.Columns[0])
+			require.NoError(t, err)
+			strs, err := column.Values[string](block.Columns[1])
+			require.NoError(t, err)
+			require.Len(t, strs, len(ints))
+			for _, v := range ints {
+				sum += v
+			}
+			total += len(ints)
+			blocks++
+		}
+		require.NoError(t, rows.Close())
+		require.NoError(t, rows.Err())
+		assert.Equal(t, 10000, total)
+		assert.Equal(t, int64(10000*9999/2), sum)
+		assert.Greater(t, blocks, 1)
+	})
+}
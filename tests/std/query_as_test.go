@@ -0,0 +1,54 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package std
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdQueryAs(t *testing.T) {
+	for name, protocol := range map[string]clickhouse.Protocol{"Native": clickhouse.Native, "Http": clickhouse.HTTP} {
+		t.Run(name, func(t *testing.T) {
+			conn, err := GetStdDSNConnection(protocol, false, nil)
+			require.NoError(t, err)
+			defer conn.Close()
+			ctx := context.Background()
+
+			type row struct {
+				Number uint64 `ch:"number"`
+				Str    string `ch:"str"`
+			}
+			rows, err := clickhouse.SelectAsDB[row](ctx, conn, "SELECT number, toString(number) AS str FROM system.numbers LIMIT 3")
+			require.NoError(t, err)
+			assert.Equal(t, []row{{0, "0"}, {1, "1"}, {2, "2"}}, rows)
+
+			var count int
+			for v, err := range clickhouse.QueryAsDB[string](ctx, conn, "SELECT toString(number) FROM system.numbers LIMIT 10") {
+				require.NoError(t, err)
+				assert.NotEmpty(t, v)
+				count++
+			}
+			assert.Equal(t, 10, count)
+		})
+	}
+}
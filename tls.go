@@ -0,0 +1,116 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsDSN is the TLS configuration of a DSN
+type tlsDSN struct {
+	caFile     string
+	certFile   string
+	keyFile    string
+	serverName string
+	minVersion uint16
+}
+
+func (t *tlsDSN) set() bool {
+	return *t != tlsDSN{}
+}
+
+// config returns the TLS configuration of the DSN. The client certificate is read again when its
+// files change, so that new connections use rotated certificates.
+func (t *tlsDSN) config(skipVerify bool) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         t.serverName,
+		MinVersion:         t.minVersion,
+		InsecureSkipVerify: skipVerify,
+	}
+	if t.caFile != "" {
+		data, err := os.ReadFile(t.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls_ca_file: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("tls_ca_file: no certificates in %s", t.caFile)
+		}
+	}
+	if t.certFile != "" || t.keyFile != "" {
+		if t.certFile == "" || t.keyFile == "" {
+			return nil, errors.New("tls_cert_file and tls_key_file must be set together")
+		}
+		cert := &reloadingCertificate{certFile: t.certFile, keyFile: t.keyFile}
+		if _, err := cert.get(nil); err != nil {
+			return nil, fmt.Errorf("tls_cert_file: %w", err)
+		}
+		config.GetClientCertificate = cert.get
+	}
+	return config, nil
+}
+
+// reloadingCertificate is a client certificate read again when its files are modified
+type reloadingCertificate struct {
+	certFile string
+	keyFile  string
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modified time.Time
+}
+
+func (c *reloadingCertificate) get(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var modified time.Time
+	for _, file := range []string{c.certFile, c.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+	}
+	if c.cert == nil || !modified.Equal(c.modified) {
+		cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+		switch {
+		case err != nil && c.cert != nil:
+			// the files may be halfway through a rotation, they are read again by the next handshake
+			return c.cert, nil
+		case err != nil:
+			return nil, err
+		}
+		c.cert, c.modified = &cert, modified
+	}
+	return c.cert, nil
+}
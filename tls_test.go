@@ -0,0 +1,153 @@
+// Licensed to ClickHouse, Inc. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. ClickHouse, Inc. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package clickhouse
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCertificate(t *testing.T, name string, parent *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCertificate{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (c *testCertificate) write(t *testing.T, certFile, keyFile string) {
+	require.NoError(t, os.WriteFile(certFile, c.pem, 0o600))
+	if keyFile != "" {
+		der, err := x509.MarshalECPrivateKey(c.key)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600))
+	}
+}
+
+// clientCertificate returns the certificate config presents to a server requiring a client certificate of ca
+func clientCertificate(t *testing.T, config *tls.Config, server, ca *testCertificate) *x509.Certificate {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+	peer := make(chan *x509.Certificate, 1)
+	go func() {
+		conn := tls.Server(serverConn, &tls.Config{
+			Certificates: []tls.Certificate{{Certificate: [][]byte{server.cert.Raw}, PrivateKey: server.key}},
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			ClientCAs:    roots,
+		})
+		if conn.Handshake() != nil {
+			peer <- nil
+			return
+		}
+		peer <- conn.ConnectionState().PeerCertificates[0]
+	}()
+	require.NoError(t, tls.Client(clientConn, config).Handshake())
+	return <-peer
+}
+
+func TestParseDSNTLSFiles(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		caFile   = filepath.Join(dir, "ca.pem")
+		certFile = filepath.Join(dir, "client.pem")
+		keyFile  = filepath.Join(dir, "client.key")
+		ca       = newTestCertificate(t, "ca", nil)
+		server   = newTestCertificate(t, "clickhouse.internal", ca)
+		client   = newTestCertificate(t, "client", ca)
+	)
+	ca.write(t, caFile, "")
+	client.write(t, certFile, keyFile)
+
+	opts, err := ParseDSN("clickhouse://127.0.0.1:9440/?" + url.Values{
+		"tls_ca_file":     {caFile},
+		"tls_cert_file":   {certFile},
+		"tls_key_file":    {keyFile},
+		"tls_server_name": {"clickhouse.internal"},
+		"tls_min_version": {"1.2"},
+	}.Encode())
+	require.NoError(t, err)
+	require.NotNil(t, opts.TLS)
+	assert.Equal(t, "clickhouse.internal", opts.TLS.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS12), opts.TLS.MinVersion)
+	assert.False(t, opts.TLS.InsecureSkipVerify)
+
+	handshake := func() *x509.Certificate {
+		return clientCertificate(t, opts.TLS, server, ca)
+	}
+	assert.Equal(t, "client", handshake().Subject.CommonName)
+
+	// a rotated certificate is used by the next handshakes
+	rotated := newTestCertificate(t, "rotated", ca)
+	rotated.write(t, certFile, keyFile)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+	assert.Equal(t, "rotated", handshake().Subject.CommonName)
+
+	for dsn, expectedErr := range map[string]string{
+		"clickhouse://127.0.0.1/?tls_min_version=1.4":                              `clickhouse [dsn parse]: tls_min_version: unknown version "1.4"`,
+		"clickhouse://127.0.0.1/?tls_cert_file=" + url.QueryEscape(certFile):       "clickhouse [dsn parse]: tls_cert_file and tls_key_file must be set together",
+		"clickhouse://127.0.0.1/?tls_ca_file=" + url.QueryEscape(keyFile):          "clickhouse [dsn parse]: tls_ca_file: no certificates in " + keyFile,
+		"http://127.0.0.1/?tls_server_name=clickhouse.internal":                    "clickhouse [dsn parse]: http with TLS specify",
+		"clickhouse://127.0.0.1/?secure=false&tls_server_name=clickhouse.internal": "clickhouse [dsn parse]: tls_* parameters require secure=true, got secure=false",
+	} {
+		_, err := ParseDSN(dsn)
+		assert.EqualError(t, err, expectedErr, dsn)
+	}
+}